@@ -29,6 +29,8 @@ import (
 	"github.com/gruntwork-io/terragrunt/cli/commands/catalog"
 	graphdependencies "github.com/gruntwork-io/terragrunt/cli/commands/graph-dependencies"
 	"github.com/gruntwork-io/terragrunt/cli/commands/hclfmt"
+	"github.com/gruntwork-io/terragrunt/cli/commands/lint"
+	"github.com/gruntwork-io/terragrunt/cli/commands/lsp"
 	outputmodulegroups "github.com/gruntwork-io/terragrunt/cli/commands/output-module-groups"
 	renderjson "github.com/gruntwork-io/terragrunt/cli/commands/render-json"
 	runall "github.com/gruntwork-io/terragrunt/cli/commands/run-all"
@@ -86,6 +88,8 @@ func terragruntCommands(opts *options.TerragruntOptions) cli.Commands {
 		telemetryCommand(opts, catalog.NewCommand(opts)),            // catalog
 		telemetryCommand(opts, scaffold.NewCommand(opts)),           // scaffold
 		telemetryCommand(opts, graph.NewCommand(opts)),              // graph
+		telemetryCommand(opts, lint.NewCommand(opts)),               // lint
+		telemetryCommand(opts, lsp.NewCommand(opts)),                // lsp
 	}
 
 	sort.Sort(cmds)
@@ -211,6 +215,13 @@ func initialSetup(opts *options.TerragruntOptions) func(ctx *cli.Context) error
 			return err
 		}
 
+		// --- Timeout
+		if opts.TimeoutStr != "" {
+			if opts.Timeout, err = config.ParseTimeout(opts.TimeoutStr); err != nil {
+				return err
+			}
+		}
+
 		// --- Terragrunt Version
 		terragruntVersion, err := hashicorpversion.NewVersion(ctx.App.Version)
 		if err != nil {
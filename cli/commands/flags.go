@@ -38,10 +38,30 @@ const (
 	FlagNameTerragruntModulesThatInclude             = "terragrunt-modules-that-include"
 	FlagNameTerragruntFetchDependencyOutputFromState = "terragrunt-fetch-dependency-output-from-state"
 	FlagNameTerragruntUsePartialParseConfigCache     = "terragrunt-use-partial-parse-config-cache"
+	FlagNameTerragruntPartialParseConfigCacheDir     = "terragrunt-partial-parse-config-cache-dir"
+	FlagNameTerragruntPartialParseConfigCacheRunCmd  = "terragrunt-partial-parse-config-cache-run-cmd"
 	FlagNameTerragruntIncludeModulePrefix            = "terragrunt-include-module-prefix"
 	FlagNameTerragruntFailOnStateBucketCreation      = "terragrunt-fail-on-state-bucket-creation"
 	FlagNameTerragruntDisableBucketUpdate            = "terragrunt-disable-bucket-update"
 	FlagNameTerragruntDisableCommandValidation       = "terragrunt-disable-command-validation"
+	FlagNameTerragruntResume                         = "terragrunt-resume"
+	FlagNameTerragruntReportFile                     = "terragrunt-report-file"
+	FlagNameTerragruntReportFormat                   = "terragrunt-report-format"
+	FlagNameTerragruntChangedSince                   = "terragrunt-changed-since"
+	FlagNameTerragruntFailFast                       = "terragrunt-fail-fast"
+	FlagNameTerragruntLabelParallelism               = "terragrunt-label-parallelism"
+	FlagNameTerragruntPlanSummary                    = "terragrunt-plan-summary"
+	FlagNameTerragruntPlanSummaryFile                = "terragrunt-plan-summary-file"
+	FlagNameTerragruntFilter                         = "terragrunt-filter"
+	FlagNameTerragruntDependencyOutputCacheDir       = "terragrunt-dependency-output-cache-dir"
+	FlagNameTerragruntDependencyOutputCacheTTL       = "terragrunt-dependency-output-cache-ttl"
+	FlagNameTerragruntInvalidateOutputCache          = "terragrunt-dependency-output-cache-invalidate"
+	FlagNameTerragruntRemoteConfigCacheDir           = "terragrunt-remote-config-cache-dir"
+	FlagNameTerragruntTimeout                        = "terragrunt-timeout"
+	FlagNameTerragruntGroupOutput                    = "terragrunt-group-output"
+	FlagNameTerragruntGroupOutputLiveStderr          = "terragrunt-group-output-live-stderr"
+	FlagNameTerragruntShard                          = "terragrunt-shard"
+	FlagNameTerragruntFeature                        = "terragrunt-feature"
 
 	FlagNameHelp    = "help"
 	FlagNameVersion = "version"
@@ -165,6 +185,60 @@ func NewGlobalFlags(opts *options.TerragruntOptions) cli.Flags {
 			EnvVar:      "TERRAGRUNT_PARALLELISM",
 			Usage:       "*-all commands parallelism set to at most N modules",
 		},
+		&cli.MapFlag[string, int]{
+			Name:        FlagNameTerragruntLabelParallelism,
+			Destination: &opts.LabelParallelism,
+			EnvVar:      "TERRAGRUNT_LABEL_PARALLELISM",
+			Usage:       "*-all commands run at most N modules at a time that share the same value of the given label, e.g. account=2.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntResume,
+			Destination: &opts.Resume,
+			EnvVar:      "TERRAGRUNT_RESUME",
+			Usage:       "*-all commands skip the modules that succeeded in the previous run and only run the failed or never started modules with their dependents.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntReportFile,
+			Destination: &opts.ReportFile,
+			EnvVar:      "TERRAGRUNT_REPORT_FILE",
+			Usage:       "*-all commands write a report with the result of every module to this file.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntReportFormat,
+			Destination: &opts.ReportFormat,
+			EnvVar:      "TERRAGRUNT_REPORT_FORMAT",
+			Usage:       "Format of the report written with --terragrunt-report-file: json or junit. Detected from the file extension by default.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntChangedSince,
+			Destination: &opts.ChangedSince,
+			EnvVar:      "TERRAGRUNT_CHANGED_SINCE",
+			Usage:       "*-all commands only run the modules affected by the changes since this git ref, and the modules that depend on them.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntFailFast,
+			Destination: &opts.FailFast,
+			EnvVar:      "TERRAGRUNT_FAIL_FAST",
+			Usage:       "*-all commands stop as soon as a module fails, cancelling the modules that have not started and interrupting the running ones.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntPlanSummary,
+			Destination: &opts.PlanSummary,
+			EnvVar:      "TERRAGRUNT_PLAN_SUMMARY",
+			Usage:       "run-all plan prints a summary of the resources to create, update, delete and replace in every module.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntPlanSummaryFile,
+			Destination: &opts.PlanSummaryFile,
+			EnvVar:      "TERRAGRUNT_PLAN_SUMMARY_FILE",
+			Usage:       "run-all plan writes the combined plan JSON of every module to this file. Implies --terragrunt-plan-summary.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntFilter,
+			Destination: &opts.Filter,
+			EnvVar:      "TERRAGRUNT_FILTER",
+			Usage:       "*-all commands only run the modules matching this expression of labels, path globs and dependents-of:/dependencies-of: selectors, e.g. 'env=prod && team!=data'.",
+		},
 		&cli.SliceFlag[string]{
 			Name:        FlagNameTerragruntExcludeDir,
 			Destination: &opts.ExcludeDirs,
@@ -212,12 +286,78 @@ func NewGlobalFlags(opts *options.TerragruntOptions) cli.Flags {
 			EnvVar:      "TERRAGRUNT_USE_PARTIAL_PARSE_CONFIG_CACHE",
 			Usage:       "Enables caching of includes during partial parsing operations. Will also be used for the --terragrunt-iam-role option if provided.",
 		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntPartialParseConfigCacheDir,
+			Destination: &opts.PartialParseConfigCacheDir,
+			EnvVar:      "TERRAGRUNT_PARTIAL_PARSE_CONFIG_CACHE_DIR",
+			Usage:       "Cache partially parsed configs in this directory, so that they are shared across terragrunt processes. Requires --terragrunt-use-partial-parse-config-cache.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntPartialParseConfigCacheRunCmd,
+			Destination: &opts.PartialParseConfigCacheRunCmd,
+			EnvVar:      "TERRAGRUNT_PARTIAL_PARSE_CONFIG_CACHE_RUN_CMD",
+			Usage:       "Also cache the configs calling run_cmd in --terragrunt-partial-parse-config-cache-dir, running the commands again to check their output is unchanged.",
+		},
 		&cli.BoolFlag{
 			Name:        FlagNameTerragruntFetchDependencyOutputFromState,
 			Destination: &opts.FetchDependencyOutputFromState,
 			EnvVar:      "TERRAGRUNT_FETCH_DEPENDENCY_OUTPUT_FROM_STATE",
 			Usage:       "The option fetchs dependency output directly from the state file instead of init dependencies and running terraform on them.",
 		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntDependencyOutputCacheDir,
+			Destination: &opts.DependencyOutputCacheDir,
+			EnvVar:      "TERRAGRUNT_DEPENDENCY_OUTPUT_CACHE_DIR",
+			Usage:       "Cache dependency outputs in this directory, so that they are shared across terragrunt processes.",
+		},
+		&cli.GenericFlag[int]{
+			Name:        FlagNameTerragruntDependencyOutputCacheTTL,
+			Destination: &opts.DependencyOutputCacheTTLSec,
+			EnvVar:      "TERRAGRUNT_DEPENDENCY_OUTPUT_CACHE_TTL",
+			Usage:       "The number of seconds dependency outputs cached with --terragrunt-dependency-output-cache-dir stay valid.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntInvalidateOutputCache,
+			Destination: &opts.InvalidateOutputCache,
+			EnvVar:      "TERRAGRUNT_DEPENDENCY_OUTPUT_CACHE_INVALIDATE",
+			Usage:       "Ignore the dependency outputs cached with --terragrunt-dependency-output-cache-dir and replace them with fresh ones.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntRemoteConfigCacheDir,
+			Destination: &opts.RemoteConfigCacheDir,
+			EnvVar:      "TERRAGRUNT_REMOTE_CONFIG_CACHE_DIR",
+			Usage:       "Cache the configs included or read from remote sources in this directory. Defaults to a directory in the user cache directory.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntTimeout,
+			Destination: &opts.TimeoutStr,
+			EnvVar:      "TERRAGRUNT_TIMEOUT",
+			Usage:       "Maximum duration of the run of each unit, across all of its commands, e.g. '45m'. The command running when it is exceeded is interrupted, then killed.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntGroupOutput,
+			Destination: &opts.GroupOutput,
+			EnvVar:      "TERRAGRUNT_GROUP_OUTPUT",
+			Usage:       "*-all commands write the output of each module as a single block once the module finishes, instead of interleaving the output of the modules.",
+		},
+		&cli.BoolFlag{
+			Name:        FlagNameTerragruntGroupOutputLiveStderr,
+			Destination: &opts.GroupOutputLiveStderr,
+			EnvVar:      "TERRAGRUNT_GROUP_OUTPUT_LIVE_STDERR",
+			Usage:       "With --terragrunt-group-output, write the stderr of the modules as it comes instead of buffering it.",
+		},
+		&cli.GenericFlag[string]{
+			Name:        FlagNameTerragruntShard,
+			Destination: &opts.Shard,
+			EnvVar:      "TERRAGRUNT_SHARD",
+			Usage:       "*-all commands only run the shard i/N of the stack, e.g. '2/4', so that the stack can be split across N CI jobs.",
+		},
+		&cli.MapFlag[string, string]{
+			Name:        FlagNameTerragruntFeature,
+			Destination: &opts.FeatureFlags,
+			EnvVar:      "TERRAGRUNT_FEATURE",
+			Usage:       "Set the value of a feature flag declared with a feature block, overriding its default, e.g. new_backend=true.",
+		},
 		&cli.BoolFlag{
 			Name:        FlagNameTerragruntIncludeModulePrefix,
 			Destination: &opts.IncludeModulePrefix,
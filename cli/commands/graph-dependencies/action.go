@@ -13,6 +13,5 @@ func Run(opts *options.TerragruntOptions) error {
 	}
 
 	// Exit early if the operation wanted is to get the graph
-	stack.Graph(opts)
-	return nil
+	return stack.Graph(opts)
 }
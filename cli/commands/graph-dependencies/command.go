@@ -7,12 +7,26 @@ import (
 
 const (
 	CommandName = "graph-dependencies"
+
+	FlagNameFormat = "format"
 )
 
+func NewFlags(opts *options.TerragruntOptions) cli.Flags {
+	return cli.Flags{
+		&cli.GenericFlag[string]{
+			Name:        FlagNameFormat,
+			Destination: &opts.GraphFormat,
+			EnvVar:      "TERRAGRUNT_GRAPH_FORMAT",
+			Usage:       "Format of the dependency graph: dot, mermaid or json.",
+		},
+	}
+}
+
 func NewCommand(opts *options.TerragruntOptions) *cli.Command {
 	return &cli.Command{
 		Name:   CommandName,
 		Usage:  "Prints the terragrunt dependency graph to stdout.",
+		Flags:  NewFlags(opts).Sort(),
 		Action: func(ctx *cli.Context) error { return Run(opts.OptionsFromContext(ctx)) },
 	}
 }
@@ -0,0 +1,126 @@
+// `lint` command statically checks the terragrunt configs of the units found in the working directory, along with the
+// configs they include, and prints the issues found, e.g. the locals that are never referenced or the references to
+// dependencies that are not defined. It exits with an error if any of the issues is an error.
+
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// The formats the issues can be printed in with lint --format.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+var Formats = []string{FormatText, FormatJSON, FormatSARIF}
+
+func Run(opts *options.TerragruntOptions) error {
+	var write func(io.Writer, *options.TerragruntOptions, []config.LintDiagnostic) error
+	switch opts.LintFormat {
+	case "", FormatText:
+		write = writeText
+	case FormatJSON:
+		write = writeJSON
+	case FormatSARIF:
+		write = writeSARIF
+	default:
+		return errors.WithStackTrace(UnsupportedLintFormat(opts.LintFormat))
+	}
+
+	configPaths, err := config.FindConfigFilesInPath(opts.WorkingDir, opts)
+	if err != nil {
+		return err
+	}
+
+	diagnostics := config.LintConfigs(config.NewParsingContext(context.Background(), opts), configPaths)
+	if err := write(opts.Writer, opts, diagnostics); err != nil {
+		return err
+	}
+
+	errorsFound := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == config.LintSeverityError {
+			errorsFound++
+		}
+	}
+	if errorsFound > 0 {
+		return errors.WithStackTrace(LintErrorsFound(errorsFound))
+	}
+	return nil
+}
+
+// relativePath returns the path of the given config relative to the working directory, with forward slashes.
+func relativePath(opts *options.TerragruntOptions, path string) string {
+	relPath, err := filepath.Rel(opts.WorkingDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// writeText prints each issue on its own line, as `path:line:column: severity: message (rule)`.
+func writeText(w io.Writer, opts *options.TerragruntOptions, diagnostics []config.LintDiagnostic) error {
+	for _, diagnostic := range diagnostics {
+		start := diagnostic.Range.Start
+		_, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s (%s)\n", relativePath(opts, diagnostic.Range.Filename), start.Line, start.Column, diagnostic.Severity, diagnostic.Message, diagnostic.Rule)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	if len(diagnostics) == 0 {
+		opts.Logger.Infof("No issues found in the terragrunt configs.")
+	}
+	return nil
+}
+
+// JSONDiagnostic is the representation of an issue in the JSON output of lint.
+type JSONDiagnostic struct {
+	Rule     string  `json:"rule"`
+	Severity string  `json:"severity"`
+	Message  string  `json:"message"`
+	File     string  `json:"file"`
+	Start    JSONPos `json:"start"`
+	End      JSONPos `json:"end"`
+}
+
+// JSONPos is a position in a config, with the line and column starting from 1.
+type JSONPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// writeJSON prints the issues as a JSON object, with the list of issues under `diagnostics`.
+func writeJSON(w io.Writer, opts *options.TerragruntOptions, diagnostics []config.LintDiagnostic) error {
+	jsonDiagnostics := []JSONDiagnostic{}
+	for _, diagnostic := range diagnostics {
+		jsonDiagnostics = append(jsonDiagnostics, JSONDiagnostic{
+			Rule:     diagnostic.Rule,
+			Severity: string(diagnostic.Severity),
+			Message:  diagnostic.Message,
+			File:     relativePath(opts, diagnostic.Range.Filename),
+			Start:    JSONPos{Line: diagnostic.Range.Start.Line, Column: diagnostic.Range.Start.Column},
+			End:      JSONPos{Line: diagnostic.Range.End.Line, Column: diagnostic.Range.End.Column},
+		})
+	}
+
+	contents, err := json.MarshalIndent(map[string][]JSONDiagnostic{"diagnostics": jsonDiagnostics}, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if _, err := fmt.Fprintln(w, string(contents)); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
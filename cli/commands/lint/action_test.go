@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+const lintTestConfig = `
+locals {
+  name = "app"
+}
+
+inputs = {
+  vpc_id = dependency.vpc.outputs.vpc_id
+}
+`
+
+// lintTestOptions writes a unit with an unused local and a reference to an undefined dependency in a new working
+// directory, and returns the options to lint it in the given format, with the output written to the given buffer.
+func lintTestOptions(t *testing.T, format string, output *bytes.Buffer) *options.TerragruntOptions {
+	workingDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	unitDir := filepath.Join(workingDir, "app")
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, config.DefaultTerragruntConfigPath), []byte(lintTestConfig), 0644))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(workingDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.WorkingDir = workingDir
+	opts.LintFormat = format
+	opts.Writer = output
+	return opts
+}
+
+func TestLintText(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	err := Run(lintTestOptions(t, FormatText, output))
+
+	require.Error(t, err)
+	assert.Equal(t, LintErrorsFound(1), errors.Unwrap(err))
+	assert.Equal(t, `app/terragrunt.hcl:3:3: warning: Local "name" is never referenced. (unused-local)
+app/terragrunt.hcl:7:12: error: Dependency "vpc" is not defined in terragrunt.hcl or the configs it includes. (undefined-reference)
+`, output.String())
+}
+
+func TestLintJSON(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	require.Error(t, Run(lintTestOptions(t, FormatJSON, output)))
+
+	result := map[string][]JSONDiagnostic{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &result))
+	require.Len(t, result["diagnostics"], 2)
+	assert.Equal(t, JSONDiagnostic{
+		Rule:     config.LintRuleUndefinedReference,
+		Severity: "error",
+		Message:  `Dependency "vpc" is not defined in terragrunt.hcl or the configs it includes.`,
+		File:     "app/terragrunt.hcl",
+		Start:    JSONPos{Line: 7, Column: 12},
+		End:      JSONPos{Line: 7, Column: 41},
+	}, result["diagnostics"][1])
+}
+
+func TestLintSARIF(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	require.Error(t, Run(lintTestOptions(t, FormatSARIF, output)))
+
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &log))
+	assert.Equal(t, sarifVersion, log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, len(config.LintRules))
+
+	require.Len(t, run.Results, 2)
+	result := run.Results[0]
+	assert.Equal(t, config.LintRuleUnusedLocal, result.RuleID)
+	assert.Equal(t, config.LintRuleUnusedLocal, run.Tool.Driver.Rules[result.RuleIndex].ID)
+	assert.Equal(t, "warning", result.Level)
+	assert.Equal(t, "app/terragrunt.hcl", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, sarifRegion{StartLine: 3, StartColumn: 3, EndLine: 3, EndColumn: 7}, result.Locations[0].PhysicalLocation.Region)
+}
+
+func TestLintUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Run(lintTestOptions(t, "xml", &bytes.Buffer{}))
+	require.Error(t, err)
+	assert.Equal(t, UnsupportedLintFormat("xml"), errors.Unwrap(err))
+}
@@ -0,0 +1,32 @@
+package lint
+
+import (
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/pkg/cli"
+)
+
+const (
+	CommandName = "lint"
+
+	FlagNameFormat = "format"
+)
+
+func NewFlags(opts *options.TerragruntOptions) cli.Flags {
+	return cli.Flags{
+		&cli.GenericFlag[string]{
+			Name:        FlagNameFormat,
+			Destination: &opts.LintFormat,
+			EnvVar:      "TERRAGRUNT_LINT_FORMAT",
+			Usage:       "Format of the issues found: text, json or sarif.",
+		},
+	}
+}
+
+func NewCommand(opts *options.TerragruntOptions) *cli.Command {
+	return &cli.Command{
+		Name:   CommandName,
+		Usage:  "Checks the terragrunt configs for issues, without running terraform.",
+		Flags:  NewFlags(opts).Sort(),
+		Action: func(ctx *cli.Context) error { return Run(opts.OptionsFromContext(ctx)) },
+	}
+}
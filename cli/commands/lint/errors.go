@@ -0,0 +1,18 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+type UnsupportedLintFormat string
+
+func (err UnsupportedLintFormat) Error() string {
+	return fmt.Sprintf("Unsupported lint format '%s'. Supported formats are: %s.", string(err), strings.Join(Formats, ", "))
+}
+
+type LintErrorsFound int
+
+func (err LintErrorsFound) Error() string {
+	return fmt.Sprintf("Found %d error(s) in the terragrunt configs.", int(err))
+}
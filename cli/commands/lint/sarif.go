@@ -0,0 +1,146 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// The SARIF format is the format of the static analysis results that code hosting platforms annotate the code with,
+// e.g. with GitHub code scanning. Only the properties needed for the issues found by lint are defined here, see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html for the full format.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifToolName           = "terragrunt"
+	sarifToolInformationURI = "https://terragrunt.gruntwork.io/docs/reference/cli-options/#lint"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// writeSARIF prints the issues as a SARIF log with a single run, whose rules are the rules of the linter. The paths of
+// the configs are relative to the working directory, which is expected to be the root of the repository.
+func writeSARIF(w io.Writer, opts *options.TerragruntOptions, diagnostics []config.LintDiagnostic) error {
+	driver := sarifDriver{
+		Name:           sarifToolName,
+		InformationURI: sarifToolInformationURI,
+		Rules:          []sarifRule{},
+	}
+	if opts.TerragruntVersion != nil {
+		driver.Version = opts.TerragruntVersion.String()
+	}
+
+	ruleIndexes := map[string]int{}
+	for i, rule := range config.LintRules {
+		ruleIndexes[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: string(rule.Severity)},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, diagnostic := range diagnostics {
+		results = append(results, sarifResult{
+			RuleID:    diagnostic.Rule,
+			RuleIndex: ruleIndexes[diagnostic.Rule],
+			// The severities of the linter are SARIF levels as well.
+			Level:   string(diagnostic.Severity),
+			Message: sarifMessage{Text: diagnostic.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: relativePath(opts, diagnostic.Range.Filename)},
+					Region: sarifRegion{
+						StartLine:   diagnostic.Range.Start.Line,
+						StartColumn: diagnostic.Range.Start.Column,
+						EndLine:     diagnostic.Range.End.Line,
+						EndColumn:   diagnostic.Range.End.Column,
+					},
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	contents, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if _, err := fmt.Fprintln(w, string(contents)); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
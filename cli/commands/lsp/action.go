@@ -0,0 +1,42 @@
+// `lsp` command starts a language server for the terragrunt configs, which editors run to get the completion of the
+// functions, blocks and attributes of the configs, the navigation to the definitions of the includes, dependencies
+// and locals, the values of the locals on hover, and the parsing errors as diagnostics. The server speaks the language
+// server protocol over stdin and stdout, and runs until the editor disconnects.
+
+package lsp
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+func Run(opts *options.TerragruntOptions) error {
+	return Serve(context.Background(), opts, stdio{Reader: os.Stdin, Writer: opts.Writer})
+}
+
+// Serve runs the language server over the given stream until it is closed, by the client or on exit.
+func Serve(ctx context.Context, opts *options.TerragruntOptions, stream io.ReadWriteCloser) error {
+	opts.Logger.Debugf("Starting the language server")
+
+	server := newServer(opts)
+	conn := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(stream, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(server.handle))
+	<-conn.DisconnectNotify()
+
+	opts.Logger.Debugf("The language server was disconnected")
+	return nil
+}
+
+// stdio is the stream of the language server over stdin and stdout, which are left open when the server stops.
+type stdio struct {
+	io.Reader
+	io.Writer
+}
+
+func (stdio) Close() error {
+	return nil
+}
@@ -0,0 +1,299 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+const lspTestConfig = `include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+locals {
+  env  = "prod"
+  name = "app-${local.env}"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+terraform {
+
+}
+
+inputs = {
+  name   = local.name
+  vpc_id = dependency.vpc.outputs.vpc_id
+  subnet = dependency.network.outputs.subnet_id
+}
+`
+
+const lspTestRootConfig = `dependency "network" {
+  config_path = "../network"
+}
+`
+
+// lspTestClient is a client of a language server served over an in-memory connection.
+type lspTestClient struct {
+	t           *testing.T
+	conn        *jsonrpc2.Conn
+	diagnostics chan lsp.PublishDiagnosticsParams
+}
+
+// newLSPTestClient writes a unit including a root config, along with the units it depends on, in a new working
+// directory, and returns a client of a language server started for it, along with the path of the config of the unit.
+func newLSPTestClient(t *testing.T) (*lspTestClient, string) {
+	return newLSPTestClientWithOptions(t, func(*options.TerragruntOptions) {})
+}
+
+// newLSPTestClientWithOptions is newLSPTestClient with the options of the server updated by the given function.
+func newLSPTestClientWithOptions(t *testing.T, configure func(*options.TerragruntOptions)) (*lspTestClient, string) {
+	workingDir := helpers.WriteTempFiles(t, map[string]string{
+		"root.hcl": lspTestRootConfig,
+		"app/" + config.DefaultTerragruntConfigPath:     lspTestConfig,
+		"vpc/" + config.DefaultTerragruntConfigPath:     "",
+		"network/" + config.DefaultTerragruntConfigPath: "",
+	})
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(workingDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	configure(opts)
+
+	ctx := context.Background()
+	serverStream, clientStream := net.Pipe()
+	go func() {
+		assert.NoError(t, Serve(ctx, opts, serverStream))
+	}()
+
+	client := &lspTestClient{t: t, diagnostics: make(chan lsp.PublishDiagnosticsParams, 10)}
+	client.conn = jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(clientStream, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(
+		func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			if req.Method == "textDocument/publishDiagnostics" {
+				params := lsp.PublishDiagnosticsParams{}
+				if err := json.Unmarshal(*req.Params, &params); err != nil {
+					return nil, err
+				}
+				client.diagnostics <- params
+			}
+			return nil, nil
+		}))
+	t.Cleanup(func() {
+		_ = client.conn.Notify(ctx, "exit", nil)
+		_ = client.conn.Close()
+	})
+
+	result := lsp.InitializeResult{}
+	client.call("initialize", lsp.InitializeParams{}, &result)
+	require.True(t, result.Capabilities.DefinitionProvider)
+
+	return client, filepath.Join(workingDir, "app", config.DefaultTerragruntConfigPath)
+}
+
+func (client *lspTestClient) call(method string, params, result interface{}) {
+	require.NoError(client.t, client.conn.Call(context.Background(), method, params, result))
+}
+
+// open opens the given text as the document of the given config, and returns the diagnostics published for it.
+func (client *lspTestClient) open(path, text string) []lsp.Diagnostic {
+	params := lsp.DidOpenTextDocumentParams{TextDocument: lsp.TextDocumentItem{URI: pathToURI(path), LanguageID: "hcl", Version: 1, Text: text}}
+	require.NoError(client.t, client.conn.Notify(context.Background(), "textDocument/didOpen", params))
+	return client.receiveDiagnostics()
+}
+
+func (client *lspTestClient) receiveDiagnostics() []lsp.Diagnostic {
+	select {
+	case params := <-client.diagnostics:
+		return params.Diagnostics
+	case <-time.After(10 * time.Second):
+		require.FailNow(client.t, "no diagnostics were published")
+		return nil
+	}
+}
+
+// positionParams returns the params of a request at the given offset after the first occurrence of the given text in
+// the config.
+func positionParams(path, text string, delta int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: pathToURI(path)},
+		Position:     position(lspTestConfig, strings.Index(lspTestConfig, text)+delta),
+	}
+}
+
+func completionLabels(items []lsp.CompletionItem) []string {
+	labels := []string{}
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestServeDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	client, path := newLSPTestClient(t)
+
+	diagnostics := client.open(path, "locals {\n  name = \n}\n")
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, lsp.Error, diagnostics[0].Severity)
+	assert.Equal(t, diagnosticSource, diagnostics[0].Source)
+	assert.Equal(t, 1, diagnostics[0].Range.Start.Line)
+	assert.Contains(t, diagnostics[0].Message, "Invalid expression")
+
+	params := lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: pathToURI(path)}, Version: 2},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: lspTestConfig}},
+	}
+	require.NoError(t, client.conn.Notify(context.Background(), "textDocument/didChange", params))
+	assert.Empty(t, client.receiveDiagnostics())
+}
+
+func TestServeCompletion(t *testing.T) {
+	t.Parallel()
+
+	client, path := newLSPTestClient(t)
+	require.Empty(t, client.open(path, lspTestConfig))
+
+	testCases := []struct {
+		name     string
+		text     string
+		delta    int
+		expected []string
+		excluded []string
+	}{
+		{"locals", "local.env}", len("local."), []string{"env", "name"}, nil},
+		{"dependencies", "dependency.vpc.outputs", len("dependency."), []string{"vpc"}, nil},
+		{"functions", "find_in_parent_folders", 0, []string{"find_in_parent_folders", "get_terragrunt_dir", "jsonencode", "local", "dependency"}, []string{"source"}},
+		{"top level", "inputs", 0, []string{"dependency", "inputs", "locals", "remote_state", "terraform"}, []string{"source", "jsonencode"}},
+		{"block", "terraform {\n", len("terraform {\n"), []string{"after_hook", "extra_arguments", "source"}, []string{"inputs"}},
+	}
+	for _, testCase := range testCases {
+		params := lsp.CompletionParams{TextDocumentPositionParams: positionParams(path, testCase.text, testCase.delta)}
+		result := lsp.CompletionList{}
+		client.call("textDocument/completion", params, &result)
+
+		labels := completionLabels(result.Items)
+		for _, label := range testCase.expected {
+			assert.Contains(t, labels, label, testCase.name)
+		}
+		for _, label := range testCase.excluded {
+			assert.NotContains(t, labels, label, testCase.name)
+		}
+	}
+}
+
+func TestServeDefinition(t *testing.T) {
+	t.Parallel()
+
+	client, path := newLSPTestClient(t)
+	require.Empty(t, client.open(path, lspTestConfig))
+	workingDir := filepath.Dir(filepath.Dir(path))
+
+	testCases := []struct {
+		name         string
+		text         string
+		delta        int
+		expectedPath string
+		expectedLine int
+	}{
+		{"local", "local.name", len("local.n"), path, 6},
+		{"dependency", "dependency.vpc.outputs", len("dependency.v"), path, 9},
+		{"included dependency", "dependency.network", len("dependency.n"), filepath.Join(workingDir, "root.hcl"), 0},
+		{"include path", "find_in_parent_folders", 1, filepath.Join(workingDir, "root.hcl"), 0},
+		{"dependency config path", `"../vpc"`, 1, filepath.Join(workingDir, "vpc", config.DefaultTerragruntConfigPath), 0},
+	}
+	for _, testCase := range testCases {
+		locations := []lsp.Location{}
+		client.call("textDocument/definition", positionParams(path, testCase.text, testCase.delta), &locations)
+
+		require.Len(t, locations, 1, testCase.name)
+		assert.Equal(t, pathToURI(testCase.expectedPath), locations[0].URI, testCase.name)
+		assert.Equal(t, testCase.expectedLine, locations[0].Range.Start.Line, testCase.name)
+	}
+
+	locations := []lsp.Location{}
+	client.call("textDocument/definition", positionParams(path, "inputs", 0), &locations)
+	assert.Empty(t, locations)
+}
+
+func TestServeHover(t *testing.T) {
+	t.Parallel()
+
+	client, path := newLSPTestClient(t)
+	require.Empty(t, client.open(path, lspTestConfig))
+
+	hover := lsp.Hover{}
+	client.call("textDocument/hover", positionParams(path, "local.name", len("local.n")), &hover)
+	require.Len(t, hover.Contents, 1)
+	assert.Equal(t, "hcl", hover.Contents[0].Language)
+	assert.Equal(t, `local.name = "app-prod"`, hover.Contents[0].Value)
+
+	hover = lsp.Hover{}
+	client.call("textDocument/hover", positionParams(path, "env  =", 0), &hover)
+	require.Len(t, hover.Contents, 1)
+	assert.Equal(t, `local.env = "prod"`, hover.Contents[0].Value)
+}
+
+func TestServeHoverFunctionsWithSideEffects(t *testing.T) {
+	t.Parallel()
+
+	text := `locals {
+  user = run_cmd("--terragrunt-quiet", "echo", "admin")
+}
+`
+	testCases := []struct {
+		name              string
+		evaluateFunctions bool
+		expected          string
+	}{
+		{"analyzed", false, `local.user = (known after apply)`},
+		{"evaluated", true, `local.user = "admin"`},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, path := newLSPTestClientWithOptions(t, func(opts *options.TerragruntOptions) {
+				opts.LSPEvaluateFunctions = testCase.evaluateFunctions
+			})
+			require.Empty(t, client.open(path, text))
+
+			hover := lsp.Hover{}
+			params := lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: pathToURI(path)},
+				Position:     position(text, strings.Index(text, "user")),
+			}
+			client.call("textDocument/hover", params, &hover)
+			require.Len(t, hover.Contents, 1)
+			assert.Equal(t, testCase.expected, hover.Contents[0].Value)
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	t.Parallel()
+
+	// The characters of the positions are counted in UTF-16 code units, and the emoji takes two of them.
+	text := "a = \"é\"\nb = \"😀x\"\n"
+	xOffset := strings.Index(text, "x")
+
+	assert.Equal(t, lsp.Position{Line: 1, Character: 7}, position(text, xOffset))
+	assert.Equal(t, xOffset, offset(text, lsp.Position{Line: 1, Character: 7}))
+	assert.Equal(t, lsp.Position{Line: 0, Character: 6}, position(text, strings.Index(text, "\"\n")))
+	assert.Equal(t, len(text), offset(text, lsp.Position{Line: 5}))
+}
@@ -0,0 +1,32 @@
+package lsp
+
+import (
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/pkg/cli"
+)
+
+const (
+	CommandName = "lsp"
+
+	FlagNameEvaluateFunctions = "evaluate-functions"
+)
+
+func NewFlags(opts *options.TerragruntOptions) cli.Flags {
+	return cli.Flags{
+		&cli.BoolFlag{
+			Name:        FlagNameEvaluateFunctions,
+			Destination: &opts.LSPEvaluateFunctions,
+			EnvVar:      "TERRAGRUNT_LSP_EVALUATE_FUNCTIONS",
+			Usage:       "Call the functions with side effects, e.g. run_cmd, to show the value of the locals on hover.",
+		},
+	}
+}
+
+func NewCommand(opts *options.TerragruntOptions) *cli.Command {
+	return &cli.Command{
+		Name:   CommandName,
+		Usage:  "Starts a language server for the terragrunt configs, speaking the language server protocol over stdin and stdout.",
+		Flags:  NewFlags(opts).Sort(),
+		Action: func(ctx *cli.Context) error { return Run(opts.OptionsFromContext(ctx)) },
+	}
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/sourcegraph/go-lsp"
+
+	"github.com/gruntwork-io/terragrunt/config"
+)
+
+// variables are the variables that can be referenced in the expressions of the configs.
+var variables = []string{config.MetadataLocal, config.MetadataDependency, config.MetadataInclude, config.MetadataFeatureFlag}
+
+// variableAttributePrefixRegexp matches the access to an attribute of a variable being typed, e.g. `local.na`, at the
+// end of the text before the cursor.
+var variableAttributePrefixRegexp = regexp.MustCompile(`(?:^|[^\w.-])(` + strings.Join(variables, "|") + `)\.[\w-]*$`)
+
+// completion returns the completion items at the given position of the document:
+//   - after a variable and a dot, e.g. `local.`, the attributes of the variable defined in the document;
+//   - in the value of an attribute, the variables and the functions;
+//   - elsewhere, the attributes and the blocks of the enclosing block.
+func (s *server) completion(doc *document, pos lsp.Position) []lsp.CompletionItem {
+	offset := offset(doc.text, pos)
+	lineStart := strings.LastIndexByte(doc.text[:offset], '\n') + 1
+	linePrefix := doc.text[lineStart:offset]
+
+	if match := variableAttributePrefixRegexp.FindStringSubmatch(linePrefix); match != nil {
+		return variableAttributeCompletion(doc, match[1])
+	}
+
+	// The value of an attribute may not be parsed yet while it is being typed, so the text after the equal sign on the
+	// line is considered as a value as well.
+	if strings.Contains(linePrefix, "=") || doc.inAttributeValue(offset) {
+		return s.expressionCompletion(doc)
+	}
+
+	return schemaCompletion(doc, offset)
+}
+
+// variableAttributeCompletion returns the attributes of the given variable: the locals of the document, or the labels
+// of its dependency, include or feature blocks.
+func variableAttributeCompletion(doc *document, variable string) []lsp.CompletionItem {
+	items := []lsp.CompletionItem{}
+
+	if variable == config.MetadataLocal {
+		for name := range doc.locals() {
+			items = append(items, lsp.CompletionItem{Label: name, Kind: lsp.CIKVariable, Detail: "local"})
+		}
+		return sortCompletionItems(items)
+	}
+
+	for _, block := range doc.blocks(variable) {
+		if label := blockLabel(block); label != "" {
+			items = append(items, lsp.CompletionItem{Label: label, Kind: lsp.CIKModule, Detail: variable})
+		}
+	}
+	return sortCompletionItems(items)
+}
+
+// expressionCompletion returns the variables and the functions that can be used in the expressions of the document,
+// including the functions of its function blocks.
+func (s *server) expressionCompletion(doc *document) []lsp.CompletionItem {
+	items := []lsp.CompletionItem{}
+	for _, variable := range variables {
+		items = append(items, lsp.CompletionItem{Label: variable, Kind: lsp.CIKVariable, Detail: "variable"})
+	}
+
+	s.analyze(doc)
+
+	var functionNames []string
+	if doc.evalCtx != nil {
+		for name := range doc.evalCtx.Functions {
+			functionNames = append(functionNames, name)
+		}
+	} else {
+		names, err := config.FunctionNames(s.parsingContext(doc), doc.path)
+		if err != nil {
+			s.opts.Logger.Debugf("Could not list the functions of %s: %v", doc.path, err)
+		}
+		functionNames = names
+	}
+
+	for _, name := range functionNames {
+		items = append(items, lsp.CompletionItem{Label: name, Kind: lsp.CIKFunction, Detail: "function"})
+	}
+	return sortCompletionItems(items)
+}
+
+// schemaCompletion returns the attributes and the blocks of the block enclosing the given offset of the document, or
+// of the config itself at the top level.
+func schemaCompletion(doc *document, offset int) []lsp.CompletionItem {
+	schema := config.TerragruntConfigSchema()
+	for _, blockType := range enclosingBlockTypes(doc.body(), offset) {
+		schema = schema.Blocks[blockType]
+		if schema == nil {
+			// Unknown blocks have no schema to complete from.
+			return []lsp.CompletionItem{}
+		}
+	}
+
+	items := []lsp.CompletionItem{}
+	for _, name := range schema.Attributes {
+		items = append(items, lsp.CompletionItem{Label: name, Kind: lsp.CIKProperty, Detail: "attribute"})
+	}
+	for name := range schema.Blocks {
+		items = append(items, lsp.CompletionItem{Label: name, Kind: lsp.CIKStruct, Detail: "block"})
+	}
+	return sortCompletionItems(items)
+}
+
+// enclosingBlockTypes returns the types of the blocks that enclose the given offset, from the top level.
+func enclosingBlockTypes(body *hclsyntax.Body, offset int) []string {
+	if body == nil {
+		return nil
+	}
+	for _, block := range body.Blocks {
+		if block.OpenBraceRange.End.Byte <= offset && offset <= block.CloseBraceRange.Start.Byte {
+			return append([]string{block.Type}, enclosingBlockTypes(block.Body, offset)...)
+		}
+	}
+	return nil
+}
+
+func sortCompletionItems(items []lsp.CompletionItem) []lsp.CompletionItem {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
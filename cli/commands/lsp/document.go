@@ -0,0 +1,326 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// document is a config opened in the editor, whose text is the one being edited rather than the one on disk.
+type document struct {
+	uri  lsp.DocumentURI
+	path string
+	text string
+
+	// file is the syntax of the config, which is partial if the text has syntax errors, or nil if nothing could be
+	// parsed. diags are the diagnostics of the parsing.
+	file  *hcl.File
+	diags hcl.Diagnostics
+
+	// The analysis of the base blocks of the config, done on the first request that needs it, see server.analyze.
+	analyzed    bool
+	baseBlocks  *config.DecodedBaseBlocks
+	evalCtx     *hcl.EvalContext
+	analysisErr error
+}
+
+func parseDocument(opts *options.TerragruntOptions, uri lsp.DocumentURI, text string) (*document, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{uri: uri, path: path, text: text}
+	if file, diags := parseConfig(opts, path, text); file != nil {
+		doc.file, doc.diags = file.File, diags
+	} else {
+		doc.diags = diags
+	}
+	return doc, nil
+}
+
+// parseConfig parses the given text of a config. Unlike the parsing methods of hclparse, which return no file on
+// syntax errors, the file is returned along with the diagnostics, and holds all the blocks and the attributes that
+// could be parsed.
+func parseConfig(opts *options.TerragruntOptions, path, text string) (*hclparse.File, hcl.Diagnostics) {
+	parser := hclparse.NewParser().WithOptions(config.DefaultParserOptions(opts)...)
+
+	var (
+		file  *hcl.File
+		diags hcl.Diagnostics
+	)
+	switch filepath.Ext(path) {
+	case ".json":
+		file, diags = parser.ParseJSON([]byte(text), path)
+	default:
+		file, diags = parser.ParseHCL([]byte(text), path)
+	}
+
+	if file == nil || file.Body == nil {
+		return nil, diags
+	}
+	return &hclparse.File{Parser: parser, File: file, ConfigPath: path}, diags
+}
+
+// diagnostics returns the diagnostics of the parsing of the document, in the format of the protocol.
+func (doc *document) diagnostics() []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	for _, diag := range doc.diags {
+		severity := lsp.Error
+		if diag.Severity == hcl.DiagWarning {
+			severity = lsp.Warning
+		}
+
+		message := diag.Summary
+		if diag.Detail != "" {
+			message = fmt.Sprintf("%s: %s", diag.Summary, diag.Detail)
+		}
+
+		rng := lsp.Range{}
+		if diag.Subject != nil {
+			rng = lspRange(doc.text, *diag.Subject)
+		}
+
+		diagnostics = append(diagnostics, lsp.Diagnostic{
+			Range:    rng,
+			Severity: severity,
+			Source:   diagnosticSource,
+			Message:  message,
+		})
+	}
+	return diagnostics
+}
+
+// analyze decodes the base blocks of the document, for the completion of the functions and the evaluation of the
+// locals and the paths. The config is parsed again for the analysis, as the decoding may update the file, e.g. to
+// label a bare include block, which would shift the ranges of the syntax of the document.
+func (s *server) analyze(doc *document) {
+	if doc.analyzed {
+		return
+	}
+	doc.analyzed = true
+
+	file, _ := parseConfig(s.opts, doc.path, doc.text)
+	if file == nil {
+		return
+	}
+
+	doc.baseBlocks, doc.evalCtx, doc.analysisErr = config.AnalyzeBaseBlocks(s.parsingContext(doc), file, nil)
+	if doc.analysisErr != nil {
+		s.opts.Logger.Debugf("Could not evaluate the base blocks of %s: %v", doc.path, doc.analysisErr)
+	}
+}
+
+// parsingContext returns the context to evaluate the expressions of the document in. The configs are analyzed without
+// calling the functions with side effects, e.g. run_cmd, or the plugins, unless --evaluate-functions is set: the output
+// of the commands they run is then written to stderr, as stdout is the stream of the protocol.
+func (s *server) parsingContext(doc *document) *config.ParsingContext {
+	opts := s.opts.Clone(doc.path)
+	opts.Writer = opts.ErrWriter
+
+	ctx := config.NewParsingContext(context.Background(), opts)
+	if !opts.LSPEvaluateFunctions {
+		ctx = ctx.WithStaticAnalysis()
+	}
+	return ctx
+}
+
+// body returns the body of the document, or nil if it has none or is a JSON config, whose syntax isn't walked.
+func (doc *document) body() *hclsyntax.Body {
+	if doc.file == nil {
+		return nil
+	}
+	body, ok := doc.file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	return body
+}
+
+// blocks returns the top level blocks of the given type in the document.
+func (doc *document) blocks(blockType string) []*hclsyntax.Block {
+	return topLevelBlocks(doc.body(), blockType)
+}
+
+func topLevelBlocks(body *hclsyntax.Body, blockType string) []*hclsyntax.Block {
+	if body == nil {
+		return nil
+	}
+	blocks := []*hclsyntax.Block{}
+	for _, block := range body.Blocks {
+		if block.Type == blockType {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// findBlock returns the top level block of the given type and label in the given body.
+func findBlock(body *hclsyntax.Body, blockType, label string) *hclsyntax.Block {
+	for _, block := range topLevelBlocks(body, blockType) {
+		if blockLabel(block) == label {
+			return block
+		}
+	}
+	return nil
+}
+
+// blockLabel returns the first label of the block, which is empty for a bare include block, as it is labeled when
+// the config is decoded.
+func blockLabel(block *hclsyntax.Block) string {
+	if len(block.Labels) == 0 {
+		return ""
+	}
+	return block.Labels[0]
+}
+
+// locals returns the attributes of the locals blocks of the document, by name.
+func (doc *document) locals() map[string]*hclsyntax.Attribute {
+	locals := map[string]*hclsyntax.Attribute{}
+	for _, block := range doc.blocks(config.MetadataLocals) {
+		for name, attr := range block.Body.Attributes {
+			locals[name] = attr
+		}
+	}
+	return locals
+}
+
+// traversalAt returns the reference to a variable at the given offset of the document, e.g. `local.name`.
+func (doc *document) traversalAt(offset int) *hclsyntax.ScopeTraversalExpr {
+	body := doc.body()
+	if body == nil {
+		return nil
+	}
+
+	var found *hclsyntax.ScopeTraversalExpr
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if traversal, ok := node.(*hclsyntax.ScopeTraversalExpr); ok && containsOffset(traversal.SrcRange, offset) {
+			found = traversal
+		}
+		return nil
+	})
+	return found
+}
+
+// inAttributeValue returns true if the given offset of the document is in the value of an attribute, at any level.
+func (doc *document) inAttributeValue(offset int) bool {
+	body := doc.body()
+	if body == nil {
+		return false
+	}
+
+	found := false
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if attr, ok := node.(*hclsyntax.Attribute); ok && containsOffset(attr.Expr.Range(), offset) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// containsOffset returns true if the given offset is in the range, or right after it, where the cursor is when a name
+// is being typed.
+func containsOffset(rng hcl.Range, offset int) bool {
+	return rng.Start.Byte <= offset && offset <= rng.End.Byte
+}
+
+// traversalAttrName returns the name of the attribute accessed by the given step of the traversal, if any.
+func traversalAttrName(traversal hcl.Traversal, step int) string {
+	if len(traversal) <= step {
+		return ""
+	}
+	if attr, ok := traversal[step].(hcl.TraverseAttr); ok {
+		return attr.Name
+	}
+	return ""
+}
+
+// offset returns the byte offset in the text of the given position of the protocol, whose character is counted in
+// UTF-16 code units.
+func offset(text string, pos lsp.Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+
+	for units := 0; units < pos.Character && offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' {
+			break
+		}
+		units += utf16Len(r)
+		offset += size
+	}
+	return offset
+}
+
+// position returns the position of the protocol of the given byte offset in the text.
+func position(text string, offset int) lsp.Position {
+	offset = max(0, min(offset, len(text)))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+
+	pos := lsp.Position{Line: strings.Count(text[:lineStart], "\n")}
+	for _, r := range text[lineStart:offset] {
+		pos.Character += utf16Len(r)
+	}
+	return pos
+}
+
+// utf16Len returns the number of UTF-16 code units that encode the rune.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// lspRange returns the range of the protocol of the given range of a config whose text is given.
+func lspRange(text string, rng hcl.Range) lsp.Range {
+	return lsp.Range{Start: position(text, rng.Start.Byte), End: position(text, rng.End.Byte)}
+}
+
+// uriToPath returns the path of the file of the given URI, which must be a file URI.
+func uriToPath(uri lsp.DocumentURI) (string, error) {
+	parsed, err := url.Parse(string(uri))
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	if parsed.Scheme != "file" {
+		return "", &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("unsupported document URI: %s", uri)}
+	}
+
+	path := parsed.Path
+	if runtime.GOOS == "windows" {
+		// The path of the URI of a Windows file starts with a slash before the volume, e.g. `/C:/dir`.
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// pathToURI returns the file URI of the given absolute path.
+func pathToURI(path string) lsp.DocumentURI {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return lsp.DocumentURI((&url.URL{Scheme: "file", Path: path}).String())
+}
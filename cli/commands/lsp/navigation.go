@@ -0,0 +1,192 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sourcegraph/go-lsp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// definition returns the definition of what is at the given position of the document:
+//   - for a reference to a local, the local;
+//   - for a reference to a dependency, an include or a feature flag, its block, in the document or, for the
+//     dependencies and the feature flags, in the configs it includes;
+//   - for the path of an include or the config_path of a dependency, the config it points to.
+func (s *server) definition(doc *document, pos lsp.Position) []lsp.Location {
+	offset := offset(doc.text, pos)
+
+	if traversal := doc.traversalAt(offset); traversal != nil {
+		return s.variableDefinition(doc, traversal.Traversal)
+	}
+
+	if configPath := s.referencedConfigPath(doc, offset); configPath != "" {
+		return []lsp.Location{{URI: pathToURI(configPath)}}
+	}
+
+	return []lsp.Location{}
+}
+
+func (s *server) variableDefinition(doc *document, traversal hcl.Traversal) []lsp.Location {
+	name := traversalAttrName(traversal, 1)
+	if name == "" {
+		return []lsp.Location{}
+	}
+
+	switch variable := traversal.RootName(); variable {
+	case config.MetadataLocal:
+		if attr, ok := doc.locals()[name]; ok {
+			return []lsp.Location{{URI: doc.uri, Range: lspRange(doc.text, attr.NameRange)}}
+		}
+	case config.MetadataInclude:
+		if block := findBlock(doc.body(), variable, name); block != nil {
+			return []lsp.Location{{URI: doc.uri, Range: lspRange(doc.text, block.DefRange())}}
+		}
+	case config.MetadataDependency, config.MetadataFeatureFlag:
+		if block := findBlock(doc.body(), variable, name); block != nil {
+			return []lsp.Location{{URI: doc.uri, Range: lspRange(doc.text, block.DefRange())}}
+		}
+		// The dependencies and the feature flags of the included configs are merged into the document.
+		for _, included := range s.includedConfigs(doc) {
+			if block := findBlock(included.body, variable, name); block != nil {
+				return []lsp.Location{{URI: pathToURI(included.path), Range: lspRange(included.text, block.DefRange())}}
+			}
+		}
+	}
+	return []lsp.Location{}
+}
+
+// referencedConfigPath returns the path of the config pointed to by the path of the include or the config_path of the
+// dependency at the given offset of the document, if it exists.
+func (s *server) referencedConfigPath(doc *document, offset int) string {
+	for _, block := range doc.blocks(config.MetadataInclude) {
+		if attr, ok := block.Body.Attributes["path"]; ok && containsOffset(attr.Expr.Range(), offset) {
+			s.analyze(doc)
+			if doc.baseBlocks == nil {
+				return ""
+			}
+			// The path of a remote include is the path of the config fetched from the remote source.
+			include, ok := doc.baseBlocks.TrackInclude.CurrentMap[blockLabel(block)]
+			if !ok {
+				return ""
+			}
+			return existingConfigPath(doc.resolvePath(include.Path))
+		}
+	}
+
+	for _, block := range doc.blocks(config.MetadataDependency) {
+		if attr, ok := block.Body.Attributes["config_path"]; ok && containsOffset(attr.Expr.Range(), offset) {
+			s.analyze(doc)
+			if doc.evalCtx == nil {
+				return ""
+			}
+			value, diags := attr.Expr.Value(doc.evalCtx)
+			if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() || value.Type() != cty.String {
+				return ""
+			}
+			return existingConfigPath(config.GetDefaultConfigPath(doc.resolvePath(value.AsString())))
+		}
+	}
+
+	return ""
+}
+
+// resolvePath returns the given path, resolved against the directory of the document if it is relative.
+func (doc *document) resolvePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = util.JoinPath(filepath.Dir(doc.path), path)
+	}
+	return filepath.Clean(path)
+}
+
+func existingConfigPath(path string) string {
+	if !util.IsFile(path) {
+		return ""
+	}
+	return path
+}
+
+// includedConfig is the syntax of a config included by a document, read from the disk.
+type includedConfig struct {
+	path string
+	text string
+	body *hclsyntax.Body
+}
+
+// includedConfigs returns the configs the document includes directly. The configs that can't be read or parsed are
+// skipped.
+func (s *server) includedConfigs(doc *document) []includedConfig {
+	s.analyze(doc)
+	if doc.baseBlocks == nil {
+		return nil
+	}
+
+	includedConfigs := []includedConfig{}
+	for _, include := range doc.baseBlocks.TrackInclude.CurrentList {
+		path := doc.resolvePath(include.Path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		if body, ok := file.Body.(*hclsyntax.Body); ok {
+			includedConfigs = append(includedConfigs, includedConfig{path: path, text: string(content), body: body})
+		}
+	}
+	return includedConfigs
+}
+
+// hover returns the value of the local referenced or defined at the given position of the document, if any.
+func (s *server) hover(doc *document, pos lsp.Position) *lsp.Hover {
+	offset := offset(doc.text, pos)
+	locals := doc.locals()
+
+	var (
+		name string
+		rng  hcl.Range
+	)
+	if traversal := doc.traversalAt(offset); traversal != nil && traversal.Traversal.RootName() == config.MetadataLocal {
+		name, rng = traversalAttrName(traversal.Traversal, 1), traversal.SrcRange
+	} else {
+		for localName, attr := range locals {
+			if containsOffset(attr.NameRange, offset) {
+				name, rng = localName, attr.NameRange
+			}
+		}
+	}
+	if _, ok := locals[name]; !ok {
+		return nil
+	}
+
+	s.analyze(doc)
+
+	contents := lsp.RawMarkedString(fmt.Sprintf("Could not evaluate the locals: %v", doc.analysisErr))
+	if doc.baseBlocks != nil && doc.baseBlocks.Locals != nil {
+		localsValue := *doc.baseBlocks.Locals
+		if !localsValue.Type().IsObjectType() || !localsValue.Type().HasAttribute(name) {
+			return nil
+		}
+		contents = lsp.MarkedString{Language: "hcl", Value: fmt.Sprintf("local.%s = %s", name, formatValue(localsValue.GetAttr(name)))}
+	}
+
+	hoverRange := lspRange(doc.text, rng)
+	return &lsp.Hover{Contents: []lsp.MarkedString{contents}, Range: &hoverRange}
+}
+
+// formatValue returns the given value as an HCL expression.
+func formatValue(value cty.Value) string {
+	if !value.IsWhollyKnown() {
+		return "(known after apply)"
+	}
+	return string(hclwrite.Format(hclwrite.TokensForValue(value).Bytes()))
+}
@@ -0,0 +1,160 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// diagnosticSource is the source of the diagnostics published by the server, shown by the editors next to them.
+const diagnosticSource = "terragrunt"
+
+// server is the state of the language server, i.e. the documents opened in the editor. The requests are handled one at
+// a time, in the order they are received, so the state is not guarded.
+type server struct {
+	opts      *options.TerragruntOptions
+	documents map[lsp.DocumentURI]*document
+}
+
+func newServer(opts *options.TerragruntOptions) *server {
+	return &server{
+		opts:      opts,
+		documents: map[lsp.DocumentURI]*document{},
+	}
+}
+
+// handle handles the requests and the notifications of the client. The parsing and the evaluation of the configs may
+// panic on some errors, which are returned as the errors of the requests rather than stopping the server.
+func (s *server) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.WithStackTrace(fmt.Errorf("panic while handling %s: %v", req.Method, recovered))
+		}
+	}()
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "shutdown", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration", "workspace/didChangeWatchedFiles":
+		return nil, nil
+	case "exit":
+		return nil, conn.Close()
+
+	case "textDocument/didOpen":
+		params := lsp.DidOpenTextDocumentParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.openDocument(ctx, conn, params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		params := lsp.DidChangeTextDocumentParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		// The documents are synced in full, so the last change holds the whole text.
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.openDocument(ctx, conn, params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didSave":
+		return nil, nil
+	case "textDocument/didClose":
+		params := lsp.DidCloseTextDocumentParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, publishDiagnostics(ctx, conn, params.TextDocument.URI, nil)
+
+	case "textDocument/completion":
+		params := lsp.CompletionParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return lsp.CompletionList{Items: s.completion(doc, params.Position)}, nil
+	case "textDocument/definition":
+		params := lsp.TextDocumentPositionParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return s.definition(doc, params.Position), nil
+	case "textDocument/hover":
+		params := lsp.TextDocumentPositionParams{}
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return s.hover(doc, params.Position), nil
+	}
+
+	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+func (s *server) initialize() lsp.InitializeResult {
+	syncKind := lsp.TDSKFull
+	return lsp.InitializeResult{
+		Capabilities: lsp.ServerCapabilities{
+			TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
+				Options: &lsp.TextDocumentSyncOptions{OpenClose: true, Change: syncKind},
+			},
+			CompletionProvider: &lsp.CompletionOptions{TriggerCharacters: []string{"."}},
+			DefinitionProvider: true,
+			HoverProvider:      true,
+		},
+	}
+}
+
+// openDocument parses the given text of a document, either opened or changed in the editor, and publishes the
+// diagnostics of the parsing.
+func (s *server) openDocument(ctx context.Context, conn *jsonrpc2.Conn, uri lsp.DocumentURI, text string) error {
+	doc, err := parseDocument(s.opts, uri, text)
+	if err != nil {
+		return err
+	}
+	s.documents[uri] = doc
+
+	return publishDiagnostics(ctx, conn, uri, doc.diagnostics())
+}
+
+func (s *server) document(uri lsp.DocumentURI) (*document, error) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("document not opened: %s", uri)}
+	}
+	return doc, nil
+}
+
+func publishDiagnostics(ctx context.Context, conn *jsonrpc2.Conn, uri lsp.DocumentURI, diagnostics []lsp.Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []lsp.Diagnostic{}
+	}
+	params := lsp.PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics}
+	return errors.WithStackTrace(conn.Notify(ctx, "textDocument/publishDiagnostics", params))
+}
+
+func unmarshalParams(req *jsonrpc2.Request, params interface{}) error {
+	if req.Params == nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("missing params of %s", req.Method)}
+	}
+	if err := json.Unmarshal(*req.Params, params); err != nil {
+		return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf("invalid params of %s: %v", req.Method, err)}
+	}
+	return nil
+}
@@ -165,6 +165,25 @@ func runTerraform(terragruntOptions *options.TerragruntOptions, target *Target)
 		terragruntOptions.RetrySleepIntervalSec = time.Duration(*terragruntConfig.RetrySleepIntervalSec) * time.Second
 	}
 
+	// The retry rules of the errors block default to the policy of retry_max_attempts and retry_sleep_interval_sec.
+	terragruntOptions.RetryRules = terragruntConfig.Errors.GetRetryRules(terragruntOptions.RetryMaxAttempts, terragruntOptions.RetrySleepIntervalSec)
+	terragruntOptions.IgnoreRules = terragruntConfig.Errors.GetIgnoreRules()
+
+	if terragruntConfig.Timeout != nil {
+		timeout, err := config.ParseTimeout(*terragruntConfig.Timeout)
+		if err != nil {
+			return err
+		}
+		terragruntOptions.Timeout = timeout
+	}
+
+	// The timeout applies to the whole run of the unit, so a single deadline is shared by all of its commands.
+	if terragruntOptions.Timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), terragruntOptions.Timeout)
+		defer cancel()
+		terragruntOptions.TimeoutCtx = timeoutCtx
+	}
+
 	updatedTerragruntOptions := terragruntOptions
 	sourceUrl, err := config.GetTerraformSourceUrl(terragruntOptions, terragruntConfig)
 	if err != nil {
@@ -300,6 +319,14 @@ func runTerragruntWithConfig(originalTerragruntOptions *options.TerragruntOption
 	return runActionWithHooks("terraform", terragruntOptions, terragruntConfig, func() error {
 		runTerraformError := runTerraformWithRetry(terragruntOptions)
 
+		// The outputs of the module may have changed, even if the command failed part way through, so the modules that
+		// depend on it must not reuse the cached ones.
+		if util.ListContainsElement(config.TERRAFORM_COMMANDS_THAT_MODIFY_STATE, util.FirstArg(terragruntOptions.TerraformCliArgs)) {
+			if err := config.InvalidateOutputCache(terragruntOptions, terragruntOptions.TerragruntConfigPath); err != nil {
+				terragruntOptions.Logger.Warnf("Failed to invalidate the cached outputs of %s: %v", terragruntOptions.TerragruntConfigPath, err)
+			}
+		}
+
 		var lockFileError error
 		if shouldCopyLockFile(terragruntOptions.TerraformCliArgs) {
 			// Copy the lock file from the Terragrunt working dir (e.g., .terragrunt-cache/xxx/<some-module>) to the
@@ -410,22 +437,112 @@ func setTerragruntInputsAsEnvVars(terragruntOptions *options.TerragruntOptions,
 }
 
 func runTerraformWithRetry(terragruntOptions *options.TerragruntOptions) error {
-	// Retry the command configurable time with sleep in between
-	for i := 0; i < terragruntOptions.RetryMaxAttempts; i++ {
-		if out, tferr := shell.RunTerraformCommandWithOutput(terragruntOptions, terragruntOptions.TerraformCliArgs...); tferr != nil {
-			if out != nil && isRetryable(out.Stdout, out.Stderr, tferr, terragruntOptions) {
-				terragruntOptions.Logger.Infof("Encountered an error eligible for retrying. Sleeping %v before retrying.\n", terragruntOptions.RetrySleepIntervalSec)
-				time.Sleep(terragruntOptions.RetrySleepIntervalSec)
-			} else {
-				terragruntOptions.Logger.Errorf("%s invocation failed in %s", terragruntOptions.TerraformImplementation, terragruntOptions.WorkingDir)
-				return tferr
-			}
-		} else {
+	// Number of attempts per retry rule, the rule of retryable_errors having no name.
+	attempts := map[string]int{}
+
+	for {
+		out, tferr := shell.RunTerraformCommandWithOutput(terragruntOptions, terragruntOptions.TerraformCliArgs...)
+		if tferr == nil {
 			return nil
 		}
+
+		if exitCode, ok := nonFatalExitCode(tferr, terragruntOptions); ok {
+			terragruntOptions.Logger.Debugf("%s exited with code %d in %s, which is not a failure", terragruntOptions.TerraformImplementation, exitCode, terragruntOptions.WorkingDir)
+			return tferr
+		}
+
+		var stdout, stderr string
+		if out != nil {
+			stdout, stderr = out.Stdout, out.Stderr
+		}
+
+		if rule := getIgnoreRule(stdout, stderr, tferr, terragruntOptions); rule != nil {
+			terragruntOptions.Logger.Warnf("Ignoring the error of %s in %s, as it matches the ignore rule %s. %s", terragruntOptions.TerraformImplementation, terragruntOptions.WorkingDir, rule.Name, rule.Message)
+			notifyErrorRuleMatch(terragruntOptions, options.ErrorRuleMatch{Rule: rule.Name, Action: options.ErrorRuleActionIgnore, Message: rule.Message})
+			return nil
+		}
+
+		rule := getRetryRule(stdout, stderr, tferr, terragruntOptions)
+		if rule == nil {
+			terragruntOptions.Logger.Errorf("%s invocation failed in %s", terragruntOptions.TerraformImplementation, terragruntOptions.WorkingDir)
+			return tferr
+		}
+
+		attempts[rule.Name]++
+		if attempts[rule.Name] >= rule.MaxAttempts {
+			return errors.WithStackTrace(MaxRetriesExceeded{Opts: terragruntOptions, Rule: rule})
+		}
+
+		sleep := rule.SleepBeforeRetry(attempts[rule.Name])
+		if rule.Name == "" {
+			terragruntOptions.Logger.Infof("Encountered an error eligible for retrying. Sleeping %v before retrying.\n", sleep)
+		} else {
+			terragruntOptions.Logger.Infof("Encountered an error matching the retry rule %s. Sleeping %v before retrying (attempt %d of %d).", rule.Name, sleep, attempts[rule.Name]+1, rule.MaxAttempts)
+			notifyErrorRuleMatch(terragruntOptions, options.ErrorRuleMatch{Rule: rule.Name, Action: options.ErrorRuleActionRetry})
+		}
+		time.Sleep(sleep)
+	}
+}
+
+// nonFatalExitCode returns the exit code of the failed command, and true if it is one of the NonFatalExitCodes.
+func nonFatalExitCode(tferr error, terragruntOptions *options.TerragruntOptions) (int, bool) {
+	if len(terragruntOptions.NonFatalExitCodes) == 0 || shell.IsTimeoutError(tferr) {
+		return 0, false
+	}
+	exitCode, err := shell.GetExitCode(tferr)
+	if err != nil {
+		return 0, false
+	}
+	for _, nonFatal := range terragruntOptions.NonFatalExitCodes {
+		if exitCode == nonFatal {
+			return exitCode, true
+		}
+	}
+	return 0, false
+}
+
+// getIgnoreRule returns the first ignore rule of the errors block that matches the output of the failed command, or
+// nil if the error must not be ignored.
+func getIgnoreRule(stdout string, stderr string, tferr error, terragruntOptions *options.TerragruntOptions) *options.IgnoreRule {
+	// A command that exceeded its timeout was killed, which is never harmless.
+	if tferr == nil || shell.IsTimeoutError(tferr) {
+		return nil
+	}
+	for i, rule := range terragruntOptions.IgnoreRules {
+		if util.MatchesAny(rule.On, stderr) || util.MatchesAny(rule.On, stdout) {
+			return &terragruntOptions.IgnoreRules[i]
+		}
 	}
+	return nil
+}
 
-	return errors.WithStackTrace(MaxRetriesExceeded{terragruntOptions})
+// getRetryRule returns the first retry rule of the errors block that matches the output of the failed command, else a
+// rule with no name for retryable_errors, retry_max_attempts and retry_sleep_interval_sec if the output matches any of
+// the RetryableErrors, or nil if the error must not be retried.
+func getRetryRule(stdout string, stderr string, tferr error, terragruntOptions *options.TerragruntOptions) *options.RetryRule {
+	// The retry rules of the errors block are declared by the config, so --terragrunt-no-auto-retry only disables the
+	// retries of retryable_errors, see isRetryable.
+	if tferr == nil || shell.IsTimeoutError(tferr) {
+		return nil
+	}
+	for i, rule := range terragruntOptions.RetryRules {
+		if util.MatchesAny(rule.On, stderr) || util.MatchesAny(rule.On, stdout) {
+			return &terragruntOptions.RetryRules[i]
+		}
+	}
+	if isRetryable(stdout, stderr, tferr, terragruntOptions) {
+		return &options.RetryRule{
+			MaxAttempts:   terragruntOptions.RetryMaxAttempts,
+			SleepInterval: terragruntOptions.RetrySleepIntervalSec,
+		}
+	}
+	return nil
+}
+
+func notifyErrorRuleMatch(terragruntOptions *options.TerragruntOptions, match options.ErrorRuleMatch) {
+	if terragruntOptions.OnErrorRuleMatch != nil {
+		terragruntOptions.OnErrorRuleMatch(match)
+	}
 }
 
 // Prepare for running 'terraform init' by initializing remote state storage and adding backend configuration arguments
@@ -653,7 +770,8 @@ func checkProtectedModule(terragruntOptions *options.TerragruntOptions, terragru
 
 // isRetryable checks whether there was an error and if the output matches any of the configured RetryableErrors
 func isRetryable(stdout string, stderr string, tferr error, terragruntOptions *options.TerragruntOptions) bool {
-	if !terragruntOptions.AutoRetry || tferr == nil {
+	// A command that exceeded its timeout is likely to exceed it again.
+	if !terragruntOptions.AutoRetry || tferr == nil || shell.IsTimeoutError(tferr) {
 		return false
 	}
 	// When -json is enabled, Terraform will send all output, errors included, to stdout.
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	goerrors "github.com/go-errors/errors"
 	"github.com/gruntwork-io/go-commons/errors"
@@ -243,6 +244,82 @@ func TestAutoRetryFalseDisablesRetry(t *testing.T) {
 	require.False(t, retryable, "The error should not retry")
 }
 
+func TestGetRetryRule(t *testing.T) {
+	t.Parallel()
+
+	tgOptions, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	tgOptions.RetryableErrors = []string{".*timeout.*"}
+	tgOptions.RetryRules = []options.RetryRule{
+		{Name: "throttling", On: []string{".*Throttling.*"}, MaxAttempts: 5, ExponentialBackoff: true},
+		{Name: "consistency", On: []string{".*NotFound.*", ".*does not exist.*"}, MaxAttempts: 2},
+	}
+	tferr := errors.WithStackTrace(goerrors.New("dummy error"))
+
+	rule := getRetryRule("", "Error: Throttling: Rate exceeded", tferr, tgOptions)
+	require.NotNil(t, rule)
+	assert.Equal(t, "throttling", rule.Name)
+
+	rule = getRetryRule("role does not exist", "", tferr, tgOptions)
+	require.NotNil(t, rule)
+	assert.Equal(t, "consistency", rule.Name)
+
+	// The errors that don't match any rule fall back to retryable_errors.
+	rule = getRetryRule("", "i/o timeout", tferr, tgOptions)
+	require.NotNil(t, rule)
+	assert.Empty(t, rule.Name)
+	assert.Equal(t, tgOptions.RetryMaxAttempts, rule.MaxAttempts)
+
+	assert.Nil(t, getRetryRule("", "Error: invalid argument", tferr, tgOptions))
+	assert.Nil(t, getRetryRule("", "Error: Throttling", nil, tgOptions))
+
+	// Disabling the auto retry only disables retryable_errors, not the rules of the errors block.
+	tgOptions.AutoRetry = false
+	rule = getRetryRule("", "Error: Throttling", tferr, tgOptions)
+	require.NotNil(t, rule)
+	assert.Equal(t, "throttling", rule.Name)
+	assert.Nil(t, getRetryRule("", "i/o timeout", tferr, tgOptions))
+}
+
+func TestGetIgnoreRule(t *testing.T) {
+	t.Parallel()
+
+	tgOptions, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	tgOptions.IgnoreRules = []options.IgnoreRule{
+		{Name: "missing-alarm", On: []string{".*AlarmNotFound.*"}, Message: "The alarm is deleted by the cleanup job."},
+	}
+	tferr := errors.WithStackTrace(goerrors.New("dummy error"))
+
+	rule := getIgnoreRule("", "Error: AlarmNotFound", tferr, tgOptions)
+	require.NotNil(t, rule)
+	assert.Equal(t, "missing-alarm", rule.Name)
+
+	assert.Nil(t, getIgnoreRule("", "Error: invalid argument", tferr, tgOptions))
+}
+
+func TestRetryRuleSleepBeforeRetry(t *testing.T) {
+	t.Parallel()
+
+	constant := options.RetryRule{SleepInterval: 2 * time.Second}
+	assert.Equal(t, 2*time.Second, constant.SleepBeforeRetry(1))
+	assert.Equal(t, 2*time.Second, constant.SleepBeforeRetry(3))
+
+	exponential := options.RetryRule{SleepInterval: 2 * time.Second, ExponentialBackoff: true}
+	assert.Equal(t, 2*time.Second, exponential.SleepBeforeRetry(1))
+	assert.Equal(t, 4*time.Second, exponential.SleepBeforeRetry(2))
+	assert.Equal(t, 8*time.Second, exponential.SleepBeforeRetry(3))
+
+	// The backoff is capped rather than overflowing with many attempts.
+	assert.Equal(t, options.MaxRetrySleepInterval, exponential.SleepBeforeRetry(64))
+	assert.Equal(t, options.MaxRetrySleepInterval, exponential.SleepBeforeRetry(1000000))
+
+	long := options.RetryRule{SleepInterval: 2 * time.Hour, ExponentialBackoff: true}
+	assert.Equal(t, 2*time.Hour, long.SleepBeforeRetry(5))
+}
+
 func TestTerragruntHandlesCatastrophicTerraformFailure(t *testing.T) {
 	t.Parallel()
 
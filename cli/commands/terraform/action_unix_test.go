@@ -0,0 +1,107 @@
+//go:build !windows
+// +build !windows
+
+package terraform
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/gruntwork-io/terragrunt/util"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// optionsWithFailingTerraform returns options running a fake terraform that writes the given error to stderr and
+// fails with the given exit code, and the path of the file in which every attempt is logged.
+func optionsWithFailingTerraform(t *testing.T, stderr string, exitCode int) (*options.TerragruntOptions, string) {
+	workingDir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\necho attempt >> attempts.log\necho '%s' >&2\nexit %d\n", stderr, exitCode)
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "terraform"), []byte(script), 0755))
+
+	tgOptions, err := options.NewTerragruntOptionsForTest(filepath.Join(workingDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+	tgOptions.WorkingDir = workingDir
+	tgOptions.TerraformPath = filepath.Join(workingDir, "terraform")
+	tgOptions.TerraformCliArgs = []string{"apply"}
+	return tgOptions, filepath.Join(workingDir, "attempts.log")
+}
+
+func countAttempts(t *testing.T, attemptsLog string) int {
+	contents, err := os.ReadFile(attemptsLog)
+	require.NoError(t, err)
+	return strings.Count(string(contents), "attempt")
+}
+
+func TestRunTerraformWithRetryRule(t *testing.T) {
+	t.Parallel()
+
+	tgOptions, attemptsLog := optionsWithFailingTerraform(t, "Error: Throttling: Rate exceeded", 1)
+	tgOptions.RetryRules = []options.RetryRule{
+		{Name: "throttling", On: []string{".*Throttling.*"}, MaxAttempts: 3},
+	}
+	var matches []options.ErrorRuleMatch
+	tgOptions.OnErrorRuleMatch = func(match options.ErrorRuleMatch) {
+		matches = append(matches, match)
+	}
+
+	err := runTerraformWithRetry(tgOptions)
+	require.Error(t, err)
+
+	var maxRetriesErr MaxRetriesExceeded
+	require.ErrorAs(t, errors.Unwrap(err), &maxRetriesErr)
+	assert.Contains(t, err.Error(), "Exhausted retries (3) of the retry rule throttling")
+	assert.Equal(t, 3, countAttempts(t, attemptsLog))
+	assert.Equal(t, []options.ErrorRuleMatch{
+		{Rule: "throttling", Action: options.ErrorRuleActionRetry},
+		{Rule: "throttling", Action: options.ErrorRuleActionRetry},
+	}, matches)
+}
+
+func TestRunTerraformWithIgnoreRule(t *testing.T) {
+	t.Parallel()
+
+	tgOptions, attemptsLog := optionsWithFailingTerraform(t, "Error: AlarmNotFound", 1)
+	tgOptions.RetryableErrors = []string{".*"}
+	tgOptions.IgnoreRules = []options.IgnoreRule{
+		{Name: "missing-alarm", On: []string{".*AlarmNotFound.*"}, Message: "The alarm is deleted by the cleanup job."},
+	}
+	var matches []options.ErrorRuleMatch
+	tgOptions.OnErrorRuleMatch = func(match options.ErrorRuleMatch) {
+		matches = append(matches, match)
+	}
+
+	// The ignored error is not retried, even if it matches retryable_errors.
+	require.NoError(t, runTerraformWithRetry(tgOptions))
+	assert.Equal(t, 1, countAttempts(t, attemptsLog))
+	assert.Equal(t, []options.ErrorRuleMatch{
+		{Rule: "missing-alarm", Action: options.ErrorRuleActionIgnore, Message: "The alarm is deleted by the cleanup job."},
+	}, matches)
+}
+
+func TestRunTerraformWithNonFatalExitCode(t *testing.T) {
+	t.Parallel()
+
+	tgOptions, attemptsLog := optionsWithFailingTerraform(t, "Note: Objects have changed outside of Terraform", 2)
+	tgOptions.RetryableErrors = []string{".*"}
+	tgOptions.NonFatalExitCodes = []int{2}
+	logs := &bytes.Buffer{}
+	tgOptions.Logger = util.CreateLogEntryWithWriter(logs, "", logrus.DebugLevel, nil)
+
+	// The error is returned with its exit code, without being retried or logged as a failure.
+	err := runTerraformWithRetry(tgOptions)
+	require.Error(t, err)
+	exitCode, err := shell.GetExitCode(err)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, 1, countAttempts(t, attemptsLog))
+	assert.NotContains(t, logs.String(), "invocation failed")
+}
@@ -46,8 +46,12 @@ func (err ModuleIsProtected) Error() string {
 
 type MaxRetriesExceeded struct {
 	Opts *options.TerragruntOptions
+	Rule *options.RetryRule
 }
 
 func (err MaxRetriesExceeded) Error() string {
-	return fmt.Sprintf("Exhausted retries (%v) for command %v %v", err.Opts.RetryMaxAttempts, err.Opts.TerraformPath, strings.Join(err.Opts.TerraformCliArgs, " "))
+	if err.Rule.Name != "" {
+		return fmt.Sprintf("Exhausted retries (%v) of the retry rule %s for command %v %v", err.Rule.MaxAttempts, err.Rule.Name, err.Opts.TerraformPath, strings.Join(err.Opts.TerraformCliArgs, " "))
+	}
+	return fmt.Sprintf("Exhausted retries (%v) for command %v %v", err.Rule.MaxAttempts, err.Opts.TerraformPath, strings.Join(err.Opts.TerraformCliArgs, " "))
 }
@@ -1,6 +1,6 @@
 // `validate-inputs` command collects all the terraform variables defined in the target module, and the terragrunt
-// inputs that are configured, and compare the two to determine if there are any unused inputs or undefined required
-// inputs.
+// inputs that are configured, and compare the two to determine if there are any unused inputs, undefined required
+// inputs, or inputs that do not match the type of their variable.
 
 package validateinputs
 
@@ -9,9 +9,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/google/shlex"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	"github.com/gruntwork-io/terragrunt/cli/commands/terraform"
 	"github.com/gruntwork-io/terragrunt/config"
@@ -40,6 +45,15 @@ func runValidateInputs(opts *options.TerragruntOptions, cfg *config.TerragruntCo
 		return err
 	}
 
+	variables, err := tr.ModuleVariablesWithTypes(opts.WorkingDir)
+	if err != nil {
+		return err
+	}
+	mismatches, err := getInputTypeMismatches(opts, cfg, variables)
+	if err != nil {
+		return err
+	}
+
 	// Unused variables are those that are passed in by terragrunt, but are not defined in terraform.
 	unusedVars := []string{}
 	for _, varName := range allInputs {
@@ -79,10 +93,20 @@ func runValidateInputs(opts *options.TerragruntOptions, cfg *config.TerragruntCo
 		opts.Logger.Debug(fmt.Sprintf("Strict mode enabled: %t", opts.ValidateStrict))
 	}
 
+	if len(mismatches) > 0 {
+		opts.Logger.Error("The following inputs do not match the type of their variable:\n")
+		for _, mismatch := range mismatches {
+			opts.Logger.Errorf("\t- %s", mismatch)
+		}
+		opts.Logger.Error("")
+	} else {
+		opts.Logger.Info("All inputs passed in by terragrunt match the type of their variable")
+	}
+
 	// Return an error when there are misaligned inputs. Terragrunt strict mode defaults to false. When it is false,
-	// an error will only be returned if required inputs are missing. When strict mode is true, an error will be
-	// returned if required inputs are missing OR if any unused variables are passed
-	if len(missingVars) > 0 || len(unusedVars) > 0 && opts.ValidateStrict {
+	// an error will only be returned if required inputs are missing or inputs do not match the type of their variable.
+	// When strict mode is true, an error will also be returned if any unused variables are passed
+	if len(missingVars) > 0 || len(mismatches) > 0 || len(unusedVars) > 0 && opts.ValidateStrict {
 		return fmt.Errorf(fmt.Sprintf("Terragrunt configuration has misaligned inputs. Strict mode enabled: %t.", opts.ValidateStrict))
 	} else if len(unusedVars) > 0 {
 		opts.Logger.Warn("Terragrunt configuration has misaligned inputs, but running in relaxed mode so ignoring.")
@@ -140,6 +164,160 @@ func getDefinedTerragruntInputs(opts *options.TerragruntOptions, cfg *config.Ter
 	return out, nil
 }
 
+// inputTypeMismatch is an input of the inputs block whose value does not match the type of its variable.
+type inputTypeMismatch struct {
+	// Path is the path of the value in the input that does not match, e.g. subnets[0].cidr.
+	Path string
+	Err  string
+	// File is the config the input is defined in, and Include the label of the include block that included that config,
+	// if it is not the config of the module itself.
+	File    string
+	Include string
+}
+
+func (mismatch inputTypeMismatch) String() string {
+	location := fmt.Sprintf("defined in %s", mismatch.File)
+	if mismatch.Include != "" {
+		location = fmt.Sprintf("%s through include %q", location, mismatch.Include)
+	}
+	return fmt.Sprintf("%s: %s (%s)", mismatch.Path, mismatch.Err, location)
+}
+
+// getInputTypeMismatches checks the value of every input of the inputs block against the type constraint of the
+// variable it sets, as terraform does when the input is passed in, and returns the inputs that do not match, sorted by
+// path. Nested values are checked as well, e.g. each attribute of each object of a list(object(...)) variable.
+func getInputTypeMismatches(opts *options.TerragruntOptions, cfg *config.TerragruntConfig, variables []*tr.ModuleVariable) ([]inputTypeMismatch, error) {
+	mismatches := []inputTypeMismatch{}
+	for _, variable := range variables {
+		input, hasInput := cfg.Inputs[variable.Name]
+		if !hasInput {
+			continue
+		}
+
+		value, err := inputAsCty(input)
+		if err != nil {
+			return nil, err
+		}
+		file, include := getInputSource(opts, cfg, variable.Name)
+
+		// The input of a variable whose type constraint can not be parsed can not be checked: it is reported on its
+		// own, and the other inputs are still checked.
+		if variable.TypeErr != nil {
+			mismatches = append(mismatches, inputTypeMismatch{Path: variable.Name, Err: variable.TypeErr.Error(), File: file, Include: include})
+			continue
+		}
+
+		for _, mismatch := range checkInputType(variable.Name, value, variable.Type) {
+			mismatch.File = file
+			mismatch.Include = include
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Path < mismatches[j].Path
+	})
+	return mismatches, nil
+}
+
+// inputAsCty converts the value of an input, as parsed from the config, back to a cty value, using json as an
+// intermediary representation like terragrunt does when it passes the input to terraform.
+func inputAsCty(input interface{}) (cty.Value, error) {
+	jsonBytes, err := json.Marshal(input)
+	if err != nil {
+		return cty.NilVal, errors.WithStackTrace(err)
+	}
+	var value ctyjson.SimpleJSONValue
+	if err := value.UnmarshalJSON(jsonBytes); err != nil {
+		return cty.NilVal, errors.WithStackTrace(err)
+	}
+	return value.Value, nil
+}
+
+// checkInputType checks the given value, found at the given path of the inputs, against the given type. Collections
+// and objects are checked element by element, so that every nested value that does not match is reported with its own
+// path, e.g. subnets[0].cidr.
+func checkInputType(path string, value cty.Value, valueType cty.Type) []inputTypeMismatch {
+	if valueType == cty.DynamicPseudoType || value.IsNull() || !value.IsKnown() {
+		return nil
+	}
+
+	mismatches := []inputTypeMismatch{}
+	actualType := value.Type()
+	isSequence := actualType.IsTupleType() || actualType.IsListType() || actualType.IsSetType()
+	isMapping := actualType.IsObjectType() || actualType.IsMapType()
+
+	switch {
+	case (valueType.IsListType() || valueType.IsSetType()) && isSequence:
+		for i, element := range value.AsValueSlice() {
+			mismatches = append(mismatches, checkInputType(fmt.Sprintf("%s[%d]", path, i), element, valueType.ElementType())...)
+		}
+	case valueType.IsTupleType() && isSequence && value.LengthInt() == len(valueType.TupleElementTypes()):
+		for i, element := range value.AsValueSlice() {
+			mismatches = append(mismatches, checkInputType(fmt.Sprintf("%s[%d]", path, i), element, valueType.TupleElementType(i))...)
+		}
+	case valueType.IsMapType() && isMapping:
+		elements := value.AsValueMap()
+		for _, key := range sortedKeys(elements) {
+			mismatches = append(mismatches, checkInputType(fmt.Sprintf("%s[%q]", path, key), elements[key], valueType.ElementType())...)
+		}
+	case valueType.IsObjectType() && isMapping:
+		attributes := value.AsValueMap()
+		attributeTypes := valueType.AttributeTypes()
+		for _, name := range sortedKeys(attributeTypes) {
+			attribute, hasAttribute := attributes[name]
+			if !hasAttribute {
+				if !valueType.AttributeOptional(name) {
+					mismatches = append(mismatches, inputTypeMismatch{Path: path, Err: fmt.Sprintf("attribute %q is required", name)})
+				}
+				continue
+			}
+			mismatches = append(mismatches, checkInputType(path+"."+name, attribute, attributeTypes[name])...)
+		}
+	}
+	if len(mismatches) > 0 {
+		return mismatches
+	}
+
+	// The value may still not convert as a whole, e.g. if it is not a collection at all, or if the elements of a
+	// list(any) have different types.
+	if _, err := convert.Convert(value, valueType); err != nil {
+		return []inputTypeMismatch{{Path: path, Err: err.Error()}}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the given map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getInputSource returns the config file the given input is defined in, and the label of the include block of the
+// module config that included that file. The include label is empty if the input is defined in the module config
+// itself.
+func getInputSource(opts *options.TerragruntOptions, cfg *config.TerragruntConfig, inputName string) (string, string) {
+	file := opts.TerragruntConfigPath
+	if metadata, found := cfg.GetMapFieldMetadata(config.MetadataInputs, inputName); found && metadata[config.FoundInFile] != "" {
+		file = metadata[config.FoundInFile]
+	}
+
+	for label, include := range cfg.ProcessedIncludes {
+		includePath := include.Path
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(opts.TerragruntConfigPath), includePath)
+		}
+		if util.CleanPath(includePath) == util.CleanPath(file) {
+			return file, label
+		}
+	}
+	return file, ""
+}
+
 // getTerraformInputNamesFromEnvVar will check the runtime environment variables and the configured environment
 // variables from extra_arguments blocks to see if there are any TF_VAR environment variables that set terraform
 // variables. This will return the list of names of variables that are set in this way by the given terragrunt
@@ -1,9 +1,14 @@
 package validateinputs
 
 import (
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	tr "github.com/gruntwork-io/terragrunt/terraform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -67,3 +72,75 @@ func TestGetVarFlagsFromExtraArgs(t *testing.T) {
 	}
 
 }
+
+func TestGetInputTypeMismatches(t *testing.T) {
+	t.Parallel()
+
+	moduleDir := t.TempDir()
+	variables := `
+variable "name" {
+  type = string
+}
+
+variable "instance_count" {
+  type = number
+}
+
+variable "subnets" {
+  type = list(object({
+    cidr = string
+    zone = optional(string, "a")
+  }))
+}
+
+variable "tags" {
+  type = map(string)
+}
+
+variable "anything" {}
+
+variable "broken" {
+  type = list(strin)
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "variables.tf"), []byte(variables), 0644))
+	moduleVariables, err := tr.ModuleVariablesWithTypes(moduleDir)
+	require.NoError(t, err)
+
+	configPath := filepath.Join(moduleDir, "terragrunt.hcl")
+	rootPath := filepath.Join(filepath.Dir(moduleDir), "root.hcl")
+	opts, err := options.NewTerragruntOptionsForTest(configPath)
+	require.NoError(t, err)
+
+	cfg := &config.TerragruntConfig{
+		Inputs: map[string]interface{}{
+			"name":           "app",
+			"instance_count": "three",
+			"subnets": []interface{}{
+				map[string]interface{}{"cidr": "10.0.0.0/24"},
+				map[string]interface{}{"zone": "b"},
+				map[string]interface{}{"cidr": []interface{}{"10.0.1.0/24"}},
+			},
+			"tags":     map[string]interface{}{"team": map[string]interface{}{"name": "platform"}},
+			"anything": []interface{}{1, "two"},
+			"broken":   []interface{}{"a"},
+		},
+		ProcessedIncludes: config.IncludeConfigs{"root": {Name: "root", Path: "../root.hcl"}},
+	}
+	cfg.SetFieldMetadataMap(config.MetadataInputs, map[string]interface{}{"instance_count": nil, "subnets": nil}, map[string]interface{}{config.FoundInFile: configPath})
+	cfg.SetFieldMetadataWithType(config.MetadataInputs, "tags", map[string]interface{}{config.FoundInFile: rootPath})
+
+	mismatches, err := getInputTypeMismatches(opts, cfg, moduleVariables)
+	require.NoError(t, err)
+
+	// The variable whose type can't be parsed is reported once, and the other inputs are still checked.
+	require.NotEmpty(t, mismatches)
+	assert.Equal(t, "broken", mismatches[0].Path)
+	assert.Contains(t, mismatches[0].Err, "Invalid type constraint for variable broken")
+	assert.Equal(t, []inputTypeMismatch{
+		{Path: "instance_count", Err: "a number is required", File: configPath},
+		{Path: "subnets[1]", Err: `attribute "cidr" is required`, File: configPath},
+		{Path: "subnets[2].cidr", Err: "string required", File: configPath},
+		{Path: `tags["team"]`, Err: "string required", File: rootPath, Include: "root"},
+	}, mismatches[1:])
+}
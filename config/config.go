@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gruntwork-io/terragrunt/telemetry"
 
@@ -57,8 +58,14 @@ const (
 	MetadataRetryableErrors             = "retryable_errors"
 	MetadataRetryMaxAttempts            = "retry_max_attempts"
 	MetadataRetrySleepIntervalSec       = "retry_sleep_interval_sec"
+	MetadataErrors                      = "errors"
 	MetadataDependentModules            = "dependent_modules"
 	MetadataInclude                     = "include"
+	MetadataLabels                      = "labels"
+	MetadataTimeout                     = "timeout"
+	MetadataFunction                    = "function"
+	MetadataPlugin                      = "plugin"
+	MetadataFeatureFlag                 = "feature"
 )
 
 var (
@@ -99,6 +106,9 @@ type TerragruntConfig struct {
 	RetryableErrors             []string
 	RetryMaxAttempts            *int
 	RetrySleepIntervalSec       *int
+	Errors                      *ErrorsConfig
+	Labels                      map[string]string
+	Timeout                     *string
 
 	// Fields used for internal tracking
 	// Indicates whether or not this is the result of a partial evaluation
@@ -188,13 +198,32 @@ type terragruntConfigFile struct {
 	RetryMaxAttempts      *int     `hcl:"retry_max_attempts,optional"`
 	RetrySleepIntervalSec *int     `hcl:"retry_sleep_interval_sec,optional"`
 
-	// This struct is used for validating and parsing the entire terragrunt config. Since locals and include are
-	// evaluated in a completely separate cycle, it should not be evaluated here. Otherwise, we can't support self
-	// referencing other elements in the same block.
+	// Retry and ignore rules for the errors of the terraform commands, e.g.:
+	//
+	// errors {
+	//   retry "throttling" {
+	//     on           = [".*Throttling.*"]
+	//     max_attempts = 5
+	//     backoff      = "exponential"
+	//   }
+	// }
+	Errors *ErrorsConfig `hcl:"errors,block"`
+
+	Labels map[string]string `hcl:"labels,optional"`
+
+	// Maximum duration of the run of this unit, across all of its commands, e.g. "30m". Overrides --terragrunt-timeout.
+	Timeout *string `hcl:"timeout,optional"`
+
+	// This struct is used for validating and parsing the entire terragrunt config. Since locals, include, function,
+	// plugin and feature are evaluated in a completely separate cycle, it should not be evaluated here. Otherwise, we
+	// can't support self referencing other elements in the same block.
 	// We don't want to use the special Remain keyword here, as that would cause the checker to support parsing config
 	// that have extraneous, unsupported blocks and attributes.
-	Locals  *terragruntLocal          `hcl:"locals,block"`
-	Include []terragruntIncludeIgnore `hcl:"include,block"`
+	Locals       *terragruntLocal            `hcl:"locals,block"`
+	Include      []terragruntIncludeIgnore   `hcl:"include,block"`
+	Functions    []terragruntBaseBlockIgnore `hcl:"function,block"`
+	Plugins      []terragruntBaseBlockIgnore `hcl:"plugin,block"`
+	FeatureFlags []terragruntBaseBlockIgnore `hcl:"feature,block"`
 }
 
 // We use a struct designed to not parse the block, as locals and includes are parsed and decoded using a special
@@ -208,6 +237,13 @@ type terragruntIncludeIgnore struct {
 	Remain hcl.Body `hcl:",remain"`
 }
 
+// Function, plugin and feature blocks are decoded with the base blocks, into the functions and the variables of the
+// evaluation context.
+type terragruntBaseBlockIgnore struct {
+	Name   string   `hcl:"name,label"`
+	Remain hcl.Body `hcl:",remain"`
+}
+
 // Configuration for Terraform remote state as parsed from a terragrunt.hcl config file
 type remoteStateConfigFile struct {
 	Backend                       string                     `hcl:"backend,attr"`
@@ -291,6 +327,10 @@ type IncludeConfig struct {
 	Path          string  `hcl:"path,attr"`
 	Expose        *bool   `hcl:"expose,attr"`
 	MergeStrategy *string `hcl:"merge_strategy,attr"`
+
+	// RemoteSource is the go-getter source of the included config when it is fetched from a remote source, in which
+	// case Path is the local path of the fetched config.
+	RemoteSource string
 }
 
 func (cfg *IncludeConfig) String() string {
@@ -564,6 +604,19 @@ func adjustSourceWithMap(sourceMap map[string]string, source string, modulePath
 
 }
 
+// ParseTimeout parses a timeout, such as the timeout attribute of the config or --terragrunt-timeout, written as a
+// duration like "45m" or "1h30m".
+func ParseTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.WithStackTrace(InvalidTimeout{Value: value, Err: err})
+	}
+	if timeout <= 0 {
+		return 0, errors.WithStackTrace(InvalidTimeout{Value: value, Err: fmt.Errorf("the timeout must be positive")})
+	}
+	return timeout, nil
+}
+
 // Return the default path to use for the Terragrunt configuration that exists within the path giving preference to `terragrunt.hcl`
 func GetDefaultConfigPath(workingDir string) string {
 	// check if a configuration file was passed as `workingDir`.
@@ -677,6 +730,7 @@ func ParseConfigFile(opts *options.TerragruntOptions, ctx *ParsingContext, confi
 		"config_path": configPath,
 	}, func(childCtx context.Context) error {
 		// Parse the HCL file into an AST body that can be decoded multiple times later without having to re-parse
+		ctx.ReadRecorder.recordFile(configPath)
 		file, err := hclparse.NewParser().WithOptions(ctx.ParserOptions...).ParseFromFile(configPath)
 		if err != nil {
 			return err
@@ -745,12 +799,15 @@ func ParseConfig(ctx *ParsingContext, file *hclparse.File, includeFromChild *Inc
 	}
 
 	// Decode just the Base blocks. See the function docs for DecodeBaseBlocks for more info on what base blocks are.
-	trackInclude, locals, err := DecodeBaseBlocks(ctx, file, includeFromChild)
+	baseBlocks, err := DecodeBaseBlocks(ctx, file, includeFromChild)
 	if err != nil {
 		return nil, err
 	}
-	ctx = ctx.WithTrackInclude(trackInclude)
-	ctx = ctx.WithLocals(locals)
+	ctx = ctx.WithTrackInclude(baseBlocks.TrackInclude)
+	ctx = ctx.WithLocals(baseBlocks.Locals)
+	ctx = ctx.WithFunctions(baseBlocks.Functions)
+	ctx = ctx.WithFeatureFlags(baseBlocks.FeatureFlags)
+	ctx = ctx.WithIncludedFiles(baseBlocks.IncludedFiles)
 
 	if ctx.DecodedDependencies == nil {
 		// Decode just the `dependency` blocks, retrieving the outputs from the target terragrunt config in the
@@ -788,7 +845,8 @@ func ParseConfig(ctx *ParsingContext, file *hclparse.File, includeFromChild *Inc
 		if err != nil {
 			return nil, err
 		}
-		// Saving processed includes into configuration, direct assignment since nested includes aren't supported
+		// Saving processed includes into configuration: the include blocks of this config, not the ones of the configs it
+		// includes
 		mergedConfig.ProcessedIncludes = ctx.TrackInclude.CurrentMap
 		// Make sure the top level information that is not automatically merged in is captured on the merged config to
 		// ensure the proper representation of the config is captured.
@@ -979,6 +1037,14 @@ func convertToTerragruntConfig(ctx *ParsingContext, configPath string, terragrun
 		terragruntConfig.SetFieldMetadata(MetadataRetrySleepIntervalSec, defaultMetadata)
 	}
 
+	if terragruntConfigFromFile.Errors != nil {
+		if err := terragruntConfigFromFile.Errors.Validate(); err != nil {
+			return nil, err
+		}
+		terragruntConfig.Errors = terragruntConfigFromFile.Errors
+		terragruntConfig.SetFieldMetadata(MetadataErrors, defaultMetadata)
+	}
+
 	if terragruntConfigFromFile.DownloadDir != nil {
 		terragruntConfig.DownloadDir = *terragruntConfigFromFile.DownloadDir
 		terragruntConfig.SetFieldMetadata(MetadataDownloadDir, defaultMetadata)
@@ -1019,6 +1085,16 @@ func convertToTerragruntConfig(ctx *ParsingContext, configPath string, terragrun
 		terragruntConfig.SetFieldMetadata(MetadataIamAssumeRoleSessionName, defaultMetadata)
 	}
 
+	if terragruntConfigFromFile.Labels != nil {
+		terragruntConfig.Labels = terragruntConfigFromFile.Labels
+		terragruntConfig.SetFieldMetadata(MetadataLabels, defaultMetadata)
+	}
+
+	if terragruntConfigFromFile.Timeout != nil {
+		terragruntConfig.Timeout = terragruntConfigFromFile.Timeout
+		terragruntConfig.SetFieldMetadata(MetadataTimeout, defaultMetadata)
+	}
+
 	generateBlocks := []terragruntGenerateBlock{}
 	generateBlocks = append(generateBlocks, terragruntConfigFromFile.GenerateBlocks...)
 
@@ -113,6 +113,26 @@ func TerragruntConfigAsCty(config *TerragruntConfig) (cty.Value, error) {
 		output[MetadataRetrySleepIntervalSec] = retrySleepIntervalSecCty
 	}
 
+	if config.Errors != nil {
+		errorsCty, err := goTypeToCty(*config.Errors)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		output[MetadataErrors] = errorsCty
+	}
+
+	if len(config.Labels) > 0 {
+		labelsCty, err := goTypeToCty(config.Labels)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		output[MetadataLabels] = labelsCty
+	}
+
+	if config.Timeout != nil {
+		output[MetadataTimeout] = gostringToCty(*config.Timeout)
+	}
+
 	inputsCty, err := convertToCtyWithJson(config.Inputs)
 	if err != nil {
 		return cty.NilVal, err
@@ -195,6 +215,28 @@ func TerragruntConfigAsCtyWithMetadata(config *TerragruntConfig) (cty.Value, err
 		return cty.NilVal, err
 	}
 
+	if config.Errors != nil {
+		errorsCty, err := goTypeToCty(*config.Errors)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		if err := wrapWithMetadata(config, errorsCty, MetadataErrors, &output); err != nil {
+			return cty.NilVal, err
+		}
+	}
+
+	if len(config.Labels) > 0 {
+		if err := wrapWithMetadata(config, config.Labels, MetadataLabels, &output); err != nil {
+			return cty.NilVal, err
+		}
+	}
+
+	if config.Timeout != nil {
+		if err := wrapWithMetadata(config, *config.Timeout, MetadataTimeout, &output); err != nil {
+			return cty.NilVal, err
+		}
+	}
+
 	if err := wrapWithMetadata(config, config.DependentModulesPath, MetadataDependentModules, &output); err != nil {
 		return cty.NilVal, err
 	}
@@ -19,6 +19,7 @@ func TestTerragruntConfigAsCtyDrift(t *testing.T) {
 	testSource := "./foo"
 	testTrue := true
 	testFalse := false
+	timeout := "45m"
 	mockOutputs := cty.Zero
 	mockOutputsAllowedTerraformCommands := []string{"init"}
 	dependentModulesPath := []*string{&testSource}
@@ -84,6 +85,18 @@ func TestTerragruntConfigAsCtyDrift(t *testing.T) {
 			"quote": "the answer is 42",
 		},
 		DependentModulesPath: dependentModulesPath,
+		Labels: map[string]string{
+			"account": "prod",
+		},
+		Timeout: &timeout,
+		Errors: &ErrorsConfig{
+			Retry: []RetryConfig{
+				{Name: "throttling", On: []string{".*Throttling.*"}},
+			},
+			Ignore: []IgnoreConfig{
+				{Name: "harmless", On: []string{".*harmless.*"}},
+			},
+		},
 		TerragruntDependencies: []Dependency{
 			{
 				Name:                                "foo",
@@ -232,6 +245,12 @@ func terragruntConfigStructFieldToMapKey(t *testing.T, fieldName string) (string
 		return "retry_sleep_interval_sec", true
 	case "DependentModulesPath":
 		return "dependent_modules", true
+	case "Labels":
+		return "labels", true
+	case "Timeout":
+		return "timeout", true
+	case "Errors":
+		return "errors", true
 	default:
 		t.Fatalf("Unknown struct property: %s", fieldName)
 		// This should not execute
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"unicode/utf8"
 
@@ -79,6 +80,17 @@ var TERRAFORM_COMMANDS_NEED_LOCKING = []string{
 	"untaint",
 }
 
+// List of terraform commands that modify the state, after which the cached outputs of the module are invalidated
+var TERRAFORM_COMMANDS_THAT_MODIFY_STATE = []string{
+	"apply",
+	"destroy",
+	"import",
+	"refresh",
+	"state",
+	"taint",
+	"untaint",
+}
+
 // List of terraform commands that accept -var or -var-file
 var TERRAFORM_COMMANDS_NEED_VARS = []string{
 	"apply",
@@ -176,9 +188,15 @@ func createTerragruntEvalContext(ctx *ParsingContext, configPath string) (*hcl.E
 	for k, v := range tfscope.Functions() {
 		functions[k] = v
 	}
+	if ctx.ReadRecorder != nil {
+		wrapTerraformFunctionsForReadRecorder(ctx.ReadRecorder, tfscope.BaseDir, functions)
+	}
 	for k, v := range terragruntFunctions {
 		functions[k] = v
 	}
+	if err := addConfigFunctions(functions, ctx.Functions); err != nil {
+		return nil, err
+	}
 	for k, v := range ctx.PredefinedFunctions {
 		functions[k] = v
 	}
@@ -194,6 +212,9 @@ func createTerragruntEvalContext(ctx *ParsingContext, configPath string) (*hcl.E
 	if ctx.DecodedDependencies != nil {
 		evalCtx.Variables[MetadataDependency] = *ctx.DecodedDependencies
 	}
+	if ctx.FeatureFlags != nil {
+		evalCtx.Variables[MetadataFeatureFlag] = *ctx.FeatureFlags
+	}
 	if ctx.TrackInclude != nil && len(ctx.TrackInclude.CurrentList) > 0 {
 		// For each include block, check if we want to expose the included config, and if so, add under the include
 		// variable.
@@ -206,6 +227,23 @@ func createTerragruntEvalContext(ctx *ParsingContext, configPath string) (*hcl.E
 	return evalCtx, nil
 }
 
+// FunctionNames returns the sorted names of the functions that can be called in the expressions of the given config:
+// the terraform functions, the terragrunt ones and the functions of the parsing context, i.e. the functions the config
+// declares and the predefined functions.
+func FunctionNames(ctx *ParsingContext, configPath string) ([]string, error) {
+	evalCtx, err := createTerragruntEvalContext(ctx.WithTrackInclude(nil), configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(evalCtx.Functions))
+	for name := range evalCtx.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // Return the OS platform
 func getPlatform(ctx *ParsingContext) (string, error) {
 	return runtime.GOOS, nil
@@ -271,6 +309,17 @@ func getOriginalTerragruntDir(ctx *ParsingContext) (string, error) {
 
 // Return the parent directory where the Terragrunt configuration file lives
 func getParentTerragruntDir(ctx *ParsingContext, params []string) (string, error) {
+	// The remote parents are in the remote config cache, next to the other files of their source.
+	if ctx.TrackInclude != nil {
+		included, err := getSelectedIncludeBlock(*ctx.TrackInclude, params)
+		if err != nil {
+			return "", err
+		}
+		if included != nil && included.RemoteSource != "" {
+			return filepath.ToSlash(filepath.Dir(included.Path)), nil
+		}
+	}
+
 	parentPath, err := pathRelativeFromInclude(ctx, params)
 	if err != nil {
 		return "", errors.WithStackTrace(err)
@@ -317,6 +366,9 @@ func runCommand(ctx *ParsingContext, args []string) (string, error) {
 		return "", errors.WithStackTrace(EmptyStringNotAllowedError("parameter to the run_cmd function"))
 	}
 
+	// The args are modified below, so keep them for the read recorder.
+	recordedArgs := append([]string{}, args...)
+
 	suppressOutput := false
 	currentPath := filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath)
 	cachePath := currentPath
@@ -345,6 +397,7 @@ func runCommand(ctx *ParsingContext, args []string) (string, error) {
 		} else {
 			ctx.TerragruntOptions.Logger.Debugf("run_cmd, cached output: [%s]", cachedValue)
 		}
+		ctx.ReadRecorder.recordCommand(ctx, recordedArgs, cachedValue)
 		return cachedValue, nil
 	}
 
@@ -364,6 +417,7 @@ func runCommand(ctx *ParsingContext, args []string) (string, error) {
 	// Persisting result in cache to avoid future re-evaluation
 	// see: https://github.com/gruntwork-io/terragrunt/issues/1427
 	runCommandCache.Put(cacheKey, value)
+	ctx.ReadRecorder.recordCommand(ctx, recordedArgs, value)
 	return value, nil
 }
 
@@ -374,6 +428,11 @@ func getEnvironmentVariable(ctx *ParsingContext, parameters []string) (string, e
 		return "", errors.WithStackTrace(err)
 	}
 	envValue, exists := ctx.TerragruntOptions.Env[parameterMap.Name]
+	if exists {
+		ctx.ReadRecorder.recordEnv(parameterMap.Name, &envValue)
+	} else {
+		ctx.ReadRecorder.recordEnv(parameterMap.Name, nil)
+	}
 
 	if !exists {
 		if parameterMap.IsRequired {
@@ -434,6 +493,7 @@ func findInParentFolders(
 			fileToFind = util.JoinPath(currentDir, fileToFindParam)
 		}
 
+		ctx.ReadRecorder.recordFile(fileToFind)
 		if util.FileExists(fileToFind) {
 			return fileToFind, nil
 		}
@@ -452,26 +512,17 @@ func pathRelativeToInclude(ctx *ParsingContext, params []string) (string, error)
 		return ".", nil
 	}
 
-	var included IncludeConfig
-	switch {
-	case ctx.TrackInclude.Original != nil:
-		included = *ctx.TrackInclude.Original
-	case len(ctx.TrackInclude.CurrentList) > 0:
-		// Called in child ctx, so we need to select the right include file.
-		selected, err := getSelectedIncludeBlock(*ctx.TrackInclude, params)
-		if err != nil {
-			return "", err
-		}
-		included = *selected
-	default:
+	included, err := getSelectedIncludeBlock(*ctx.TrackInclude, params)
+	if err != nil {
+		return "", err
+	} else if included == nil {
 		return ".", nil
 	}
 
 	currentPath := filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath)
-	includePath := filepath.Dir(included.Path)
-
-	if !filepath.IsAbs(includePath) {
-		includePath = util.JoinPath(currentPath, includePath)
+	includePath, err := includeRelativePathsDir(ctx, *included)
+	if err != nil {
+		return "", err
 	}
 
 	relativePath, err := util.GetPathRelativeTo(currentPath, includePath)
@@ -491,14 +542,34 @@ func pathRelativeFromInclude(ctx *ParsingContext, params []string) (string, erro
 		return ".", nil
 	}
 
-	includePath := filepath.Dir(included.Path)
+	includePath, err := includeRelativePathsDir(ctx, *included)
+	if err != nil {
+		return "", err
+	}
 	currentPath := filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath)
 
+	return util.GetPathRelativeTo(includePath, currentPath)
+}
+
+// includeRelativePathsDir returns the directory of the included config, that path_relative_to_include and
+// path_relative_from_include are relative to. The remote configs are fetched outside of the repository of the current
+// config, so the root of the repository is used instead, as if the included config was at the top of the repository.
+func includeRelativePathsDir(ctx *ParsingContext, included IncludeConfig) (string, error) {
+	currentPath := filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath)
+
+	if included.RemoteSource != "" {
+		repoRoot, err := shell.GitTopLevelDir(ctx.TerragruntOptions, currentPath)
+		if err != nil {
+			return "", errors.WithStackTrace(RemoteIncludeOutsideRepoError{Source: included.RemoteSource, Path: ctx.TerragruntOptions.TerragruntConfigPath})
+		}
+		return repoRoot, nil
+	}
+
+	includePath := filepath.Dir(included.Path)
 	if !filepath.IsAbs(includePath) {
 		includePath = util.JoinPath(currentPath, includePath)
 	}
-
-	return util.GetPathRelativeTo(includePath, currentPath)
+	return includePath, nil
 }
 
 // getTerraformCommand returns the current terraform command in execution
@@ -509,6 +580,7 @@ func getTerraformCommand(ctx *ParsingContext) (string, error) {
 // getWorkingDir returns the current working dir
 func getWorkingDir(ctx *ParsingContext) (string, error) {
 	ctx.TerragruntOptions.Logger.Debugf("Start processing get_working_dir built-in function")
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameGetWorkingDir))
 	defer ctx.TerragruntOptions.Logger.Debugf("Complete processing get_working_dir built-in function")
 
 	// Initialize evaluation ctx extensions from base blocks.
@@ -550,6 +622,7 @@ func getDefaultRetryableErrors(ctx *ParsingContext) ([]string, error) {
 
 // Return the AWS account id associated to the current set of credentials
 func getAWSAccountID(ctx *ParsingContext) (string, error) {
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameGetAWSAccountID))
 	accountID, err := aws_helper.GetAWSAccountID(nil, ctx.TerragruntOptions)
 	if err == nil {
 		return accountID, nil
@@ -559,6 +632,7 @@ func getAWSAccountID(ctx *ParsingContext) (string, error) {
 
 // Return the ARN of the AWS identity associated with the current set of credentials
 func getAWSCallerIdentityARN(ctx *ParsingContext) (string, error) {
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameGetAWSCallerIdentityArn))
 	identityARN, err := aws_helper.GetAWSIdentityArn(nil, ctx.TerragruntOptions)
 	if err == nil {
 		return identityARN, nil
@@ -568,6 +642,7 @@ func getAWSCallerIdentityARN(ctx *ParsingContext) (string, error) {
 
 // Return the UserID of the AWS identity associated with the current set of credentials
 func getAWSCallerIdentityUserID(ctx *ParsingContext) (string, error) {
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameGetAWSCallerIdentityUserID))
 	userID, err := aws_helper.GetAWSUserID(nil, ctx.TerragruntOptions)
 	if err == nil {
 		return userID, nil
@@ -580,8 +655,13 @@ func getAWSCallerIdentityUserID(ctx *ParsingContext) (string, error) {
 func readTerragruntConfig(ctx *ParsingContext, configPath string, defaultVal *cty.Value) (cty.Value, error) {
 	// target config check: make sure the target config exists. If the file does not exist, and there is no default val,
 	// return an error. If the file does not exist but there is a default val, return the default val. Otherwise,
-	// proceed to parse the file as a terragrunt config file.
+	// proceed to parse the file as a terragrunt config file. The remote configs are fetched first.
+	configPath, err := resolveRemoteConfigPath(ctx, configPath)
+	if err != nil {
+		return cty.NilVal, err
+	}
 	targetConfig := getCleanedTargetConfigPath(configPath, ctx.TerragruntOptions.TerragruntConfigPath)
+	ctx.ReadRecorder.recordFile(targetConfig)
 	targetConfigFileExists := util.FileExists(targetConfig)
 	if !targetConfigFileExists && defaultVal == nil {
 		return cty.NilVal, errors.WithStackTrace(TerragruntConfigNotFoundError{Path: targetConfig})
@@ -729,6 +809,8 @@ var sopsCache = NewStringCache()
 
 // decrypts and returns sops encrypted utf-8 yaml or json data as a string
 func sopsDecryptFile(ctx *ParsingContext, params []string) (string, error) {
+	// The decrypted secrets must not be written to the on-disk cache.
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameSopsDecryptFile))
 	numParams := len(params)
 
 	var sourceFile string
@@ -791,7 +873,8 @@ func getTerragruntSourceCliFlag(ctx *ParsingContext) (string, error) {
 }
 
 // Return the selected include block based on a label passed in as a function param. Note that the assumption is that:
-//   - If the Original attribute is set, we are in the parent ctx so return that.
+//   - If the Original attribute is set, we are in the parent ctx so return that, unless the param is the label of one
+//     of the include blocks of the parent itself.
 //   - If there are no include blocks, no param is required and nil is returned.
 //   - If there is only one include block, no param is required and that is automatically returned.
 //   - If there is more than one include block, 1 param is required to use as the label name to lookup the include block
@@ -800,6 +883,11 @@ func getSelectedIncludeBlock(trackInclude TrackInclude, params []string) (*Inclu
 	importMap := trackInclude.CurrentMap
 
 	if trackInclude.Original != nil {
+		if len(params) == 1 {
+			if imported, hasKey := importMap[params[0]]; hasKey {
+				return &imported, nil
+			}
+		}
 		return trackInclude.Original, nil
 	}
 
@@ -910,6 +998,7 @@ func readTFVarsFile(ctx *ParsingContext, args []string) (string, error) {
 		return "", errors.WithStackTrace(err)
 	}
 
+	ctx.ReadRecorder.recordFile(varFile)
 	if !util.FileExists(varFile) {
 		return "", errors.WithStackTrace(TFVarFileNotFoundError{File: varFile})
 	}
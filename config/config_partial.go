@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 
 	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/gruntwork-io/terragrunt/config/hclparse"
@@ -25,6 +26,7 @@ const (
 	TerragruntInputs
 	TerragruntVersionConstraints
 	RemoteStateBlock
+	TerragruntLabels
 )
 
 // terragruntIncludeMultiple is a struct that can be used to only decode the include block with labels.
@@ -87,50 +89,113 @@ type terragruntRemoteState struct {
 	Remain      hcl.Body               `hcl:",remain"`
 }
 
+// terragruntLabels is a struct that can be used to only decode the labels attribute.
+type terragruntLabels struct {
+	Labels map[string]string `hcl:"labels,optional"`
+	Remain hcl.Body          `hcl:",remain"`
+}
+
 // terragruntInputs is a struct that can be used to only decode the inputs block.
 type terragruntInputs struct {
 	Inputs *cty.Value `hcl:"inputs,attr"`
 	Remain hcl.Body   `hcl:",remain"`
 }
 
+// DecodedBaseBlocks holds the bindings decoded from the base blocks of a config, see DecodeBaseBlocks.
+type DecodedBaseBlocks struct {
+	TrackInclude  *TrackInclude
+	Locals        *cty.Value
+	Functions     map[string]function.Function
+	FeatureFlags  *cty.Value
+	IncludedFiles map[string][]*hclparse.File
+}
+
 // DecodeBaseBlocks takes in a parsed HCL2 file and decodes the base blocks. Base blocks are blocks that should always
 // be decoded even in partial decoding, because they provide bindings that are necessary for parsing any block in the
 // file. Currently base blocks are:
 // - locals
 // - include
-func DecodeBaseBlocks(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*TrackInclude, *cty.Value, error) {
-	evalParsingContext, err := createTerragruntEvalContext(ctx, file.ConfigPath)
+// - function
+// - plugin
+// - feature
+func DecodeBaseBlocks(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*DecodedBaseBlocks, error) {
+	// The functions of the config that includes this one are not in scope here.
+	ctx = ctx.WithFunctions(nil)
+
+	// Decode just the `include` blocks
+	terragruntIncludeList, err := decodeIncludeBlocks(ctx, file, includeFromChild)
+	if err != nil {
+		return nil, err
+	}
+
+	trackInclude := getTrackInclude(terragruntIncludeList, includeFromChild)
+
+	// Parse the configs included at every level, which also checks that the includes have no cycle, unless they were
+	// parsed with the config that includes this one.
+	includedFiles, hierarchy, err := parseIncludedFiles(ctx, file, trackInclude, includeFromChild)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Decode just the `include` and `import` blocks, and verify that it's allowed here
-	terragruntIncludeList, err := decodeAsTerragruntInclude(
-		file,
-		evalParsingContext,
-	)
+	// Decode the function blocks of this config and the included ones, so that the functions can be used in the locals.
+	functions, err := decodeFunctionBlocks(ctx, file, includedFiles)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	trackInclude, err := getTrackInclude(ctx, terragruntIncludeList, includeFromChild)
+	// Decode the feature blocks, so that the feature flags can be used in the locals. The feature flags of the child
+	// config, if this config is parsed as one of its includes, take precedence over the defaults of this config.
+	featureFlags, err := decodeFeatureFlags(ctx, file, includedFiles, includeFromChild, functions)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Evaluate all the expressions in the locals block separately and generate the variables list to use in the
 	// evaluation ctx.
-	locals, err := evaluateLocalsBlock(ctx.WithTrackInclude(trackInclude), file)
+	locals, err := evaluateLocalsBlock(ctx.WithTrackInclude(trackInclude).WithFunctions(functions).WithFeatureFlags(featureFlags), file)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	localsAsCtyVal, err := convertValuesMapToCtyVal(locals)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	return &DecodedBaseBlocks{
+		TrackInclude:  trackInclude,
+		Locals:        &localsAsCtyVal,
+		Functions:     functions,
+		FeatureFlags:  featureFlags,
+		IncludedFiles: hierarchy,
+	}, nil
+}
+
+// AnalyzeBaseBlocks decodes the base blocks of the given config for its static analysis, e.g. by the linter or to find
+// the files read by the config, and returns them along with the evaluation context of its other blocks. The locals may
+// fail to evaluate outside of a real run (e.g. sops_decrypt_file without access to the keys), or because of the issues
+// of the config being analyzed: the base blocks are then limited to the include blocks, and the error is returned with
+// them. The base blocks are nil if the include blocks can't be decoded either, and the evaluation context is nil if it
+// can't be created.
+func AnalyzeBaseBlocks(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*DecodedBaseBlocks, *hcl.EvalContext, error) {
+	baseBlocks, err := DecodeBaseBlocks(ctx.WithTrackInclude(nil), file, includeFromChild)
+	if err != nil {
+		trackInclude, includeErr := decodeIncludesOnly(ctx.WithTrackInclude(nil).WithFunctions(nil).WithFeatureFlags(nil), file, includeFromChild)
+		if includeErr != nil {
+			return nil, nil, includeErr
+		}
+		baseBlocks = &DecodedBaseBlocks{TrackInclude: trackInclude}
 	}
 
-	return trackInclude, &localsAsCtyVal, nil
+	evalCtx, evalErr := createTerragruntEvalContext(ctx.WithTrackInclude(baseBlocks.TrackInclude).WithLocals(baseBlocks.Locals).
+		WithFunctions(baseBlocks.Functions).WithFeatureFlags(baseBlocks.FeatureFlags), file.ConfigPath)
+	if evalErr != nil {
+		evalCtx = nil
+		if err == nil {
+			err = evalErr
+		}
+	}
+	return baseBlocks, evalCtx, err
 }
 
 func PartialParseConfigFile(ctx *ParsingContext, configPath string, include *IncludeConfig) (*TerragruntConfig, error) {
@@ -144,28 +209,41 @@ func PartialParseConfigFile(ctx *ParsingContext, configPath string, include *Inc
 
 var terragruntConfigCache = NewTerragruntConfigCache()
 
-// Wrapper of PartialParseConfigString which checks for cached configs.
-// filename, configString, includeFromChild and decodeList are used for the cache key,
+// Wrapper of PartialParseConfigString which checks for cached configs, in memory and, if
+// --terragrunt-partial-parse-config-cache-dir is set, on disk.
+// filename, configString, includeFromChild, decodeList and the feature flags of the child are used for the cache key,
 // by getting the default value (%#v) through fmt.
 func TerragruntConfigFromPartialConfig(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*TerragruntConfig, error) {
 	var cacheKey = fmt.Sprintf("%#v-%#v-%#v-%#v", file.ConfigPath, file.Content(), includeFromChild, ctx.PartialParseDecodeList)
+	if includeFromChild != nil && ctx.FeatureFlags != nil {
+		cacheKey = fmt.Sprintf("%s-%#v", cacheKey, *ctx.FeatureFlags)
+	}
 
 	if ctx.TerragruntOptions.UsePartialParseConfigCache {
 		if config, found := terragruntConfigCache.Get(cacheKey); found {
 			ctx.TerragruntOptions.Logger.Debugf("Cache hit for '%s' (partial parsing), decodeList: '%v'.", file.ConfigPath, ctx.PartialParseDecodeList)
+			if recorder, found := partialParseConfigCacheReads.Load(cacheKey); found {
+				ctx.ReadRecorder.mergeFrom(recorder.(*configReadRecorder))
+			} else {
+				ctx.ReadRecorder.markUncacheable(fmt.Sprintf("the reads of %s cached in memory are unknown", file.ConfigPath))
+			}
 			return &config, nil
 		}
 
 		ctx.TerragruntOptions.Logger.Debugf("Cache miss for '%s' (partial parsing), decodeList: '%v'.", file.ConfigPath, ctx.PartialParseDecodeList)
 	}
 
-	config, err := PartialParseConfig(ctx, file, includeFromChild)
+	// The on-disk cache is only used along with the in-memory cache, so that a config is read from disk at most once.
+	config, recorder, err := partialParseConfigWithDiskCache(ctx, file, includeFromChild)
 	if err != nil {
 		return nil, err
 	}
 
 	if ctx.TerragruntOptions.UsePartialParseConfigCache {
 		terragruntConfigCache.Put(cacheKey, *config)
+		if recorder != nil {
+			partialParseConfigCacheReads.Store(cacheKey, recorder)
+		}
 	}
 
 	return config, nil
@@ -202,12 +280,15 @@ func PartialParseConfig(ctx *ParsingContext, file *hclparse.File, includeFromChi
 
 	// Decode just the Base blocks. See the function docs for DecodeBaseBlocks for more info on what base blocks are.
 	// Initialize evaluation ctx extensions from base blocks.
-	trackInclude, locals, err := DecodeBaseBlocks(ctx, file, includeFromChild)
+	baseBlocks, err := DecodeBaseBlocks(ctx, file, includeFromChild)
 	if err != nil {
 		return nil, err
 	}
-	ctx = ctx.WithTrackInclude(trackInclude)
-	ctx = ctx.WithLocals(locals)
+	ctx = ctx.WithTrackInclude(baseBlocks.TrackInclude)
+	ctx = ctx.WithLocals(baseBlocks.Locals)
+	ctx = ctx.WithFunctions(baseBlocks.Functions)
+	ctx = ctx.WithFeatureFlags(baseBlocks.FeatureFlags)
+	ctx = ctx.WithIncludedFiles(baseBlocks.IncludedFiles)
 
 	// Set parsed Locals on the parsed config
 	output, err := convertToTerragruntConfig(ctx, file.ConfigPath, &terragruntConfigFile{})
@@ -356,6 +437,14 @@ func PartialParseConfig(ctx *ParsingContext, file *hclparse.File, includeFromChi
 				output.RemoteState = remoteState
 			}
 
+		case TerragruntLabels:
+			decoded := terragruntLabels{}
+			err := file.Decode(&decoded, evalParsingContext)
+			if err != nil {
+				return nil, err
+			}
+			output.Labels = decoded.Labels
+
 		default:
 			return nil, InvalidPartialBlockName{decode}
 		}
@@ -367,7 +456,8 @@ func PartialParseConfig(ctx *ParsingContext, file *hclparse.File, includeFromChi
 		if err != nil {
 			return nil, err
 		}
-		// Saving processed includes into configuration, direct assignment since nested includes aren't supported
+		// Saving processed includes into configuration: the include blocks of this config, not the ones of the configs it
+		// includes
 		config.ProcessedIncludes = ctx.TrackInclude.CurrentMap
 		return config, nil
 	}
@@ -392,17 +482,54 @@ func partialParseIncludedConfig(ctx *ParsingContext, includedConfig *IncludeConf
 	)
 }
 
+// decodeIncludeBlocks decodes the `include` blocks of the given config. The `include` blocks of a config parsed as an
+// include of a child config are evaluated relative to that included config, rather than to the child config like the
+// rest of the included config, so that e.g. `find_in_parent_folders()` finds the parent of the included config whatever
+// the child. They can only call the built-in functions, and their relative paths are made absolute.
+func decodeIncludeBlocks(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) ([]IncludeConfig, error) {
+	if includeFromChild != nil {
+		includeCtx := *ctx
+		includeCtx.TerragruntOptions = ctx.TerragruntOptions.Clone(file.ConfigPath)
+		includeCtx.Locals = nil
+		includeCtx.DecodedDependencies = nil
+		includeCtx.TrackInclude = nil
+		includeCtx.FeatureFlags = nil
+		includeCtx.Functions = nil
+		ctx = &includeCtx
+	}
+
+	evalCtx, err := createTerragruntEvalContext(ctx, file.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	includes, err := decodeAsTerragruntInclude(ctx, file, evalCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if includeFromChild != nil {
+		for i := range includes {
+			if includes[i].Path != "" && !filepath.IsAbs(includes[i].Path) {
+				includes[i].Path = util.JoinPath(filepath.Dir(file.ConfigPath), includes[i].Path)
+			}
+		}
+	}
+	return includes, nil
+}
+
 // This decodes only the `include` blocks of a terragrunt config, so its value can be used while decoding the rest of
-// the config.
-// For consistency, `include` in the call to `file.Decode` is always assumed to be nil. Either it really is nil (parsing
-// the child config), or it shouldn't be used anyway (the parent config shouldn't have an include block).
-func decodeAsTerragruntInclude(file *hclparse.File, evalParsingContext *hcl.EvalContext) ([]IncludeConfig, error) {
+// the config. The remote configs included are fetched, and their paths are replaced by the local paths of the fetched
+// configs.
+// For consistency, `include` in the call to `file.Decode` is always assumed to be nil: the `include` variable is not
+// available in the `include` blocks.
+func decodeAsTerragruntInclude(ctx *ParsingContext, file *hclparse.File, evalParsingContext *hcl.EvalContext) ([]IncludeConfig, error) {
 	tgInc := terragruntIncludeMultiple{}
 	if err := file.Decode(&tgInc, evalParsingContext); err != nil {
 		return nil, err
 	}
 
-	return tgInc.Include, nil
+	return resolveRemoteIncludes(ctx, tgInc.Include)
 }
 
 // Custom error types
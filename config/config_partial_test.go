@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -355,3 +357,34 @@ dependency "ec2" {
 	require.NoError(t, err)
 	assert.Len(t, terragruntConfig.Dependencies.Paths, 1)
 }
+
+func TestPartialParseLabelsMergedWithInclude(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "root.hcl"), []byte(`
+labels = {
+  account = "prod"
+  team    = "platform"
+}
+`), 0644))
+
+	childDir := filepath.Join(rootDir, "child")
+	require.NoError(t, os.MkdirAll(childDir, 0755))
+	childConfigPath := filepath.Join(childDir, DefaultTerragruntConfigPath)
+	require.NoError(t, os.WriteFile(childConfigPath, []byte(`
+include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+labels = {
+  team = "data"
+}
+`), 0644))
+
+	ctx := NewParsingContext(context.Background(), mockOptionsForTestWithConfigPath(t, childConfigPath)).WithDecodeList(TerragruntLabels)
+	terragruntConfig, err := PartialParseConfigFile(ctx, childConfigPath, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"account": "prod", "team": "data"}, terragruntConfig.Labels)
+}
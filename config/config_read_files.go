@@ -0,0 +1,156 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// Functions that read the file passed as the first argument, mapped to whether the path is resolved like a terragrunt
+// config path (see getCleanedTargetConfigPath) or relative to the working directory.
+var fileReadingFunctions = map[string]bool{
+	FuncNameReadTerragruntConfig: true,
+	FuncNameSopsDecryptFile:      false,
+	FuncNameReadTfvarsFile:       false,
+}
+
+// FindFilesReadByConfig returns the canonical paths of the files the given config depends on: the configs it includes
+// and the files it reads with read_terragrunt_config, sops_decrypt_file and read_tfvars_file. The files read by those
+// configs are followed as well. Calls with a path that can not be evaluated without running the full parse (e.g. one
+// that references a dependency output) are ignored.
+func FindFilesReadByConfig(ctx *ParsingContext, configPath string) ([]string, error) {
+	visited := map[string]bool{}
+	if err := findFilesReadByConfig(ctx, configPath, nil, visited); err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for path := range visited {
+		if path != configPath {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+func findFilesReadByConfig(ctx *ParsingContext, configPath string, includeFromChild *IncludeConfig, visited map[string]bool) error {
+	if visited[configPath] {
+		return nil
+	}
+	visited[configPath] = true
+
+	file, err := hclparse.NewParser().WithOptions(ctx.ParserOptions...).ParseFromFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	if includeFromChild == nil {
+		ctx = ctx.WithTerragruntOptions(ctx.TerragruntOptions.Clone(configPath))
+	}
+
+	// The locals may fail to evaluate outside of a real run (e.g. sops_decrypt_file without access to the keys), in
+	// which case only the paths that depend on them are lost.
+	baseBlocks, evalCtx, err := AnalyzeBaseBlocks(ctx, file, includeFromChild)
+	if baseBlocks == nil || evalCtx == nil {
+		return err
+	}
+	if err != nil {
+		ctx.TerragruntOptions.Logger.Debugf("Could not evaluate the locals of %s, ignoring them: %v", configPath, err)
+	}
+	ctx = ctx.WithTrackInclude(baseBlocks.TrackInclude).WithLocals(baseBlocks.Locals).WithFunctions(baseBlocks.Functions).
+		WithFeatureFlags(baseBlocks.FeatureFlags)
+
+	for _, readPath := range findReadFileCalls(ctx, file, evalCtx) {
+		if visited[readPath] {
+			continue
+		}
+		if util.IsFile(readPath) && isTerragruntConfigFile(readPath) {
+			if err := findFilesReadByConfig(ctx, readPath, nil, visited); err != nil {
+				return err
+			}
+			continue
+		}
+		visited[readPath] = true
+	}
+
+	for _, include := range baseBlocks.TrackInclude.CurrentList {
+		includePath := include.Path
+		if !filepath.IsAbs(includePath) {
+			includePath = util.JoinPath(filepath.Dir(configPath), includePath)
+		}
+		includePath = util.CleanPath(includePath)
+
+		include := include
+		if err := findFilesReadByConfig(ctx, includePath, &include, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeIncludesOnly decodes the include blocks of the file, without evaluating the locals.
+func decodeIncludesOnly(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*TrackInclude, error) {
+	includeList, err := decodeIncludeBlocks(ctx, file, includeFromChild)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTrackInclude(includeList, includeFromChild), nil
+}
+
+// findReadFileCalls walks all the expressions of the file and returns the paths passed to the functions in
+// fileReadingFunctions.
+func findReadFileCalls(ctx *ParsingContext, file *hclparse.File, evalCtx *hcl.EvalContext) []string {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		// JSON configs are not walked.
+		return nil
+	}
+
+	paths := []string{}
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		call, ok := node.(*hclsyntax.FunctionCallExpr)
+		if !ok || len(call.Args) == 0 {
+			return nil
+		}
+
+		isConfigPath, ok := fileReadingFunctions[call.Name]
+		if !ok {
+			return nil
+		}
+
+		pathVal, diags := call.Args[0].Value(evalCtx)
+		if diags.HasErrors() || !pathVal.IsWhollyKnown() || pathVal.IsNull() || pathVal.Type() != cty.String {
+			ctx.TerragruntOptions.Logger.Debugf("Could not evaluate the path passed to %s at %s, ignoring it", call.Name, call.Range())
+			return nil
+		}
+
+		var path string
+		if isConfigPath {
+			path = getCleanedTargetConfigPath(pathVal.AsString(), file.ConfigPath)
+		} else {
+			canonicalPath, err := util.CanonicalPath(pathVal.AsString(), ctx.TerragruntOptions.WorkingDir)
+			if err != nil {
+				return nil
+			}
+			path = canonicalPath
+		}
+
+		paths = append(paths, path)
+		return nil
+	})
+
+	return paths
+}
+
+// isTerragruntConfigFile returns true if the file can be parsed as a terragrunt config.
+func isTerragruntConfigFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".hcl" || ext == ".json" && filepath.Ext(path[:len(path)-len(ext)]) == ".hcl"
+}
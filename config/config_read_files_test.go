@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+func TestFindFilesReadByConfig(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"root.hcl": `
+locals {
+  env = read_terragrunt_config(find_in_parent_folders("env.hcl"))
+}
+`,
+		"env.hcl": `
+locals {
+  vars = read_tfvars_file("${get_terragrunt_dir()}/common.tfvars")
+}
+`,
+		"common.tfvars":    `region = "us-east-1"`,
+		"app/secrets.json": `{}`,
+		"app/terragrunt.hcl": `
+include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+locals {
+  secrets = jsondecode(sops_decrypt_file("secrets.json"))
+  name    = "app"
+}
+
+inputs = {
+  other = read_terragrunt_config("${dependency.vpc.outputs.dir}/other.hcl")
+  name  = local.name
+}
+`,
+	}
+	rootDir := helpers.WriteTempFiles(t, files)
+
+	configPath := filepath.Join(rootDir, "app", DefaultTerragruntConfigPath)
+	ctx := NewParsingContext(context.Background(), mockOptionsForTestWithConfigPath(t, configPath))
+
+	actual, err := FindFilesReadByConfig(ctx, configPath)
+	require.NoError(t, err)
+
+	expected := []string{
+		filepath.Join(rootDir, "root.hcl"),
+		filepath.Join(rootDir, "env.hcl"),
+		filepath.Join(rootDir, "common.tfvars"),
+		filepath.Join(rootDir, "app", "secrets.json"),
+	}
+	assert.ElementsMatch(t, expected, actual)
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigSchema describes the attributes and the nested blocks of a block of the terragrunt configs, or of the configs
+// themselves, as they are decoded by terragrunt. It is meant for the tools that assist the editing of the configs,
+// e.g. the language server, and is derived from the hcl tags of the structs the configs are decoded into.
+type ConfigSchema struct {
+	// Labels is the number of labels of the block.
+	Labels int
+
+	// Attributes is the sorted list of the names of the attributes of the block.
+	Attributes []string
+
+	// Blocks are the schemas of the nested blocks, by block type.
+	Blocks map[string]*ConfigSchema
+
+	// Open is true if the block accepts any attribute, e.g. the locals block.
+	Open bool
+}
+
+// configSchemaBlockTypes are the types of the blocks that terragruntConfigFile leaves to its remain body, as they are
+// decoded separately in DecodeBaseBlocks.
+var configSchemaBlockTypes = map[string]reflect.Type{
+	MetadataInclude:     reflect.TypeOf(IncludeConfig{}),
+	MetadataFeatureFlag: reflect.TypeOf(FeatureFlag{}),
+	MetadataPlugin:      reflect.TypeOf(PluginConfig{}),
+}
+
+// TerragruntConfigSchema returns the schema of the terragrunt configs.
+func TerragruntConfigSchema() *ConfigSchema {
+	schema := configSchemaFromType(reflect.TypeOf(terragruntConfigFile{}))
+	for blockType, blockStructType := range configSchemaBlockTypes {
+		schema.Blocks[blockType] = configSchemaFromType(blockStructType)
+	}
+	schema.Blocks[MetadataLocals] = &ConfigSchema{Blocks: map[string]*ConfigSchema{}, Open: true}
+	// The function blocks are decoded by the userfunc extension of hcl, whose schema is not tagged.
+	schema.Blocks[MetadataFunction] = &ConfigSchema{
+		Labels:     1,
+		Attributes: []string{"params", "result", "variadic_param"},
+		Blocks:     map[string]*ConfigSchema{},
+	}
+	return schema
+}
+
+// configSchemaFromType returns the schema of the blocks decoded into the given struct type, from its hcl tags.
+func configSchemaFromType(structType reflect.Type) *ConfigSchema {
+	schema := &ConfigSchema{Blocks: map[string]*ConfigSchema{}}
+	attributes := map[string]bool{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		tag, ok := structType.Field(i).Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+		// The fields tagged without a kind are attributes, as for gohcl.
+		name, kind, _ := strings.Cut(tag, ",")
+
+		switch kind {
+		case "", "attr", "optional":
+			attributes[name] = true
+		case "label":
+			schema.Labels++
+		case "block":
+			blockType := structType.Field(i).Type
+			for blockType.Kind() == reflect.Ptr || blockType.Kind() == reflect.Slice {
+				blockType = blockType.Elem()
+			}
+			schema.Blocks[name] = configSchemaFromType(blockType)
+		}
+	}
+
+	for name := range attributes {
+		schema.Attributes = append(schema.Attributes, name)
+	}
+	sort.Strings(schema.Attributes)
+	return schema
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+func TestTerragruntConfigSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := TerragruntConfigSchema()
+	assert.Contains(t, schema.Attributes, "inputs")
+	assert.Contains(t, schema.Attributes, "remote_state")
+	assert.Contains(t, schema.Blocks, "remote_state")
+
+	dependency := schema.Blocks[MetadataDependency]
+	require.NotNil(t, dependency)
+	assert.Equal(t, 1, dependency.Labels)
+	assert.Contains(t, dependency.Attributes, "config_path")
+	// The attributes tagged without a kind are attributes as well.
+	assert.Contains(t, dependency.Attributes, "mock_outputs_merge_strategy_with_state")
+
+	hook := schema.Blocks["terraform"].Blocks["before_hook"]
+	require.NotNil(t, hook)
+	assert.Contains(t, hook.Attributes, "commands")
+
+	include := schema.Blocks[MetadataInclude]
+	require.NotNil(t, include)
+	assert.Equal(t, []string{"expose", "merge_strategy", "path"}, include.Attributes)
+
+	assert.True(t, schema.Blocks[MetadataLocals].Open)
+	assert.Equal(t, []string{"params", "result", "variadic_param"}, schema.Blocks[MetadataFunction].Attributes)
+}
+
+func TestFunctionNames(t *testing.T) {
+	t.Parallel()
+
+	opts, err := options.NewTerragruntOptionsForTest(DefaultTerragruntConfigPath)
+	require.NoError(t, err)
+
+	names, err := FunctionNames(NewParsingContext(context.Background(), opts), DefaultTerragruntConfigPath)
+	require.NoError(t, err)
+	assert.Contains(t, names, FuncNameFindInParentFolders)
+	assert.Contains(t, names, "jsonencode")
+	assert.IsIncreasing(t, names)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/gruntwork-io/terragrunt/options"
@@ -264,6 +265,97 @@ func TestParseIamAssumeRoleDuration(t *testing.T) {
 	assert.Equal(t, int64(36000), *terragruntConfig.IamAssumeRoleDuration)
 }
 
+func TestParseTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := `timeout = "1h30m"`
+
+	ctx := NewParsingContext(context.Background(), mockOptionsForTest(t))
+	terragruntConfig, err := ParseConfigString(ctx, DefaultTerragruntConfigPath, config, nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, terragruntConfig.Timeout)
+	timeout, err := ParseTimeout(*terragruntConfig.Timeout)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, timeout)
+}
+
+func TestParseTimeoutInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"30", "soon", "-5m", "0s"} {
+		_, err := ParseTimeout(value)
+		assert.IsType(t, InvalidTimeout{}, errors.Unwrap(err), value)
+	}
+}
+
+func TestParseErrorsBlock(t *testing.T) {
+	t.Parallel()
+
+	config := `
+errors {
+  retry "throttling" {
+    on                 = [".*Throttling.*", ".*Rate exceeded.*"]
+    max_attempts       = 5
+    sleep_interval_sec = 2
+    backoff            = "exponential"
+  }
+
+  retry "eventual_consistency" {
+    on = [".*NoSuchEntity.*"]
+  }
+
+  ignore "missing_alarm" {
+    on      = [".*AlarmNotFound.*"]
+    message = "The alarm is deleted by the cleanup job."
+  }
+}
+`
+
+	ctx := NewParsingContext(context.Background(), mockOptionsForTest(t))
+	terragruntConfig, err := ParseConfigString(ctx, DefaultTerragruntConfigPath, config, nil)
+	require.NoError(t, err)
+	require.NotNil(t, terragruntConfig.Errors)
+
+	retryRules := terragruntConfig.Errors.GetRetryRules(3, 5*time.Second)
+	assert.Equal(t, []options.RetryRule{
+		{Name: "throttling", On: []string{".*Throttling.*", ".*Rate exceeded.*"}, MaxAttempts: 5, SleepInterval: 2 * time.Second, ExponentialBackoff: true},
+		{Name: "eventual_consistency", On: []string{".*NoSuchEntity.*"}, MaxAttempts: 3, SleepInterval: 5 * time.Second},
+	}, retryRules)
+	assert.Equal(t, []options.IgnoreRule{
+		{Name: "missing_alarm", On: []string{".*AlarmNotFound.*"}, Message: "The alarm is deleted by the cleanup job."},
+	}, terragruntConfig.Errors.GetIgnoreRules())
+}
+
+func TestParseErrorsBlockInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{"NoPattern", `retry "a" { on = [] }`, "Need at least one regular expression"},
+		{"InvalidPattern", `retry "a" { on = ["("] }`, "Invalid regular expression"},
+		{"MaxAttempts", "retry \"a\" {\n  on = [\".*\"]\n  max_attempts = 0\n}", "Cannot have less than 1 max attempt"},
+		{"Backoff", "retry \"a\" {\n  on = [\".*\"]\n  backoff = \"linear\"\n}", "Unsupported backoff linear"},
+		{"DuplicateName", "ignore \"a\" { on = [\".*\"] }\nignore \"a\" { on = [\".*\"] }", "multiple ignore blocks with the same name: a"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := NewParsingContext(context.Background(), mockOptionsForTest(t))
+			_, err := ParseConfigString(ctx, DefaultTerragruntConfigPath, "errors {\n"+testCase.config+"\n}\n", nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
+
 func TestParseIamAssumeRoleSessionName(t *testing.T) {
 	t.Parallel()
 
@@ -646,13 +738,12 @@ func TestParseTerragruntConfigTwoLevels(t *testing.T) {
 	opts := mockOptionsForTestWithConfigPath(t, configPath)
 
 	ctx := NewParsingContext(context.Background(), opts)
-	_, actualErr := ParseConfigString(ctx, configPath, config, nil)
-	expectedErr := TooManyLevelsOfInheritanceError{
-		ConfigPath:             configPath,
-		FirstLevelIncludePath:  filepath.ToSlash(absPath(t, "../test/fixture-parent-folders/multiple-terragrunt-in-parents/child/"+DefaultTerragruntConfigPath)),
-		SecondLevelIncludePath: filepath.ToSlash(absPath(t, "../test/fixture-parent-folders/multiple-terragrunt-in-parents/child/"+DefaultTerragruntConfigPath)),
-	}
-	assert.True(t, errors.IsError(actualErr, expectedErr), "Expected error %v but got %v", expectedErr, actualErr)
+	terragruntConfig, err := ParseConfigString(ctx, configPath, config, nil)
+	require.NoError(t, err)
+
+	// The child config includes the root config, which is relative to the config parsed.
+	require.NotNil(t, terragruntConfig.RemoteState)
+	assert.Equal(t, "child/sub-child/terraform.tfstate", terragruntConfig.RemoteState.Config["key"])
 }
 
 func TestParseTerragruntConfigThreeLevels(t *testing.T) {
@@ -668,13 +759,11 @@ func TestParseTerragruntConfigThreeLevels(t *testing.T) {
 	opts := mockOptionsForTestWithConfigPath(t, configPath)
 
 	ctx := NewParsingContext(context.Background(), opts)
-	_, actualErr := ParseConfigString(ctx, configPath, config, nil)
-	expectedErr := TooManyLevelsOfInheritanceError{
-		ConfigPath:             configPath,
-		FirstLevelIncludePath:  absPath(t, "../test/fixture-parent-folders/multiple-terragrunt-in-parents/child/sub-child/"+DefaultTerragruntConfigPath),
-		SecondLevelIncludePath: absPath(t, "../test/fixture-parent-folders/multiple-terragrunt-in-parents/child/sub-child/"+DefaultTerragruntConfigPath),
-	}
-	assert.True(t, errors.IsError(actualErr, expectedErr), "Expected error %v but got %v", expectedErr, actualErr)
+	terragruntConfig, err := ParseConfigString(ctx, configPath, config, nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, terragruntConfig.RemoteState)
+	assert.Equal(t, "child/sub-child/sub-sub-child/terraform.tfstate", terragruntConfig.RemoteState.Config["key"])
 }
 
 func TestParseTerragruntConfigEmptyConfig(t *testing.T) {
@@ -1301,6 +1390,10 @@ func ptr(str string) *string {
 	return &str
 }
 
+func ptrInt(value int) *int {
+	return &value
+}
+
 // Run a benchmark on ReadTerragruntConfig for all fixtures possible.
 // This should reveal regressions on execution time due to new, changed or removed features.
 func BenchmarkReadTerragruntConfig(b *testing.B) {
@@ -107,6 +107,18 @@ func wrapVoidToEmptyStringAsFuncImpl() function.Function {
 	})
 }
 
+// Create a cty Function that takes any input parameters and returns as output an unknown value, to stand in for the
+// functions that can not be run during a static analysis.
+func wrapAnyToUnknownAsFuncImpl() function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{Type: cty.DynamicPseudoType, AllowNull: true, AllowUnknown: true, AllowDynamicType: true},
+		Type:     function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.DynamicVal, nil
+		},
+	})
+}
+
 // Create a cty Function that takes no input parameters and returns as output a string slice. The implementation of the
 // function calls the given toWrap function, passing it the given include and terragruntOptions.
 func wrapVoidToStringSliceAsFuncImpl(
@@ -167,6 +167,8 @@ var outputLocks = sync.Map{}
 //
 //	consider whether or not the implementation of the cyclic dependency detection still makes sense.
 func decodeAndRetrieveOutputs(ctx *ParsingContext, file *hclparse.File) (*cty.Value, error) {
+	ctx.ReadRecorder.markUncacheable("it reads the outputs of its dependencies")
+
 	evalParsingContext, err := createTerragruntEvalContext(ctx, file.ConfigPath)
 	if err != nil {
 		return nil, err
@@ -392,6 +394,11 @@ func getTerragruntOutputIfAppliedElseConfiguredDefault(ctx *ParsingContext, depe
 		ctx.TerragruntOptions.Logger.Debugf("Skipping outputs reading for disabled dependency %s", dependencyConfig.Name)
 		return dependencyConfig.MockOutputs, nil
 	}
+	// The outputs are unknown during a static analysis, as reading them runs terragrunt output on the dependency.
+	if ctx.StaticAnalysis {
+		outputs := cty.DynamicVal
+		return &outputs, nil
+	}
 	if dependencyConfig.shouldGetOutputs() {
 		outputVal, isEmpty, err := getTerragruntOutput(ctx, dependencyConfig)
 		if err != nil {
@@ -515,8 +522,8 @@ func getOutputJsonWithCaching(ctx *ParsingContext, targetConfig string) ([]byte,
 		return rawJsonBytes.([]byte), nil
 	}
 
-	// Cache miss, so look up the output and store in cache
-	newJsonBytes, err := getTerragruntOutputJson(ctx, targetConfig)
+	// Cache miss, so look up the output, from the on-disk cache if enabled, and store in cache
+	newJsonBytes, err := getOutputJsonWithDiskCache(ctx, targetConfig)
 	if err != nil {
 		return nil, err
 	}
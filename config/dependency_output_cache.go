@@ -0,0 +1,204 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// dependencyOutputCacheEntry is the content of a file of the on-disk dependency output cache. Each dependency config
+// has its own file, and the outputs are only reused if they were read from the same remote state.
+type dependencyOutputCacheEntry struct {
+	ConfigPath  string    `json:"config_path"`
+	RemoteState string    `json:"remote_state"`
+	CreatedAt   time.Time `json:"created_at"`
+	Outputs     string    `json:"outputs"`
+}
+
+// getOutputJsonWithDiskCache returns the outputs of the target config from the on-disk cache configured with
+// --terragrunt-dependency-output-cache-dir. On a cache miss, the outputs are retrieved with getTerragruntOutputJson and
+// stored in the cache. The cache file of the target config is locked while this happens, so that concurrent terragrunt
+// processes don't retrieve the same outputs more than once.
+func getOutputJsonWithDiskCache(ctx *ParsingContext, targetConfig string) ([]byte, error) {
+	terragruntOptions := ctx.TerragruntOptions
+	if terragruntOptions.DependencyOutputCacheDir == "" {
+		return getTerragruntOutputJson(ctx, targetConfig)
+	}
+
+	remoteState, err := remoteStateIdentity(ctx, targetConfig)
+	if err != nil {
+		terragruntOptions.Logger.Debugf("Not caching the outputs of %s on disk, because its remote state could not be parsed: %v", targetConfig, err)
+		return getTerragruntOutputJson(ctx, targetConfig)
+	}
+
+	cachePath := dependencyOutputCachePath(terragruntOptions, targetConfig)
+	if err := util.EnsureDirectory(filepath.Dir(cachePath)); err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockFile(cachePath + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if outputs, isCached := readDependencyOutputCache(terragruntOptions, cachePath, targetConfig, remoteState); isCached {
+		terragruntOptions.Logger.Debugf("Using the outputs of %s cached in %s.", targetConfig, cachePath)
+		return outputs, nil
+	}
+
+	outputs, err := getTerragruntOutputJson(ctx, targetConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := dependencyOutputCacheEntry{
+		ConfigPath:  targetConfig,
+		RemoteState: remoteState,
+		CreatedAt:   time.Now().UTC(),
+		Outputs:     string(outputs),
+	}
+	if err := writeDependencyOutputCache(cachePath, entry); err != nil {
+		// The outputs were retrieved, so failing to cache them shouldn't fail the command.
+		terragruntOptions.Logger.Warnf("Failed to cache the outputs of %s in %s: %v", targetConfig, cachePath, err)
+	}
+
+	return outputs, nil
+}
+
+// readDependencyOutputCache returns the cached outputs of the target config, if they were read from the given remote
+// state and have not expired.
+func readDependencyOutputCache(terragruntOptions *options.TerragruntOptions, cachePath string, targetConfig string, remoteState string) ([]byte, bool) {
+	if terragruntOptions.InvalidateOutputCache || !util.FileExists(cachePath) {
+		return nil, false
+	}
+
+	contents, err := os.ReadFile(cachePath)
+	if err != nil {
+		terragruntOptions.Logger.Debugf("Failed to read the dependency output cache %s: %v", cachePath, err)
+		return nil, false
+	}
+
+	entry := dependencyOutputCacheEntry{}
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		terragruntOptions.Logger.Debugf("Ignoring the corrupt dependency output cache %s: %v", cachePath, err)
+		return nil, false
+	}
+
+	ttl := time.Duration(terragruntOptions.DependencyOutputCacheTTLSec) * time.Second
+	switch {
+	case entry.ConfigPath != targetConfig:
+		return nil, false
+	case entry.RemoteState != remoteState:
+		terragruntOptions.Logger.Debugf("The outputs of %s cached in %s were read from another remote state.", targetConfig, cachePath)
+		return nil, false
+	case time.Since(entry.CreatedAt) > ttl:
+		terragruntOptions.Logger.Debugf("The outputs of %s cached in %s have expired.", targetConfig, cachePath)
+		return nil, false
+	}
+
+	return []byte(entry.Outputs), true
+}
+
+// writeDependencyOutputCache writes the cache entry to a temporary file first and then renames it, so that the cache
+// file is never partially written.
+func writeDependencyOutputCache(cachePath string, entry dependencyOutputCacheEntry) error {
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return writeCacheFile(cachePath, contents)
+}
+
+// writeCacheFile writes the given contents to a temporary file only readable by the current user, and then renames it
+// to the given cache file.
+func writeCacheFile(cachePath string, contents []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".tmp")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(contents); err != nil {
+		tmpFile.Close()
+		return errors.WithStackTrace(err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	return errors.WithStackTrace(os.Rename(tmpFile.Name(), cachePath))
+}
+
+// InvalidateOutputCache removes the cached outputs of the given config, both in memory and on disk. This is called
+// after a command that modifies the state of the module, so that the modules that depend on it read the new outputs.
+func InvalidateOutputCache(terragruntOptions *options.TerragruntOptions, configPath string) error {
+	configPath = filepath.Clean(configPath)
+	jsonOutputCache.Delete(configPath)
+
+	if terragruntOptions.DependencyOutputCacheDir == "" {
+		return nil
+	}
+
+	cachePath := dependencyOutputCachePath(terragruntOptions, configPath)
+	if !util.FileExists(cachePath) {
+		return nil
+	}
+
+	unlock, err := lockFile(cachePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+		return errors.WithStackTrace(err)
+	}
+
+	terragruntOptions.Logger.Debugf("Invalidated the outputs of %s cached in %s.", configPath, cachePath)
+	return nil
+}
+
+// dependencyOutputCachePath returns the path of the file the outputs of the given config are cached in.
+func dependencyOutputCachePath(terragruntOptions *options.TerragruntOptions, configPath string) string {
+	hash := sha256.Sum256([]byte(filepath.Clean(configPath)))
+
+	cacheDir := terragruntOptions.DependencyOutputCacheDir
+	if !filepath.IsAbs(cacheDir) {
+		cacheDir = util.JoinPath(terragruntOptions.WorkingDir, cacheDir)
+	}
+	return filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".json")
+}
+
+// remoteStateIdentity returns a hash of the remote state configuration of the target config, which identifies the
+// state the outputs are read from. Configs without a remote_state block have an empty identity.
+func remoteStateIdentity(ctx *ParsingContext, targetConfig string) (string, error) {
+	targetOptions := cloneTerragruntOptionsForDependency(ctx, targetConfig)
+
+	remoteStateTGConfig, err := PartialParseConfigFile(ctx.WithTerragruntOptions(targetOptions).WithDecodeList(RemoteStateBlock), targetConfig, nil)
+	if err != nil {
+		return "", err
+	}
+	if remoteStateTGConfig.RemoteState == nil {
+		return "", nil
+	}
+
+	identity, err := json.Marshal(map[string]interface{}{
+		"backend": remoteStateTGConfig.RemoteState.Backend,
+		"config":  remoteStateTGConfig.RemoteState.Config,
+	})
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	hash := sha256.Sum256(identity)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createOutputCacheTestDependency creates a dependency config with the given contents and returns a parsing context of
+// a module that reads its outputs, with the on-disk output cache enabled. The outputs are retrieved with a mock
+// terragrunt output command that counts how many times it is run.
+func createOutputCacheTestDependency(t *testing.T, contents string, runs *int) (*ParsingContext, string) {
+	rootDir := t.TempDir()
+	dependencyConfig := filepath.Join(rootDir, "vpc", DefaultTerragruntConfigPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(dependencyConfig), 0755))
+	require.NoError(t, os.WriteFile(dependencyConfig, []byte(contents), 0644))
+
+	opts := mockOptionsForTestWithConfigPath(t, filepath.Join(rootDir, "app", DefaultTerragruntConfigPath))
+	opts.DependencyOutputCacheDir = filepath.Join(rootDir, "cache")
+	opts.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		*runs++
+		_, err := opts.Writer.Write([]byte(`{"id": {"type": "string", "value": "vpc-1"}}`))
+		return err
+	}
+
+	return NewParsingContext(context.Background(), opts), dependencyConfig
+}
+
+func TestGetOutputJsonWithDiskCache(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	ctx, dependencyConfig := createOutputCacheTestDependency(t, ``, &runs)
+
+	outputs, err := getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": {"type": "string", "value": "vpc-1"}}`, string(outputs))
+
+	// The second process reads the outputs from the cache.
+	cachedOutputs, err := getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.Equal(t, outputs, cachedOutputs)
+	assert.Equal(t, 1, runs)
+
+	require.NoError(t, InvalidateOutputCache(ctx.TerragruntOptions, dependencyConfig))
+	_, err = getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+}
+
+func TestGetOutputJsonWithDiskCacheExpired(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	ctx, dependencyConfig := createOutputCacheTestDependency(t, ``, &runs)
+	ctx.TerragruntOptions.DependencyOutputCacheTTLSec = 0
+
+	for i := 0; i < 2; i++ {
+		_, err := getOutputJsonWithDiskCache(ctx, dependencyConfig)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, runs)
+}
+
+func TestGetOutputJsonWithDiskCacheInvalidateFlag(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	ctx, dependencyConfig := createOutputCacheTestDependency(t, ``, &runs)
+
+	_, err := getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+
+	ctx.TerragruntOptions.InvalidateOutputCache = true
+	_, err = getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+}
+
+func TestGetOutputJsonWithDiskCacheRemoteStateChanged(t *testing.T) {
+	t.Parallel()
+
+	remoteState := func(bucket string) string {
+		return `
+remote_state {
+  backend = "s3"
+  disable_dependency_optimization = true
+  config = {
+    bucket = "` + bucket + `"
+    key    = "vpc/terraform.tfstate"
+    region = "us-east-1"
+  }
+}
+`
+	}
+
+	runs := 0
+	ctx, dependencyConfig := createOutputCacheTestDependency(t, remoteState("bucket-a"), &runs)
+
+	_, err := getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	_, err = getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 1, runs)
+
+	require.NoError(t, os.WriteFile(dependencyConfig, []byte(remoteState("bucket-b")), 0644))
+	_, err = getOutputJsonWithDiskCache(ctx, dependencyConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// Supported values of the backoff attribute of the retry blocks.
+const (
+	BackoffConstant    = "constant"
+	BackoffExponential = "exponential"
+)
+
+// ErrorsConfig is the errors block, whose rules decide which errors of the terraform commands are retried, each with
+// its own policy, and which are ignored.
+// NOTE: If any attributes or blocks are added here, be sure to add it to the merge functions in include.go as well.
+type ErrorsConfig struct {
+	Retry  []RetryConfig  `hcl:"retry,block" cty:"retry"`
+	Ignore []IgnoreConfig `hcl:"ignore,block" cty:"ignore"`
+}
+
+// RetryConfig is a retry block, which retries the terraform commands failing with an error that matches one of the
+// regular expressions of On.
+type RetryConfig struct {
+	Name             string   `hcl:"name,label" cty:"name"`
+	On               []string `hcl:"on,attr" cty:"on"`
+	MaxAttempts      *int     `hcl:"max_attempts,optional" cty:"max_attempts"`
+	SleepIntervalSec *int     `hcl:"sleep_interval_sec,optional" cty:"sleep_interval_sec"`
+	Backoff          *string  `hcl:"backoff,optional" cty:"backoff"`
+}
+
+// IgnoreConfig is an ignore block, which ignores the errors of the terraform commands that match one of the regular
+// expressions of On, logging them as warnings along with Message.
+type IgnoreConfig struct {
+	Name    string   `hcl:"name,label" cty:"name"`
+	On      []string `hcl:"on,attr" cty:"on"`
+	Message *string  `hcl:"message,optional" cty:"message"`
+}
+
+func (conf *ErrorsConfig) String() string {
+	return fmt.Sprintf("ErrorsConfig{Retry = %v, Ignore = %v}", len(conf.Retry), len(conf.Ignore))
+}
+
+// Validate checks that the rules have unique names, valid regular expressions and valid retry policies.
+func (conf *ErrorsConfig) Validate() error {
+	if conf == nil {
+		return nil
+	}
+
+	retryNames := map[string]bool{}
+	for _, retry := range conf.Retry {
+		if retryNames[retry.Name] {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Detected multiple retry blocks with the same name: %s", retry.Name)))
+		}
+		retryNames[retry.Name] = true
+
+		if err := validateErrorPatterns("retry", retry.Name, retry.On); err != nil {
+			return err
+		}
+		if retry.MaxAttempts != nil && *retry.MaxAttempts < 1 {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Error with retry block %s. Cannot have less than 1 max attempt, but you specified %d.", retry.Name, *retry.MaxAttempts)))
+		}
+		if retry.SleepIntervalSec != nil && *retry.SleepIntervalSec < 0 {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Error with retry block %s. Cannot sleep for less than 0 seconds, but you specified %d.", retry.Name, *retry.SleepIntervalSec)))
+		}
+		if retry.Backoff != nil && *retry.Backoff != BackoffConstant && *retry.Backoff != BackoffExponential {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Error with retry block %s. Unsupported backoff %s, must be one of %s or %s.", retry.Name, *retry.Backoff, BackoffConstant, BackoffExponential)))
+		}
+	}
+
+	ignoreNames := map[string]bool{}
+	for _, ignore := range conf.Ignore {
+		if ignoreNames[ignore.Name] {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Detected multiple ignore blocks with the same name: %s", ignore.Name)))
+		}
+		ignoreNames[ignore.Name] = true
+
+		if err := validateErrorPatterns("ignore", ignore.Name, ignore.On); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateErrorPatterns(blockType string, name string, patterns []string) error {
+	if len(patterns) == 0 {
+		return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Error with %s block %s. Need at least one regular expression in 'on'.", blockType, name)))
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.WithStackTrace(InvalidArgError(fmt.Sprintf("Error with %s block %s. Invalid regular expression %q: %v", blockType, name, pattern, err)))
+		}
+	}
+	return nil
+}
+
+// Merge merges the rules of the source errors block into this one. The source rules replace the rules with the same
+// name, and the others are added after the existing rules.
+func (conf *ErrorsConfig) Merge(source *ErrorsConfig) {
+	if source == nil {
+		return
+	}
+
+	// The rules are copied, as the slices may be shared with the configs they were merged from.
+	conf.Retry = append([]RetryConfig{}, conf.Retry...)
+	conf.Ignore = append([]IgnoreConfig{}, conf.Ignore...)
+
+	for _, retry := range source.Retry {
+		if index := getIndexOfRetryWithName(conf.Retry, retry.Name); index >= 0 {
+			conf.Retry[index] = retry
+		} else {
+			conf.Retry = append(conf.Retry, retry)
+		}
+	}
+
+	for _, ignore := range source.Ignore {
+		if index := getIndexOfIgnoreWithName(conf.Ignore, ignore.Name); index >= 0 {
+			conf.Ignore[index] = ignore
+		} else {
+			conf.Ignore = append(conf.Ignore, ignore)
+		}
+	}
+}
+
+// GetRetryRules converts the retry blocks to options.RetryRule, using the given defaults for the attributes that are
+// not set.
+func (conf *ErrorsConfig) GetRetryRules(defaultMaxAttempts int, defaultSleepInterval time.Duration) []options.RetryRule {
+	if conf == nil {
+		return nil
+	}
+
+	rules := make([]options.RetryRule, 0, len(conf.Retry))
+	for _, retry := range conf.Retry {
+		rule := options.RetryRule{
+			Name:          retry.Name,
+			On:            retry.On,
+			MaxAttempts:   defaultMaxAttempts,
+			SleepInterval: defaultSleepInterval,
+		}
+		if retry.MaxAttempts != nil {
+			rule.MaxAttempts = *retry.MaxAttempts
+		}
+		if retry.SleepIntervalSec != nil {
+			rule.SleepInterval = time.Duration(*retry.SleepIntervalSec) * time.Second
+		}
+		if retry.Backoff != nil && *retry.Backoff == BackoffExponential {
+			rule.ExponentialBackoff = true
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GetIgnoreRules converts the ignore blocks to options.IgnoreRule.
+func (conf *ErrorsConfig) GetIgnoreRules() []options.IgnoreRule {
+	if conf == nil {
+		return nil
+	}
+
+	rules := make([]options.IgnoreRule, 0, len(conf.Ignore))
+	for _, ignore := range conf.Ignore {
+		rule := options.IgnoreRule{Name: ignore.Name, On: ignore.On}
+		if ignore.Message != nil {
+			rule.Message = *ignore.Message
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func getIndexOfRetryWithName(retries []RetryConfig, name string) int {
+	for i, retry := range retries {
+		if retry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func getIndexOfIgnoreWithName(ignores []IgnoreConfig, name string) int {
+	for i, ignore := range ignores {
+		if ignore.Name == name {
+			return i
+		}
+	}
+	return -1
+}
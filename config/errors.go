@@ -19,14 +19,10 @@ func (err IncludedConfigMissingPathError) Error() string {
 	return fmt.Sprintf("The include configuration in %s must specify a 'path' parameter", string(err))
 }
 
-type TooManyLevelsOfInheritanceError struct {
-	ConfigPath             string
-	FirstLevelIncludePath  string
-	SecondLevelIncludePath string
-}
+type IncludeCycleError []string
 
-func (err TooManyLevelsOfInheritanceError) Error() string {
-	return fmt.Sprintf("%s includes %s, which itself includes %s. Only one level of includes is allowed.", err.ConfigPath, err.FirstLevelIncludePath, err.SecondLevelIncludePath)
+func (err IncludeCycleError) Error() string {
+	return fmt.Sprintf("Found an include cycle between configs: %s", strings.Join([]string(err), " -> "))
 }
 
 type CouldNotResolveTerragruntConfigInFileError string
@@ -243,3 +239,91 @@ type DependencyCycle []string
 func (err DependencyCycle) Error() string {
 	return fmt.Sprintf("Found a dependency cycle between modules: %s", strings.Join([]string(err), " -> "))
 }
+
+type InvalidTimeout struct {
+	Value string
+	Err   error
+}
+
+func (err InvalidTimeout) Error() string {
+	return fmt.Sprintf("Invalid timeout %q, expected a duration such as \"45m\" or \"1h30m\": %v", err.Value, err.Err)
+}
+
+type FunctionNameConflictError string
+
+func (err FunctionNameConflictError) Error() string {
+	return fmt.Sprintf("The function %s defined in the config or served by a plugin conflicts with a built-in function of the same name. Rename the function.", string(err))
+}
+
+type PluginError struct {
+	Plugin string
+	Err    error
+}
+
+func (err PluginError) Error() string {
+	return fmt.Sprintf("Error running plugin %s: %v", err.Plugin, err.Err)
+}
+
+func (err PluginError) Unwrap() error {
+	return err.Err
+}
+
+type PluginFunctionError struct {
+	Plugin   string
+	Function string
+	Message  string
+}
+
+func (err PluginFunctionError) Error() string {
+	return fmt.Sprintf("Function %s of plugin %s failed: %s", err.Function, err.Plugin, err.Message)
+}
+
+type DuplicatePluginFunctionError struct {
+	Function string
+	Plugins  []string
+	Path     string
+}
+
+func (err DuplicatePluginFunctionError) Error() string {
+	return fmt.Sprintf("The function %s is served by several plugins declared in %s: %s. Remove one of the plugins.", err.Function, err.Path, strings.Join(err.Plugins, ", "))
+}
+
+type InvalidFeatureFlagValueError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (err InvalidFeatureFlagValueError) Error() string {
+	return fmt.Sprintf("Invalid value '%s' for feature flag %s: %v. The value must match the type of the default of the feature block.", err.Value, err.Name, err.Err)
+}
+
+type UnpinnedRemoteConfigError struct {
+	Source string
+}
+
+func (err UnpinnedRemoteConfigError) Error() string {
+	return fmt.Sprintf("The remote config %s is not pinned to an immutable version. Set its ref or rev query parameter to a full commit hash, its version to an exact version, or add a checksum.", err.Source)
+}
+
+type RemoteConfigFetchError struct {
+	Source string
+	Err    error
+}
+
+func (err RemoteConfigFetchError) Error() string {
+	return fmt.Sprintf("Error fetching the remote config %s: %v", err.Source, err.Err)
+}
+
+func (err RemoteConfigFetchError) Unwrap() error {
+	return err.Err
+}
+
+type RemoteIncludeOutsideRepoError struct {
+	Source string
+	Path   string
+}
+
+func (err RemoteIncludeOutsideRepoError) Error() string {
+	return fmt.Sprintf("The paths relative to the remote config %s included in %s are relative to the root of its git repository, but %s is not in a git repository.", err.Source, err.Path, err.Path)
+}
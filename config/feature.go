@@ -0,0 +1,102 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+)
+
+// FeatureFlag is a feature flag declared with a feature block. Its value is available as feature.<name>.value.
+type FeatureFlag struct {
+	Name    string    `hcl:"name,label"`
+	Default cty.Value `hcl:"default,attr"`
+}
+
+// terragruntFeatureFlags is a struct that can be used to only decode the feature blocks.
+type terragruntFeatureFlags struct {
+	FeatureFlags []FeatureFlag `hcl:"feature,block"`
+	Remain       hcl.Body      `hcl:",remain"`
+}
+
+// decodeFeatureFlags decodes the feature blocks of the given config and of the configs it includes, and returns the
+// value of each feature flag, as the object exposed under the feature variable. The value of a feature flag is, from
+// the highest precedence to the lowest:
+//   - the value set with --terragrunt-feature.
+//   - the value of the feature flag in the child config, if the config is parsed as an include of that child, so that
+//     a child can flip a feature flag used in the config it includes.
+//   - the default of the feature block of the config.
+//   - the default of the feature block of the configs included at every level, an included config taking precedence
+//     over the configs it includes, and the last include over the ones before it.
+//
+// The defaults can only call the built-in functions and the given functions of the config.
+func decodeFeatureFlags(ctx *ParsingContext, file *hclparse.File, includedFiles []*hclparse.File, includeFromChild *IncludeConfig, functions map[string]function.Function) (*cty.Value, error) {
+	featureFlagsCtx := *ctx
+	featureFlagsCtx.Locals = nil
+	featureFlagsCtx.DecodedDependencies = nil
+	featureFlagsCtx.TrackInclude = nil
+	featureFlagsCtx.FeatureFlags = nil
+	featureFlagsCtx.Functions = functions
+	evalCtx, err := createTerragruntEvalContext(&featureFlagsCtx, file.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]cty.Value{}
+	for _, featureFile := range append(includedFiles, file) {
+		decoded := terragruntFeatureFlags{}
+		if err := featureFile.Decode(&decoded, evalCtx); err != nil {
+			return nil, err
+		}
+		for _, featureFlag := range decoded.FeatureFlags {
+			values[featureFlag.Name] = featureFlag.Default
+		}
+	}
+
+	if includeFromChild != nil && ctx.FeatureFlags != nil {
+		for name, featureFlag := range ctx.FeatureFlags.AsValueMap() {
+			values[name] = featureFlag.GetAttr("value")
+		}
+	}
+
+	for name, rawValue := range ctx.TerragruntOptions.FeatureFlags {
+		defaultValue, declared := values[name]
+		if !declared {
+			continue
+		}
+		value, err := parseFeatureFlagValue(rawValue, defaultValue.Type())
+		if err != nil {
+			return nil, errors.WithStackTrace(InvalidFeatureFlagValueError{Name: name, Value: rawValue, Err: err})
+		}
+		values[name] = value
+	}
+
+	featureFlags := map[string]cty.Value{}
+	for name, value := range values {
+		featureFlags[name] = cty.ObjectVal(map[string]cty.Value{"value": value})
+	}
+	featureFlagsAsCtyVal := cty.ObjectVal(featureFlags)
+	return &featureFlagsAsCtyVal, nil
+}
+
+// parseFeatureFlagValue parses a value of a feature flag set on the command line into the type of its default. Strings
+// are taken as is, primitive values are converted from their string representation, e.g. true or 3, and other values
+// are parsed as JSON.
+func parseFeatureFlagValue(rawValue string, valueType cty.Type) (cty.Value, error) {
+	switch {
+	case valueType == cty.DynamicPseudoType || valueType == cty.String:
+		return cty.StringVal(rawValue), nil
+	case valueType.IsPrimitiveType():
+		return convert.Convert(cty.StringVal(rawValue), valueType)
+	}
+
+	var value ctyjson.SimpleJSONValue
+	if err := value.UnmarshalJSON([]byte(rawValue)); err != nil {
+		return cty.NilVal, err
+	}
+	return value.Value, nil
+}
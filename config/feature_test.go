@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+const featureTestRootConfig = `
+feature "new_backend" {
+  default = false
+}
+
+feature "replicas" {
+  default = 1
+}
+
+feature "zones" {
+  default = ["a"]
+}
+
+locals {
+  backend = feature.new_backend.value ? "new" : "legacy"
+}
+
+inputs = {
+  backend  = local.backend
+  replicas = feature.replicas.value
+  zones    = feature.zones.value
+}
+`
+
+const featureTestChildConfig = `
+include "root" {
+  path   = find_in_parent_folders("root.hcl")
+  expose = true
+}
+
+feature "replicas" {
+  default = 3
+}
+
+feature "tier" {
+  default = "web"
+}
+
+inputs = {
+  tier         = feature.tier.value
+  root_backend = include.root.locals.backend
+}
+`
+
+func TestParseConfigWithFeatureFlags(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, featureTestRootConfig, featureTestChildConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	// The child overrides the default of the replicas feature flag, including in the config it includes.
+	assert.Equal(t, "legacy", cfg.Inputs["backend"])
+	assert.Equal(t, "legacy", cfg.Inputs["root_backend"])
+	assert.Equal(t, float64(3), cfg.Inputs["replicas"])
+	assert.Equal(t, []interface{}{"a"}, cfg.Inputs["zones"])
+	assert.Equal(t, "web", cfg.Inputs["tier"])
+}
+
+func TestParseConfigWithFeatureFlagsFromCommandLine(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, featureTestRootConfig, featureTestChildConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+	opts.FeatureFlags = map[string]string{
+		"new_backend": "true",
+		"replicas":    "5",
+		"zones":       `["a", "b"]`,
+		"tier":        "api",
+		"undeclared":  "ignored",
+	}
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "new", cfg.Inputs["backend"])
+	assert.Equal(t, "new", cfg.Inputs["root_backend"])
+	assert.Equal(t, float64(5), cfg.Inputs["replicas"])
+	assert.Equal(t, []interface{}{"a", "b"}, cfg.Inputs["zones"])
+	assert.Equal(t, "api", cfg.Inputs["tier"])
+}
+
+func TestParseConfigWithFeatureFlagsPartial(t *testing.T) {
+	t.Parallel()
+
+	childConfig := featureTestChildConfig + `
+dependencies {
+  paths = feature.replicas.value > 1 ? ["../ha"] : []
+}
+`
+	childPath := writeFunctionsTestConfigs(t, featureTestRootConfig, childConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	ctx := NewParsingContext(context.Background(), opts).WithDecodeList(DependenciesBlock)
+	cfg, err := PartialParseConfigFile(ctx, childPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"../ha"}, cfg.Dependencies.Paths)
+}
+
+func TestParseConfigWithInvalidFeatureFlagValue(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, featureTestRootConfig, featureTestChildConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+	opts.FeatureFlags = map[string]string{"new_backend": "maybe"}
+
+	_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.Error(t, err)
+
+	var invalidValueErr InvalidFeatureFlagValueError
+	require.ErrorAs(t, errors.Unwrap(err), &invalidValueErr)
+	assert.Equal(t, "new_backend", invalidValueErr.Name)
+	assert.Equal(t, "maybe", invalidValueErr.Value)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+)
+
+// decodeFunctionBlocks decodes the functions of the given config, i.e. its function blocks and the functions served
+// by its plugins, along with the functions of the configs it includes, so that a child config can use the function
+// library of its parents at every level, and the functions of the plugins of the closest .terragruntrc file. The
+// functions of the config take precedence over the included ones, the functions of an included config over the ones of
+// the configs it includes, the functions of an include over the ones of the includes before it, and the included
+// functions over the ones of .terragruntrc.
+//
+// Functions only see their parameters: their result is evaluated with the built-in functions and the other functions
+// of the config they are defined in, but without the locals, the dependencies or the include variable.
+//
+// The functions of the plugins are added to ParsingContext.Functions along with the function blocks, rather than to
+// ParsingContext.PredefinedFunctions: the predefined functions override all the others, including the function blocks
+// that take precedence over the plugins, and the stubs of the static analysis, and they are shared by all the configs
+// parsed with the context, while the plugins of a config are only in scope in that config and its children.
+func decodeFunctionBlocks(ctx *ParsingContext, file *hclparse.File, includedFiles []*hclparse.File) (map[string]function.Function, error) {
+	inherited, err := findTerragruntRCPluginFunctions(ctx, file.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if inherited == nil {
+		inherited = map[string]function.Function{}
+	}
+
+	for _, includedFile := range includedFiles {
+		functions, err := decodeFileFunctions(ctx, includedFile, nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, fn := range functions {
+			inherited[name] = fn
+		}
+	}
+
+	return decodeFileFunctions(ctx, file, inherited)
+}
+
+// decodeFileFunctions decodes the functions of a single file, and returns them along with the given inherited
+// functions, which the functions of the file can call. The function blocks take precedence over the functions served
+// by the plugins of the file.
+func decodeFileFunctions(ctx *ParsingContext, file *hclparse.File, inherited map[string]function.Function) (map[string]function.Function, error) {
+	functionsCtx := *ctx
+	functionsCtx.Locals = nil
+	functionsCtx.DecodedDependencies = nil
+	functionsCtx.TrackInclude = nil
+	functionsCtx.FeatureFlags = nil
+	functionsCtx.Functions = nil
+
+	pluginFunctions, err := decodePluginFunctions(&functionsCtx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	// The evaluation context of the functions must contain the functions themselves, so that they can call each other.
+	// It is only used when a function is called, by which time it is built below.
+	var functionsEvalCtx *hcl.EvalContext
+	functions, err := file.Functions(MetadataFunction, func() *hcl.EvalContext {
+		return functionsEvalCtx
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, lowerPriority := range []map[string]function.Function{pluginFunctions, inherited} {
+		for name, fn := range lowerPriority {
+			if _, found := functions[name]; !found {
+				functions[name] = fn
+			}
+		}
+	}
+
+	functionsCtx.Functions = functions
+	functionsEvalCtx, err = createTerragruntEvalContext(&functionsCtx, file.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return functions, nil
+}
+
+// addConfigFunctions adds the functions defined in the config to the given functions, which must not redefine any of
+// them.
+func addConfigFunctions(functions map[string]function.Function, configFunctions map[string]function.Function) error {
+	for name, fn := range configFunctions {
+		if _, found := functions[name]; found {
+			return errors.WithStackTrace(FunctionNameConflictError(name))
+		}
+		functions[name] = fn
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+const functionsTestRootConfig = `
+function "resource_name" {
+  params = [env, name]
+  result = "${env}-${name}"
+}
+
+function "tags" {
+  params         = [env]
+  variadic_param = extra
+  result         = merge({ Environment = env, Name = resource_name(env, "tags") }, extra...)
+}
+
+locals {
+  root_name = resource_name("root", "vpc")
+}
+`
+
+const functionsTestChildConfig = `
+include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+function "subnet" {
+  params = [cidr, index]
+  result = cidrsubnet(cidr, 8, index)
+}
+
+locals {
+  env = "prod"
+}
+
+inputs = {
+  name   = resource_name(local.env, "app")
+  tags   = tags(local.env, { Team = "platform" })
+  subnet = subnet("10.0.0.0/16", 2)
+}
+`
+
+// writeFunctionsTestConfigs writes the given root config, and the given child config in the child directory next to
+// it, and returns the path of the child config.
+func writeFunctionsTestConfigs(t *testing.T, rootConfig string, childConfig string) string {
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"root.hcl":                             rootConfig,
+		"child/" + DefaultTerragruntConfigPath: childConfig,
+	})
+	return filepath.Join(rootDir, "child", DefaultTerragruntConfigPath)
+}
+
+func TestParseConfigWithFunctions(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, functionsTestRootConfig, functionsTestChildConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	// The child config calls the functions of its parent, and its own.
+	assert.Equal(t, "prod-app", cfg.Inputs["name"])
+	assert.Equal(t, map[string]interface{}{"Environment": "prod", "Name": "prod-tags", "Team": "platform"}, cfg.Inputs["tags"])
+	assert.Equal(t, "10.0.2.0/24", cfg.Inputs["subnet"])
+}
+
+func TestParseConfigWithFunctionsPartial(t *testing.T) {
+	t.Parallel()
+
+	childConfig := functionsTestChildConfig + `
+dependencies {
+  paths = [resource_name("../", local.env)]
+}
+`
+	childPath := writeFunctionsTestConfigs(t, functionsTestRootConfig, childConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	ctx := NewParsingContext(context.Background(), opts).WithDecodeList(DependenciesBlock)
+	cfg, err := PartialParseConfigFile(ctx, childPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"../-prod"}, cfg.Dependencies.Paths)
+}
+
+func TestParseConfigWithFunctionsOnlySeeTheirParameters(t *testing.T) {
+	t.Parallel()
+
+	rootConfig := `
+locals {
+  env = "root"
+}
+
+function "with_local" {
+  params = []
+  result = local.env
+}
+`
+	childConfig := `
+include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+inputs = {
+  env = with_local()
+}
+`
+	childPath := writeFunctionsTestConfigs(t, rootConfig, childConfig)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "with_local")
+}
+
+func TestParseConfigWithFunctionNameConflict(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, "", `
+function "upper" {
+  params = [value]
+  result = value
+}
+`)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.Error(t, err)
+	assert.Equal(t, FunctionNameConflictError("upper"), errors.Unwrap(err))
+}
+
+func TestParseConfigWithRecursiveFunctions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		functions string
+		expected  string
+	}{
+		{
+			name: "self",
+			functions: `
+function "f" {
+  params = [x]
+  result = f(x)
+}
+`,
+			expected: `the calls f -> f would never return`,
+		},
+		{
+			name: "mutual",
+			functions: `
+function "even" {
+  params = [n]
+  result = n == 0 ? true : odd(n - 1)
+}
+
+function "odd" {
+  params = [n]
+  result = n == 0 ? false : even(n - 1)
+}
+`,
+			expected: `the calls even -> odd -> even would never return`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			childPath := writeFunctionsTestConfigs(t, testCase.functions, `
+include {
+  path = find_in_parent_folders("root.hcl")
+}
+
+inputs = {
+  value = upper("x")
+}
+`)
+			opts := terragruntOptionsForTest(t, childPath)
+
+			_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Recursive function call")
+			assert.Contains(t, err.Error(), testCase.expected)
+		})
+	}
+}
@@ -2,12 +2,16 @@ package hclparse
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/gruntwork-io/terragrunt/util"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/userfunc"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 const (
@@ -107,6 +111,104 @@ func (file *File) Blocks(name string, isMultipleAllowed bool) ([]*Block, error)
 	return extractedBlocks, nil
 }
 
+// Functions decodes the `name` blocks of the file as user-defined functions, each with the `params`, optional
+// `variadic_param` and `result` attributes. The result of the functions is evaluated in the context returned by
+// contextFunc, extended with the parameters, when the functions are called.
+//
+// The functions can call each other, but not recursively: a function calling itself, directly or through other
+// functions, could only return by overflowing the stack, so the cycles of calls are reported as errors.
+func (file *File) Functions(name string, contextFunc userfunc.ContextFunc) (map[string]function.Function, error) {
+	functions, _, diags := userfunc.DecodeUserFunctions(file.Body, name, contextFunc)
+	if !diags.HasErrors() {
+		diags = append(diags, file.functionCallCycles(name)...)
+	}
+	if err := file.diagnosticsError(diags); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	return functions, nil
+}
+
+// functionCallCycles returns a diagnostic for every cycle of calls between the `name` blocks of the file, found by
+// walking the function calls of their `result` attribute. Only the native syntax is walked: the expressions of a JSON
+// config are parsed when they are evaluated.
+func (file *File) functionCallCycles(name string) hcl.Diagnostics {
+	content, _, _ := file.Body.PartialContent(&hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: name, LabelNames: []string{"name"}}}})
+
+	calls := map[string][]*hclsyntax.FunctionCallExpr{}
+	names := []string{}
+	defined := map[string]bool{}
+	for _, block := range content.Blocks {
+		functionName := block.Labels[0]
+		names = append(names, functionName)
+		defined[functionName] = true
+
+		content, _, _ := block.Body.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{{Name: "result"}}})
+		result, found := content.Attributes["result"]
+		if !found {
+			continue
+		}
+		expr, ok := result.Expr.(hclsyntax.Expression)
+		if !ok {
+			continue
+		}
+		hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			if call, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+				calls[functionName] = append(calls[functionName], call)
+			}
+			return nil
+		})
+	}
+
+	var diags hcl.Diagnostics
+
+	// Depth-first search of the calls, where a call to a function that is still on the stack closes a cycle.
+	visited := map[string]bool{}
+	stack := []string{}
+	onStack := map[string]bool{}
+	var visit func(functionName string)
+	visit = func(functionName string) {
+		visited[functionName] = true
+		onStack[functionName] = true
+		stack = append(stack, functionName)
+
+		for _, call := range calls[functionName] {
+			// The calls to the built-in and the inherited functions can't be part of a cycle.
+			if !defined[call.Name] {
+				continue
+			}
+			if onStack[call.Name] {
+				start := len(stack) - 1
+				for stack[start] != call.Name {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), call.Name)
+				rng := call.Range()
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Recursive function call",
+					Detail:   fmt.Sprintf("The function %q can not be called here, as the calls %s would never return.", call.Name, strings.Join(cycle, " -> ")),
+					Subject:  &rng,
+				})
+				continue
+			}
+			if !visited[call.Name] {
+				visit(call.Name)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		onStack[functionName] = false
+	}
+	for _, functionName := range names {
+		if !visited[functionName] {
+			visit(functionName)
+		}
+	}
+
+	return diags
+}
+
 func (file *File) JustAttributes() (Attributes, error) {
 	hclAttrs, diags := file.Body.JustAttributes()
 
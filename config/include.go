@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"strings"
+	"slices"
 
 	"github.com/gruntwork-io/terragrunt/codegen"
 	"github.com/gruntwork-io/terragrunt/config/hclparse"
@@ -290,6 +290,17 @@ func (targetConfig *TerragruntConfig) Merge(sourceConfig *TerragruntConfig, terr
 		targetConfig.GenerateConfigs[key] = val
 	}
 
+	if sourceConfig.Labels != nil {
+		targetConfig.Labels = mergeLabels(sourceConfig.Labels, targetConfig.Labels)
+	}
+
+	if sourceConfig.Timeout != nil {
+		targetConfig.Timeout = sourceConfig.Timeout
+	}
+
+	// The rules of the errors block are merged by name, the rules of the child taking precedence.
+	targetConfig.Errors = mergeErrorsConfig(targetConfig.Errors, sourceConfig.Errors)
+
 	if sourceConfig.Inputs != nil {
 		targetConfig.Inputs = mergeInputs(sourceConfig.Inputs, targetConfig.Inputs)
 	}
@@ -426,6 +437,17 @@ func (targetConfig *TerragruntConfig) DeepMerge(sourceConfig *TerragruntConfig,
 		}
 	}
 
+	if sourceConfig.Labels != nil {
+		targetConfig.Labels = mergeLabels(sourceConfig.Labels, targetConfig.Labels)
+	}
+
+	if sourceConfig.Timeout != nil {
+		targetConfig.Timeout = sourceConfig.Timeout
+	}
+
+	// The rules of the errors block are merged by name, the rules of the child taking precedence.
+	targetConfig.Errors = mergeErrorsConfig(targetConfig.Errors, sourceConfig.Errors)
+
 	if sourceConfig.Inputs != nil {
 		mergedInputs, err := deepMergeInputs(sourceConfig.Inputs, targetConfig.Inputs)
 		if err != nil {
@@ -567,6 +589,34 @@ func mergeInputs(childInputs map[string]interface{}, parentInputs map[string]int
 	return out
 }
 
+// mergeErrorsConfig merges the rules of the child errors block into the rules of the parent one, without modifying
+// either of them.
+func mergeErrorsConfig(parentErrors *ErrorsConfig, childErrors *ErrorsConfig) *ErrorsConfig {
+	if childErrors == nil {
+		return parentErrors
+	}
+
+	merged := &ErrorsConfig{}
+	merged.Merge(parentErrors)
+	merged.Merge(childErrors)
+	return merged
+}
+
+// mergeLabels merges the child labels into the parent labels, the child label values take precedence.
+func mergeLabels(childLabels map[string]string, parentLabels map[string]string) map[string]string {
+	out := map[string]string{}
+
+	for key, value := range parentLabels {
+		out[key] = value
+	}
+
+	for key, value := range childLabels {
+		out[key] = value
+	}
+
+	return out
+}
+
 func deepMergeInputs(childInputs map[string]interface{}, parentInputs map[string]interface{}) (map[string]interface{}, error) {
 	out := map[string]interface{}{}
 	for key, value := range parentInputs {
@@ -625,43 +675,103 @@ func mergeErrorHooks(terragruntOptions *options.TerragruntOptions, childHooks []
 
 // getTrackInclude converts the terragrunt include blocks into TrackInclude structs that differentiate between an
 // included config in the current parsing ctx, and an included config that was passed through from a previous
-// parsing ctx.
-func getTrackInclude(ctx *ParsingContext, terragruntIncludeList []IncludeConfig, includeFromChild *IncludeConfig) (*TrackInclude, error) {
-	includedPaths := []string{}
+// parsing ctx. A config parsed as an include of a child config can have include blocks itself, in which case both are
+// tracked.
+func getTrackInclude(terragruntIncludeList []IncludeConfig, includeFromChild *IncludeConfig) *TrackInclude {
 	terragruntIncludeMap := make(map[string]IncludeConfig, len(terragruntIncludeList))
 	for _, tgInc := range terragruntIncludeList {
-		includedPaths = append(includedPaths, tgInc.Path)
 		terragruntIncludeMap[tgInc.Name] = tgInc
 	}
 
-	hasInclude := len(terragruntIncludeList) > 0
-	var trackInc TrackInclude
-	switch {
-	case hasInclude && includeFromChild != nil:
-		// tgInc appears in a parent that is already included, which means a nested include block. This is not
-		// something we currently support.
-		err := errors.WithStackTrace(TooManyLevelsOfInheritanceError{
-			ConfigPath:             ctx.TerragruntOptions.TerragruntConfigPath,
-			FirstLevelIncludePath:  includeFromChild.Path,
-			SecondLevelIncludePath: strings.Join(includedPaths, ","),
-		})
-		return nil, err
-	case hasInclude && includeFromChild == nil:
-		// Current parsing ctx where there is no included config already loaded.
-		trackInc = TrackInclude{
-			CurrentList: terragruntIncludeList,
-			CurrentMap:  terragruntIncludeMap,
-			Original:    nil,
-		}
-	case !hasInclude:
-		// Parsing ctx where there is an included config already loaded.
-		trackInc = TrackInclude{
-			CurrentList: terragruntIncludeList,
-			CurrentMap:  terragruntIncludeMap,
-			Original:    includeFromChild,
-		}
-	}
-	return &trackInc, nil
+	return &TrackInclude{
+		CurrentList: terragruntIncludeList,
+		CurrentMap:  terragruntIncludeMap,
+		Original:    includeFromChild,
+	}
+}
+
+// parseIncludedFiles parses the configs included by the given config at every level, for the base blocks that a config
+// inherits from the configs it includes. The configs are returned from the lowest precedence to the highest: each
+// included config comes after the configs it includes itself, and the includes are in order. The includes without a
+// path, or whose config does not exist, are skipped: they are reported when the includes are parsed. An include cycle
+// is reported with the chain of includes that leads to it.
+//
+// The hierarchy is parsed once, with the top level config: the configs included at every level are returned along with
+// the configs they include themselves, by path, which the included configs are given in ctx.IncludedFiles when they
+// are parsed in turn.
+func parseIncludedFiles(ctx *ParsingContext, file *hclparse.File, trackInclude *TrackInclude, includeFromChild *IncludeConfig) ([]*hclparse.File, map[string][]*hclparse.File, error) {
+	configPath := canonicalIncludePath(file.ConfigPath)
+	if includeFromChild != nil {
+		if files, found := ctx.IncludedFiles[configPath]; found {
+			return files, ctx.IncludedFiles, nil
+		}
+	}
+
+	hierarchy := map[string][]*hclparse.File{}
+	if trackInclude != nil {
+		files, err := parseIncludeHierarchy(ctx, trackInclude.CurrentList, []string{configPath}, hierarchy)
+		if err != nil {
+			return nil, nil, err
+		}
+		hierarchy[configPath] = files
+	}
+	return hierarchy[configPath], hierarchy, nil
+}
+
+// parseIncludeHierarchy parses the configs of the given include blocks, and the configs they include, recursively,
+// adding the configs included by each of them to the given hierarchy. includeChain is the list of configs that lead
+// to the include blocks, from the child config.
+func parseIncludeHierarchy(ctx *ParsingContext, includes []IncludeConfig, includeChain []string, hierarchy map[string][]*hclparse.File) ([]*hclparse.File, error) {
+	files := []*hclparse.File{}
+	for _, include := range includes {
+		includePath := include.Path
+		if includePath == "" {
+			continue
+		}
+		if !filepath.IsAbs(includePath) {
+			includePath = util.JoinPath(filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath), includePath)
+		}
+		includePath = canonicalIncludePath(includePath)
+
+		if slices.Contains(includeChain, includePath) {
+			return nil, errors.WithStackTrace(IncludeCycleError(append(slices.Clone(includeChain), includePath)))
+		}
+
+		ctx.ReadRecorder.recordFile(includePath)
+		if !util.FileExists(includePath) {
+			continue
+		}
+
+		file, err := hclparse.NewParser().WithOptions(ctx.ParserOptions...).ParseFromFile(includePath)
+		if err != nil {
+			return nil, err
+		}
+
+		include := include
+		nestedIncludes, err := decodeIncludeBlocks(ctx, file, &include)
+		if err != nil {
+			return nil, err
+		}
+		nestedFiles, err := parseIncludeHierarchy(ctx, nestedIncludes, append(slices.Clone(includeChain), includePath), hierarchy)
+		if err != nil {
+			return nil, err
+		}
+		hierarchy[includePath] = nestedFiles
+
+		files = append(files, nestedFiles...)
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// canonicalIncludePath returns the absolute and clean version of the given config path, so that the configs of an
+// include chain can be compared.
+func canonicalIncludePath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return absPath
 }
 
 // updateBareIncludeBlock searches the parsed terragrunt contents for a bare include block (include without a label),
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
 	"github.com/gruntwork-io/terragrunt/remote"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -126,6 +132,11 @@ func TestMergeConfigIntoIncludedConfig(t *testing.T) {
 			&TerragruntConfig{IamRole: "role1"},
 			&TerragruntConfig{IamRole: "role2"},
 		},
+		{
+			&TerragruntConfig{Errors: &ErrorsConfig{Retry: []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(5)}}, Ignore: []IgnoreConfig{{Name: "childIgnore"}}}},
+			&TerragruntConfig{Errors: &ErrorsConfig{Retry: []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(2)}, {Name: "parentRetry"}}}},
+			&TerragruntConfig{Errors: &ErrorsConfig{Retry: []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(5)}, {Name: "parentRetry"}}, Ignore: []IgnoreConfig{{Name: "childIgnore"}}}},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -140,6 +151,23 @@ func TestMergeConfigIntoIncludedConfig(t *testing.T) {
 	}
 }
 
+func TestMergeErrorsConfigDoesNotModifySharedRules(t *testing.T) {
+	t.Parallel()
+
+	// The configs share the rules of a parent, with room to append to them.
+	parentRetry := make([]RetryConfig, 1, 4)
+	parentRetry[0] = RetryConfig{Name: "throttling", MaxAttempts: ptrInt(5)}
+	first := &ErrorsConfig{Retry: parentRetry}
+	second := &ErrorsConfig{Retry: parentRetry}
+
+	first.Merge(&ErrorsConfig{Retry: []RetryConfig{{Name: "first"}}, Ignore: []IgnoreConfig{{Name: "first"}}})
+	second.Merge(&ErrorsConfig{Retry: []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(2)}, {Name: "second"}}})
+
+	assert.Equal(t, []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(5)}, {Name: "first"}}, first.Retry)
+	assert.Equal(t, []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(2)}, {Name: "second"}}, second.Retry)
+	assert.Equal(t, []RetryConfig{{Name: "throttling", MaxAttempts: ptrInt(5)}}, parentRetry)
+}
+
 func TestDeepMergeConfigIntoIncludedConfig(t *testing.T) {
 	t.Parallel()
 
@@ -286,3 +314,194 @@ func TestDeepMergeConfigIntoIncludedConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseConfigWithNestedIncludes(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"org.hcl": `
+function "resource_name" {
+  params = [name]
+  result = "acme-${name}"
+}
+
+remote_state {
+  backend = "s3"
+  config = {
+    bucket = "acme-state"
+    key    = "${path_relative_to_include()}/terraform.tfstate"
+  }
+}
+
+inputs = {
+  org   = "acme"
+  level = "org"
+  tags  = { org = "acme" }
+}
+`,
+		"dev/account.hcl": `
+include "org" {
+  path           = find_in_parent_folders("org.hcl")
+  expose         = true
+  merge_strategy = "deep"
+}
+
+inputs = {
+  level    = "account"
+  org_name = include.org.inputs.org
+  tags     = { account = "dev" }
+}
+`,
+		"dev/us-east-1/app/terragrunt.hcl": `
+include "account" {
+  path   = find_in_parent_folders("account.hcl")
+  expose = true
+}
+
+inputs = {
+  level         = "app"
+  name          = resource_name("app")
+  account_level = include.account.inputs.level
+}
+`,
+	})
+	childPath := filepath.Join(rootDir, "dev", "us-east-1", "app", DefaultTerragruntConfigPath)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	// Each config overrides the configs it includes, with the merge strategy of its include block.
+	assert.Equal(t, "app", cfg.Inputs["level"])
+	assert.Equal(t, "acme", cfg.Inputs["org"])
+	assert.Equal(t, map[string]interface{}{"org": "acme", "account": "dev"}, cfg.Inputs["tags"])
+
+	// The include variable and the functions are available at every level.
+	assert.Equal(t, "acme", cfg.Inputs["org_name"])
+	assert.Equal(t, "account", cfg.Inputs["account_level"])
+	assert.Equal(t, "acme-app", cfg.Inputs["name"])
+
+	// The include functions of the top config are relative to it.
+	require.NotNil(t, cfg.RemoteState)
+	assert.Equal(t, "dev/us-east-1/app/terraform.tfstate", cfg.RemoteState.Config["key"])
+}
+
+func TestDecodeBaseBlocksParsesIncludeHierarchyOnce(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"org.hcl": "",
+		"account.hcl": `
+include "org" {
+  path = "org.hcl"
+}
+`,
+		"region.hcl": `
+include "account" {
+  path = "account.hcl"
+}
+`,
+		"app/terragrunt.hcl": `
+include "region" {
+  path = find_in_parent_folders("region.hcl")
+}
+`,
+	})
+	childPath := filepath.Join(rootDir, "app", DefaultTerragruntConfigPath)
+	regionPath := filepath.Join(rootDir, "region.hcl")
+	opts := terragruntOptionsForTest(t, childPath)
+	ctx := NewParsingContext(context.Background(), opts)
+
+	childFile, err := hclparse.NewParser().ParseFromFile(childPath)
+	require.NoError(t, err)
+	baseBlocks, err := DecodeBaseBlocks(ctx, childFile, nil)
+	require.NoError(t, err)
+
+	// The configs included at every level are parsed with the child config.
+	hierarchy := baseBlocks.IncludedFiles
+	require.Len(t, hierarchy[childPath], 3)
+	require.Len(t, hierarchy[regionPath], 2)
+	require.Len(t, hierarchy[filepath.Join(rootDir, "account.hcl")], 1)
+	require.Empty(t, hierarchy[filepath.Join(rootDir, "org.hcl")])
+	assert.Same(t, hierarchy[childPath][0], hierarchy[regionPath][0])
+
+	// The included configs reuse the hierarchy when they are parsed in turn, rather than parsing it again.
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "account.hcl"), []byte("include {"), 0644))
+	regionFile, err := hclparse.NewParser().ParseFromFile(regionPath)
+	require.NoError(t, err)
+	include := &IncludeConfig{Name: "region", Path: regionPath}
+
+	regionBaseBlocks, err := DecodeBaseBlocks(ctx.WithIncludedFiles(hierarchy), regionFile, include)
+	require.NoError(t, err)
+	assert.Equal(t, hierarchy, regionBaseBlocks.IncludedFiles)
+
+	_, err = DecodeBaseBlocks(ctx, regionFile, include)
+	require.Error(t, err)
+}
+
+func TestParseConfigWithNestedIncludesNoMerge(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"org.hcl": `
+inputs = {
+  org = "acme"
+}
+`,
+		"account.hcl": `
+include "org" {
+  path           = "org.hcl"
+  expose         = true
+  merge_strategy = "no_merge"
+}
+
+inputs = {
+  org_name = include.org.inputs.org
+}
+`,
+		"app/terragrunt.hcl": `
+include "account" {
+  path = find_in_parent_folders("account.hcl")
+}
+`,
+	})
+	childPath := filepath.Join(rootDir, "app", DefaultTerragruntConfigPath)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	// The relative include path is relative to the config declaring it.
+	assert.Equal(t, map[string]interface{}{"org_name": "acme"}, cfg.Inputs)
+}
+
+func TestParseConfigWithIncludeCycle(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"a.hcl": `
+include "b" {
+  path = "b.hcl"
+}
+`,
+		"b.hcl": `
+include "a" {
+  path = "a.hcl"
+}
+`,
+		"app/terragrunt.hcl": `
+include "a" {
+  path = find_in_parent_folders("a.hcl")
+}
+`,
+	})
+	childPath := filepath.Join(rootDir, "app", DefaultTerragruntConfigPath)
+	opts := terragruntOptionsForTest(t, childPath)
+
+	_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.Error(t, err)
+
+	var cycleErr IncludeCycleError
+	require.ErrorAs(t, errors.Unwrap(err), &cycleErr)
+	assert.Equal(t, IncludeCycleError{childPath, filepath.Join(rootDir, "a.hcl"), filepath.Join(rootDir, "b.hcl"), filepath.Join(rootDir, "a.hcl")}, cycleErr)
+}
@@ -0,0 +1,685 @@
+package config
+
+import (
+	goerrors "errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/terraform"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// The rules checked by the linter.
+const (
+	LintRuleInvalidConfig         = "invalid-config"
+	LintRuleUndefinedReference    = "undefined-reference"
+	LintRuleUnusedLocal           = "unused-local"
+	LintRuleUnusedDependency      = "unused-dependency"
+	LintRuleUnknownMockOutput     = "unknown-mock-output"
+	LintRuleDeprecatedAttribute   = "deprecated-attribute"
+	LintRuleGeneratePathCollision = "generate-path-collision"
+)
+
+type LintSeverity string
+
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+)
+
+// LintRule describes a rule checked by the linter.
+type LintRule struct {
+	ID          string
+	Severity    LintSeverity
+	Description string
+}
+
+var LintRules = []LintRule{
+	{LintRuleInvalidConfig, LintSeverityError, "The config can not be parsed, or its include blocks can not be evaluated."},
+	{LintRuleUndefinedReference, LintSeverityError, "A local, dependency or include that is referenced is not defined."},
+	{LintRuleUnusedLocal, LintSeverityWarning, "A local is never referenced."},
+	{LintRuleUnusedDependency, LintSeverityWarning, "The outputs of a dependency are never referenced."},
+	{LintRuleUnknownMockOutput, LintSeverityWarning, "A mock output is not an output of the module of the dependency."},
+	{LintRuleDeprecatedAttribute, LintSeverityWarning, "A deprecated attribute is set."},
+	{LintRuleGeneratePathCollision, LintSeverityError, "Several generate blocks write to the same path."},
+}
+
+// lintDeprecatedAttributes maps the deprecated attributes of each block type to the attribute replacing them.
+var lintDeprecatedAttributes = map[string]map[string]string{
+	MetadataDependency: {
+		"mock_outputs_merge_with_state": "mock_outputs_merge_strategy_with_state",
+	},
+}
+
+// LintDiagnostic is an issue found by the linter in a config.
+type LintDiagnostic struct {
+	Rule     string
+	Severity LintSeverity
+	Message  string
+	Range    hcl.Range
+}
+
+// linter checks the configs of units, along with the configs they include. The configs included by several units are
+// checked once, taking into account how all of those units use them, e.g. to find the locals they never reference.
+type linter struct {
+	ctx         *ParsingContext
+	files       map[string]*lintFile
+	diagnostics map[string]LintDiagnostic
+}
+
+// lintFile is a config checked by the linter, with what it defines and references.
+type lintFile struct {
+	file *hclparse.File
+	// body is nil for the configs that could not be parsed and the JSON configs, which are not checked.
+	body *hclsyntax.Body
+	// remote is true for the configs fetched from a remote source, whose issues are not reported.
+	remote bool
+	// mergedInUnits is true if the blocks of the config are merged in at least one unit.
+	mergedInUnits bool
+
+	locals       map[string]*hclsyntax.Attribute
+	dependencies map[string]*hclsyntax.Block
+	traversals   []hcl.Traversal
+
+	usedLocals       map[string]bool
+	allLocalsUsed    bool
+	usedDependencies map[string]bool
+}
+
+// lintUnitFile is a config of a unit, i.e. the config of the unit itself or one of the configs it includes, along with
+// how it is evaluated in that unit.
+type lintUnitFile struct {
+	*lintFile
+	path string
+	// includes are the include blocks of the config by label, and includePaths the canonical paths of their configs.
+	includes     map[string]IncludeConfig
+	includePaths map[string]string
+	// evalCtx is nil if the base blocks of the config could not be evaluated.
+	evalCtx *hcl.EvalContext
+	// merged is false if the config is included with the no_merge strategy, at any level.
+	merged bool
+}
+
+// lintGeneratedFile is a file generated by a generate block, or the generate attribute of a remote_state block.
+type lintGeneratedFile struct {
+	description string
+	path        string
+	unitFile    *lintUnitFile
+	rng         hcl.Range
+}
+
+// LintConfigs statically checks the given unit configs and the configs they include, without running terraform, the
+// commands and plugins they call, or the AWS calls, and returns the issues found, sorted by file and position. The
+// configs that can not be parsed are reported as issues as well.
+func LintConfigs(ctx *ParsingContext, configPaths []string) []LintDiagnostic {
+	l := &linter{
+		ctx:         ctx.WithStaticAnalysis(),
+		files:       map[string]*lintFile{},
+		diagnostics: map[string]LintDiagnostic{},
+	}
+
+	for _, configPath := range configPaths {
+		l.lintUnit(canonicalIncludePath(configPath))
+	}
+	l.lintFiles()
+
+	diagnostics := []LintDiagnostic{}
+	for _, diagnostic := range l.diagnostics {
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	sort.Slice(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.Range.Filename != b.Range.Filename {
+			return a.Range.Filename < b.Range.Filename
+		}
+		if a.Range.Start.Byte != b.Range.Start.Byte {
+			return a.Range.Start.Byte < b.Range.Start.Byte
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+	return diagnostics
+}
+
+// report records an issue of the given rule. The issues of the configs fetched from a remote source are ignored, and
+// the ones found again while checking another unit are recorded once.
+func (l *linter) report(lf *lintFile, rule string, rng hcl.Range, message string) {
+	if lf != nil && lf.remote {
+		return
+	}
+
+	severity := LintSeverityWarning
+	for _, lintRule := range LintRules {
+		if lintRule.ID == rule {
+			severity = lintRule.Severity
+		}
+	}
+
+	key := fmt.Sprintf("%s|%s|%d|%d|%s", rule, rng.Filename, rng.Start.Byte, rng.End.Byte, message)
+	l.diagnostics[key] = LintDiagnostic{Rule: rule, Severity: severity, Message: message, Range: rng}
+}
+
+// reportError records the given parsing error as an invalid-config issue, with the position of each of its HCL
+// diagnostics if it has any, or else at the start of the given config.
+func (l *linter) reportError(lf *lintFile, configPath string, err error) {
+	var diags hcl.Diagnostics
+	if goerrors.As(err, &diags) {
+		for _, diag := range diags {
+			if diag.Severity != hcl.DiagError {
+				continue
+			}
+			rng := lintFileStartRange(configPath)
+			if diag.Subject != nil {
+				rng = *diag.Subject
+			}
+			message := diag.Summary
+			if diag.Detail != "" {
+				message += ": " + diag.Detail
+			}
+			l.report(lf, LintRuleInvalidConfig, rng, message)
+		}
+		return
+	}
+
+	l.report(lf, LintRuleInvalidConfig, lintFileStartRange(configPath), err.Error())
+}
+
+// loadFile parses the config at the given canonical path, once, and collects what it defines and references.
+func (l *linter) loadFile(configPath string, remote bool) *lintFile {
+	if lf, found := l.files[configPath]; found {
+		return lf
+	}
+
+	lf := &lintFile{
+		remote:           remote,
+		locals:           map[string]*hclsyntax.Attribute{},
+		dependencies:     map[string]*hclsyntax.Block{},
+		usedLocals:       map[string]bool{},
+		usedDependencies: map[string]bool{},
+	}
+	l.files[configPath] = lf
+
+	file, err := hclparse.NewParser().WithOptions(l.ctx.ParserOptions...).ParseFromFile(configPath)
+	if err != nil {
+		l.reportError(lf, configPath, err)
+		return lf
+	}
+	lf.file = file
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		// JSON configs are not checked.
+		return lf
+	}
+	lf.body = body
+
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == MetadataLocals:
+			for name, attr := range block.Body.Attributes {
+				lf.locals[name] = attr
+			}
+		case block.Type == MetadataDependency && len(block.Labels) == 1:
+			lf.dependencies[block.Labels[0]] = block
+		}
+	}
+
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok {
+			lf.traversals = append(lf.traversals, expr.Traversal)
+		}
+		return nil
+	})
+
+	return lf
+}
+
+// lintUnit checks the config of a unit, along with the configs it includes.
+func (l *linter) lintUnit(configPath string) {
+	ctx := l.ctx.WithTerragruntOptions(l.ctx.TerragruntOptions.Clone(configPath))
+
+	unitFiles := l.collectUnitFiles(ctx, configPath, nil, false, []string{configPath})
+	if len(unitFiles) == 0 {
+		return
+	}
+
+	l.checkReferences(configPath, unitFiles)
+	l.checkGeneratedFiles(unitFiles)
+	l.checkMockOutputs(ctx, configPath, unitFiles)
+}
+
+// collectUnitFiles returns the given config of a unit and the configs it includes at every level, the included
+// configs first. includeChain is the list of configs that lead to the given one, from the config of the unit.
+func (l *linter) collectUnitFiles(ctx *ParsingContext, configPath string, includeFromChild *IncludeConfig, remote bool, includeChain []string) []*lintUnitFile {
+	lf := l.loadFile(configPath, remote)
+	if lf.file == nil {
+		return nil
+	}
+
+	unitFile := &lintUnitFile{
+		lintFile:     lf,
+		path:         configPath,
+		includes:     map[string]IncludeConfig{},
+		includePaths: map[string]string{},
+		merged:       true,
+	}
+
+	// The includes are still checked when the locals fail to evaluate, e.g. because of the issues reported by the linter.
+	baseBlocks, evalCtx, err := AnalyzeBaseBlocks(ctx, lf.file, includeFromChild)
+	if baseBlocks == nil {
+		l.reportError(lf, configPath, err)
+		return []*lintUnitFile{unitFile}
+	}
+	if err != nil {
+		ctx.TerragruntOptions.Logger.Debugf("Could not evaluate the base blocks of %s, ignoring them: %v", configPath, err)
+	}
+	unitFile.evalCtx = evalCtx
+
+	unitFiles := []*lintUnitFile{}
+	for _, include := range baseBlocks.TrackInclude.CurrentList {
+		includePath := include.Path
+		if !filepath.IsAbs(includePath) {
+			includePath = util.JoinPath(filepath.Dir(configPath), includePath)
+		}
+		includePath = canonicalIncludePath(includePath)
+
+		unitFile.includes[include.Name] = include
+		unitFile.includePaths[include.Name] = includePath
+
+		if util.ListContainsElement(includeChain, includePath) {
+			l.reportError(lf, configPath, IncludeCycleError(append(includeChain, includePath)))
+			continue
+		}
+
+		include := include
+		includedFiles := l.collectUnitFiles(ctx, includePath, &include, remote || include.RemoteSource != "", append(includeChain, includePath))
+		if mergeStrategy, err := include.GetMergeStrategy(); err == nil && mergeStrategy == NoMerge {
+			for _, includedFile := range includedFiles {
+				includedFile.merged = false
+			}
+		}
+		unitFiles = append(unitFiles, includedFiles...)
+	}
+
+	return append(unitFiles, unitFile)
+}
+
+// checkReferences checks that the locals, dependencies and includes referenced by the configs of a unit are defined,
+// and records the ones that are used.
+func (l *linter) checkReferences(unitPath string, unitFiles []*lintUnitFile) {
+	unitDependencies := map[string]bool{}
+	for _, unitFile := range unitFiles {
+		if !unitFile.merged {
+			continue
+		}
+		unitFile.mergedInUnits = true
+		for name := range unitFile.dependencies {
+			unitDependencies[name] = true
+		}
+	}
+
+	usedDependencies := map[string]bool{}
+	allDependenciesUsed := false
+
+	for _, unitFile := range unitFiles {
+		if unitFile.body == nil {
+			continue
+		}
+
+		for _, traversal := range unitFile.traversals {
+			name, hasName := lintTraversalStep(traversal, 1)
+
+			switch traversal.RootName() {
+			case MetadataLocal:
+				switch {
+				case !hasName:
+					unitFile.allLocalsUsed = true
+				case unitFile.locals[name] == nil:
+					l.report(unitFile.lintFile, LintRuleUndefinedReference, traversal.SourceRange(), fmt.Sprintf("Local %q is not defined in the locals block of this config.", name))
+				default:
+					unitFile.usedLocals[name] = true
+				}
+
+			case MetadataDependency:
+				switch {
+				case !hasName:
+					allDependenciesUsed = true
+				case !unitDependencies[name]:
+					l.report(unitFile.lintFile, LintRuleUndefinedReference, traversal.SourceRange(), fmt.Sprintf("Dependency %q is not defined in %s or the configs it includes.", name, lintRelativePath(unitPath, unitFile.path)))
+				default:
+					usedDependencies[name] = true
+				}
+
+			case MetadataInclude:
+				// The bare include block is referenced without its label.
+				if _, hasBareInclude := unitFile.includes[""]; hasBareInclude {
+					continue
+				}
+				if !hasName {
+					for _, includePath := range unitFile.includePaths {
+						if included := l.files[includePath]; included != nil {
+							included.allLocalsUsed = true
+						}
+					}
+					continue
+				}
+
+				include, isDefined := unitFile.includes[name]
+				switch {
+				case !isDefined:
+					l.report(unitFile.lintFile, LintRuleUndefinedReference, traversal.SourceRange(), fmt.Sprintf("Include %q is not defined in this config.", name))
+				case !include.GetExpose():
+					l.report(unitFile.lintFile, LintRuleUndefinedReference, traversal.SourceRange(), fmt.Sprintf("Include %q is not exposed: set expose = true to reference it.", name))
+				default:
+					l.useIncludedLocals(unitFile.includePaths[name], traversal)
+				}
+			}
+		}
+	}
+
+	for _, unitFile := range unitFiles {
+		for name := range unitFile.dependencies {
+			if allDependenciesUsed || usedDependencies[name] {
+				unitFile.usedDependencies[name] = true
+			}
+		}
+	}
+}
+
+// useIncludedLocals records the locals of the included config at the given path referenced by the given traversal of
+// an exposed include, e.g. include.root.locals.region.
+func (l *linter) useIncludedLocals(includePath string, traversal hcl.Traversal) {
+	included := l.files[includePath]
+	if included == nil {
+		return
+	}
+
+	attrName, hasAttr := lintTraversalStep(traversal, 2)
+	switch {
+	case !hasAttr:
+		included.allLocalsUsed = true
+	case attrName == MetadataLocals:
+		if localName, hasLocal := lintTraversalStep(traversal, 3); hasLocal {
+			included.usedLocals[localName] = true
+		} else {
+			included.allLocalsUsed = true
+		}
+	}
+}
+
+// checkGeneratedFiles checks that the generate blocks of a unit, once merged, and the generate attribute of its
+// remote_state block write to distinct paths.
+func (l *linter) checkGeneratedFiles(unitFiles []*lintUnitFile) {
+	generatedFiles := []*lintGeneratedFile{}
+	// The generate blocks of the included configs are overridden by the ones of the including configs with the same
+	// name, and so is the remote_state block.
+	indexes := map[string]int{}
+	addGeneratedFile := func(key string, generatedFile *lintGeneratedFile) {
+		if index, found := indexes[key]; found {
+			generatedFiles[index] = generatedFile
+			return
+		}
+		indexes[key] = len(generatedFiles)
+		generatedFiles = append(generatedFiles, generatedFile)
+	}
+
+	for _, unitFile := range unitFiles {
+		if !unitFile.merged || unitFile.body == nil {
+			continue
+		}
+
+		for _, block := range unitFile.body.Blocks {
+			switch {
+			case block.Type == MetadataGenerateConfigs && len(block.Labels) == 1:
+				pathAttr := block.Body.Attributes["path"]
+				if pathAttr == nil || lintAttributeIsTrue(block.Body.Attributes["disable"], unitFile.evalCtx) {
+					continue
+				}
+				path, ok := lintEvalString(pathAttr.Expr, unitFile.evalCtx)
+				if !ok {
+					continue
+				}
+				addGeneratedFile("generate."+block.Labels[0], &lintGeneratedFile{
+					description: fmt.Sprintf("generate block %q", block.Labels[0]),
+					path:        path,
+					unitFile:    unitFile,
+					rng:         pathAttr.Expr.Range(),
+				})
+
+			case block.Type == MetadataRemoteState:
+				generateAttr := block.Body.Attributes["generate"]
+				if generateAttr == nil {
+					continue
+				}
+				generateVal, diags := generateAttr.Expr.Value(unitFile.evalCtx)
+				if diags.HasErrors() || !generateVal.IsWhollyKnown() || generateVal.IsNull() || !generateVal.Type().IsObjectType() || !generateVal.Type().HasAttribute("path") {
+					continue
+				}
+				pathVal := generateVal.GetAttr("path")
+				if pathVal.IsNull() || pathVal.Type() != cty.String {
+					continue
+				}
+				addGeneratedFile(MetadataRemoteState, &lintGeneratedFile{
+					description: "the remote_state block",
+					path:        pathVal.AsString(),
+					unitFile:    unitFile,
+					rng:         generateAttr.Expr.Range(),
+				})
+			}
+		}
+	}
+
+	firstByPath := map[string]*lintGeneratedFile{}
+	for _, generatedFile := range generatedFiles {
+		path := filepath.Clean(generatedFile.path)
+		first, found := firstByPath[path]
+		if !found {
+			firstByPath[path] = generatedFile
+			continue
+		}
+
+		message := fmt.Sprintf("The %s generates %s, like the %s in %s.", generatedFile.description, generatedFile.path, first.description, lintRelativePath(first.unitFile.path, generatedFile.unitFile.path))
+		l.report(generatedFile.unitFile.lintFile, LintRuleGeneratePathCollision, generatedFile.rng, message)
+	}
+}
+
+// checkMockOutputs checks that the mock outputs of the dependencies of a unit are outputs of the modules of the
+// dependencies. The dependencies whose module is not local, or whose config can not be parsed, are skipped.
+func (l *linter) checkMockOutputs(ctx *ParsingContext, unitPath string, unitFiles []*lintUnitFile) {
+	config, err := PartialParseConfigFile(ctx.WithDecodeList(DependencyBlock), unitPath, nil)
+	if err != nil {
+		ctx.TerragruntOptions.Logger.Debugf("Could not parse the dependency blocks of %s, not checking their mock outputs: %v", unitPath, err)
+		return
+	}
+
+	for _, dependency := range config.TerragruntDependencies {
+		if !dependency.isEnabled() || dependency.MockOutputs == nil {
+			continue
+		}
+		mockOutputs := *dependency.MockOutputs
+		if !mockOutputs.IsWhollyKnown() || mockOutputs.IsNull() || !(mockOutputs.Type().IsObjectType() || mockOutputs.Type().IsMapType()) {
+			continue
+		}
+
+		modulePath, outputs, ok := l.dependencyModuleOutputs(ctx, unitPath, dependency)
+		if !ok {
+			continue
+		}
+
+		for name := range mockOutputs.AsValueMap() {
+			if util.ListContainsElement(outputs, name) {
+				continue
+			}
+
+			unitFile, rng, found := findMockOutputRange(unitFiles, dependency.Name, name)
+			if !found {
+				continue
+			}
+			message := fmt.Sprintf("Mock output %q of dependency %q is not an output of the module in %s.", name, dependency.Name, lintRelativePath(modulePath, unitFile.path))
+			l.report(unitFile.lintFile, LintRuleUnknownMockOutput, rng, message)
+		}
+	}
+}
+
+// dependencyModuleOutputs returns the path and the outputs of the terraform module of the given dependency: the local
+// source of its terraform block, or else its own directory. It returns false if the module can't be read locally.
+func (l *linter) dependencyModuleOutputs(ctx *ParsingContext, unitPath string, dependency Dependency) (string, []string, bool) {
+	dependencyConfigPath := getCleanedTargetConfigPath(dependency.ConfigPath, unitPath)
+	if !util.FileExists(dependencyConfigPath) {
+		return "", nil, false
+	}
+
+	dependencyCtx := ctx.WithTerragruntOptions(ctx.TerragruntOptions.Clone(dependencyConfigPath)).WithDecodeList(TerraformSource)
+	dependencyConfig, err := PartialParseConfigFile(dependencyCtx, dependencyConfigPath, nil)
+	if err != nil {
+		ctx.TerragruntOptions.Logger.Debugf("Could not parse the terraform source of %s, not checking the mock outputs of dependency %s: %v", dependencyConfigPath, dependency.Name, err)
+		return "", nil, false
+	}
+
+	modulePath := filepath.Dir(dependencyConfigPath)
+	if dependencyConfig.Terraform != nil && dependencyConfig.Terraform.Source != nil && *dependencyConfig.Terraform.Source != "" {
+		sourceURL, err := terraform.ToSourceUrl(*dependencyConfig.Terraform.Source, modulePath)
+		if err != nil || !terraform.IsLocalSource(sourceURL) {
+			return "", nil, false
+		}
+		modulePath = filepath.Clean(filepath.FromSlash(sourceURL.Path))
+	}
+
+	tfFiles, err := filepath.Glob(filepath.Join(modulePath, "*.tf"))
+	if err != nil || len(tfFiles) == 0 {
+		return "", nil, false
+	}
+
+	outputs, err := terraform.ModuleOutputs(modulePath)
+	if err != nil {
+		ctx.TerragruntOptions.Logger.Debugf("Could not read the outputs of the module in %s: %v", modulePath, err)
+		return "", nil, false
+	}
+	return modulePath, outputs, true
+}
+
+// findMockOutputRange returns the config setting the given mock output of the given dependency, and the range of its
+// key. The including configs take precedence, as their mock outputs are merged over the ones of the included configs.
+func findMockOutputRange(unitFiles []*lintUnitFile, dependencyName string, outputName string) (*lintUnitFile, hcl.Range, bool) {
+	for i := len(unitFiles) - 1; i >= 0; i-- {
+		unitFile := unitFiles[i]
+		block := unitFile.dependencies[dependencyName]
+		if !unitFile.merged || block == nil {
+			continue
+		}
+		mockOutputsAttr := block.Body.Attributes["mock_outputs"]
+		if mockOutputsAttr == nil {
+			continue
+		}
+
+		objectExpr, ok := mockOutputsAttr.Expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return unitFile, mockOutputsAttr.Expr.Range(), true
+		}
+		for _, item := range objectExpr.Items {
+			// The keys that are bare words evaluate to the word itself.
+			if keyVal, diags := item.KeyExpr.Value(nil); !diags.HasErrors() && keyVal.Type() == cty.String && keyVal.IsKnown() && keyVal.AsString() == outputName {
+				return unitFile, item.KeyExpr.Range(), true
+			}
+		}
+	}
+	return nil, hcl.Range{}, false
+}
+
+// lintFiles checks each config on its own, once all the units are checked: the deprecated attributes, and the locals
+// and dependencies that none of the units reference.
+func (l *linter) lintFiles() {
+	for _, lf := range l.files {
+		if lf.body == nil {
+			continue
+		}
+
+		for _, block := range lf.body.Blocks {
+			for name, replacement := range lintDeprecatedAttributes[block.Type] {
+				attr := block.Body.Attributes[name]
+				if attr == nil {
+					continue
+				}
+				l.report(lf, LintRuleDeprecatedAttribute, attr.NameRange, fmt.Sprintf("The %s attribute of %s blocks is deprecated: use %s instead.", name, block.Type, replacement))
+			}
+		}
+
+		if !lf.allLocalsUsed {
+			for name, attr := range lf.locals {
+				if !lf.usedLocals[name] {
+					l.report(lf, LintRuleUnusedLocal, attr.NameRange, fmt.Sprintf("Local %q is never referenced.", name))
+				}
+			}
+		}
+
+		if lf.mergedInUnits {
+			for name, block := range lf.dependencies {
+				if lf.usedDependencies[name] || lintAttributeIsTrue(block.Body.Attributes["skip_outputs"], nil) {
+					continue
+				}
+				l.report(lf, LintRuleUnusedDependency, block.LabelRanges[0], fmt.Sprintf("The outputs of dependency %q are never referenced.", name))
+			}
+		}
+	}
+}
+
+// lintTraversalStep returns the name of the attribute or the string key accessed by the given step of a traversal.
+func lintTraversalStep(traversal hcl.Traversal, index int) (string, bool) {
+	if len(traversal) <= index {
+		return "", false
+	}
+
+	switch step := traversal[index].(type) {
+	case hcl.TraverseAttr:
+		return step.Name, true
+	case hcl.TraverseIndex:
+		if step.Key.Type() == cty.String && step.Key.IsKnown() && !step.Key.IsNull() {
+			return step.Key.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// lintEvalString evaluates the given expression to a string, returning false if it can not be evaluated statically.
+func lintEvalString(expr hcl.Expression, evalCtx *hcl.EvalContext) (string, bool) {
+	val, diags := expr.Value(evalCtx)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// lintAttributeIsTrue returns true if the given attribute is set, and evaluates to true.
+func lintAttributeIsTrue(attr *hclsyntax.Attribute, evalCtx *hcl.EvalContext) bool {
+	if attr == nil {
+		return false
+	}
+	val, diags := attr.Expr.Value(evalCtx)
+	return !diags.HasErrors() && val.IsWhollyKnown() && !val.IsNull() && val.Type() == cty.Bool && val.True()
+}
+
+// lintRelativePath returns the given path relative to the directory of the given config, for the messages.
+func lintRelativePath(path string, configPath string) string {
+	relPath, err := filepath.Rel(filepath.Dir(configPath), path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
+
+// lintFileStartRange returns the range of the start of the given config, for the issues without a position.
+func lintFileStartRange(configPath string) hcl.Range {
+	return hcl.Range{
+		Filename: configPath,
+		Start:    hcl.InitialPos,
+		End:      hcl.InitialPos,
+	}
+}
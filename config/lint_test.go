@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+const lintTestRootConfig = `
+locals {
+  region = "us-east-1"
+  owner  = "platform"
+}
+
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite"
+  contents  = ""
+}
+`
+
+const lintTestAppConfig = `
+include "root" {
+  path   = find_in_parent_folders("root.hcl")
+  expose = true
+}
+
+include "env" {
+  path = find_in_parent_folders("env.hcl")
+}
+
+locals {
+  name   = "app"
+  unused = "x"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    vpc_id    = "vpc-mock"
+    subnet_id = "subnet-mock"
+  }
+  mock_outputs_merge_with_state = true
+}
+
+dependency "db" {
+  config_path = "../vpc"
+}
+
+generate "backend" {
+  path      = "provider.tf"
+  if_exists = "overwrite"
+  contents  = ""
+}
+
+inputs = {
+  name    = local.name
+  region  = include.root.locals.region
+  vpc_id  = dependency.vpc.outputs.vpc_id
+  missing = local.missing
+  cache   = dependency.cache.outputs.id
+  env     = include.env.locals.env
+  other   = include.other.locals.env
+}
+`
+
+// lintTestConfigs lints the given units of the given directory, and returns the issues found formatted as
+// `path:line: rule: message`, with the paths relative to the directory.
+func lintTestConfigs(t *testing.T, rootDir string, units ...string) []string {
+	configPaths := []string{}
+	for _, unit := range units {
+		configPaths = append(configPaths, filepath.Join(rootDir, unit, DefaultTerragruntConfigPath))
+	}
+	opts := terragruntOptionsForTest(t, configPaths[0])
+
+	issues := []string{}
+	for _, diagnostic := range LintConfigs(NewParsingContext(context.Background(), opts), configPaths) {
+		relPath, err := filepath.Rel(rootDir, diagnostic.Range.Filename)
+		require.NoError(t, err)
+		issues = append(issues, fmt.Sprintf("%s:%d: %s: %s", filepath.ToSlash(relPath), diagnostic.Range.Start.Line, diagnostic.Rule, diagnostic.Message))
+	}
+	return issues
+}
+
+func TestLintConfigs(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"root.hcl":               lintTestRootConfig,
+		"env.hcl":                `locals { env = "dev" }`,
+		"modules/vpc/outputs.tf": `output "vpc_id" { value = "vpc" }`,
+		"vpc/terragrunt.hcl":     `terraform { source = "../modules/vpc" }`,
+		"app/terragrunt.hcl":     lintTestAppConfig,
+	})
+
+	assert.Equal(t, []string{
+		`app/terragrunt.hcl:13: unused-local: Local "unused" is never referenced.`,
+		`app/terragrunt.hcl:21: unknown-mock-output: Mock output "subnet_id" of dependency "vpc" is not an output of the module in ../modules/vpc.`,
+		`app/terragrunt.hcl:23: deprecated-attribute: The mock_outputs_merge_with_state attribute of dependency blocks is deprecated: use mock_outputs_merge_strategy_with_state instead.`,
+		`app/terragrunt.hcl:26: unused-dependency: The outputs of dependency "db" are never referenced.`,
+		`app/terragrunt.hcl:31: generate-path-collision: The generate block "backend" generates provider.tf, like the generate block "provider" in ../root.hcl.`,
+		`app/terragrunt.hcl:40: undefined-reference: Local "missing" is not defined in the locals block of this config.`,
+		`app/terragrunt.hcl:41: undefined-reference: Dependency "cache" is not defined in terragrunt.hcl or the configs it includes.`,
+		`app/terragrunt.hcl:42: undefined-reference: Include "env" is not exposed: set expose = true to reference it.`,
+		`app/terragrunt.hcl:43: undefined-reference: Include "other" is not defined in this config.`,
+		`env.hcl:1: unused-local: Local "env" is never referenced.`,
+		`root.hcl:4: unused-local: Local "owner" is never referenced.`,
+	}, lintTestConfigs(t, rootDir, "app", "vpc"))
+}
+
+func TestLintConfigsSharedInclude(t *testing.T) {
+	t.Parallel()
+
+	rootConfig := `
+locals {
+  region = "us-east-1"
+  owner  = "platform"
+}
+
+dependency "vpc" {
+  config_path = "${get_parent_terragrunt_dir()}/vpc"
+}
+
+inputs = {
+  name = dependency.vpc.outputs.name
+}
+`
+	childConfig := `
+include "root" {
+  path   = find_in_parent_folders("root.hcl")
+  expose = true
+}
+
+inputs = {
+  %s = include.root.locals.%s
+}
+`
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"root.hcl":           rootConfig,
+		"vpc/terragrunt.hcl": ``,
+		"a/terragrunt.hcl":   fmt.Sprintf(childConfig, "region", "region"),
+		"b/terragrunt.hcl":   fmt.Sprintf(childConfig, "owner", "owner"),
+	})
+
+	// Each local of the root config is referenced by one of the units, and its dependency is referenced by itself.
+	assert.Empty(t, lintTestConfigs(t, rootDir, "a", "b"))
+}
+
+func TestLintConfigsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"app/terragrunt.hcl": "locals {\n  name = \n}\n",
+	})
+
+	issues := lintTestConfigs(t, rootDir, "app")
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0], "app/terragrunt.hcl:2: invalid-config: ")
+}
+
+func TestLintConfigsDoesNotRunCommands(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"plugin.sh": "#!/bin/sh\ntouch \"$(dirname \"$0\")/plugin-ran\"\n",
+		"app/terragrunt.hcl": `
+plugin "cmdb" {
+  command = "../plugin.sh"
+}
+
+locals {
+  marker = run_cmd("touch", "${get_terragrunt_dir()}/run-cmd-ran")
+  unused = "x"
+}
+
+inputs = {
+  marker = local.marker
+  owner  = host_info("web").owner
+}
+`,
+	})
+	require.NoError(t, os.Chmod(filepath.Join(rootDir, "plugin.sh"), 0755))
+
+	assert.Equal(t, []string{`app/terragrunt.hcl:8: unused-local: Local "unused" is never referenced.`}, lintTestConfigs(t, rootDir, "app"))
+	assert.NoFileExists(t, filepath.Join(rootDir, "app", "run-cmd-ran"))
+	assert.NoFileExists(t, filepath.Join(rootDir, "plugin-ran"))
+}
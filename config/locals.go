@@ -158,8 +158,8 @@ func canEvaluateLocals(expression hcl.Expression, evaluatedLocals map[string]cty
 
 		rootName := var_.RootName()
 
-		// If the variable is `include`, then we can evaluate it now
-		if rootName == MetadataInclude {
+		// If the variable is `include` or `feature`, then we can evaluate it now
+		if rootName == MetadataInclude || rootName == MetadataFeatureFlag {
 			continue
 		}
 
@@ -0,0 +1,30 @@
+//go:build !windows
+// +build !windows
+
+package config
+
+import (
+	"os"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"golang.org/x/sys/unix"
+)
+
+// lockFile takes an exclusive lock on the given file, creating it if needed, and blocks until the lock is acquired. The
+// lock is shared across processes. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX); err != nil {
+		file.Close()
+		return nil, errors.WithStackTrace(err)
+	}
+
+	return func() {
+		unix.Flock(int(file.Fd()), unix.LOCK_UN) //nolint:errcheck
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package config
+
+import (
+	"os"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive lock on the given file, creating it if needed, and blocks until the lock is acquired. The
+// lock is shared across processes. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	overlapped := &windows.Overlapped{}
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, errors.WithStackTrace(err)
+	}
+
+	return func() {
+		windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped) //nolint:errcheck
+		file.Close()
+	}, nil
+}
@@ -33,9 +33,31 @@ type ParsingContext struct {
 	// expected to be available.
 	PartialParseDecodeList []PartialDecodeSectionType
 
+	// Functions are the user-defined functions of the function blocks of the current config and the configs it
+	// includes.
+	Functions map[string]function.Function
+
+	// FeatureFlags are the values of the feature flags of the feature blocks of the current config and the configs it
+	// includes, exposed under the feature variable.
+	FeatureFlags *cty.Value
+
+	// IncludedFiles are the configs included at every level by the config being parsed and the configs it includes,
+	// by path of the config that includes them. The include hierarchy is parsed once with the top level config, and
+	// reused when the included configs are parsed.
+	IncludedFiles map[string][]*hclparse.File
+
+	// ReadRecorder records the files, environment variables and commands read while parsing the configs cached in the
+	// on-disk partial parse config cache. It is nil when the on-disk cache is disabled.
+	ReadRecorder *configReadRecorder
+
 	// These functions have the highest priority and will overwrite any others with the same name
 	PredefinedFunctions map[string]function.Function
 
+	// StaticAnalysis is true when the configs are analyzed without being run, e.g. by the linter: the functions with
+	// side effects or that reach external services are replaced by functions returning unknown values, the outputs of
+	// the dependencies are unknown, and the plugins are not run.
+	StaticAnalysis bool
+
 	// `ParserOptions` is used to configure hcl Parser.
 	ParserOptions []hclparse.Option
 
@@ -66,7 +88,54 @@ func (ctx ParsingContext) WithLocals(locals *cty.Value) *ParsingContext {
 	return &ctx
 }
 
+func (ctx ParsingContext) WithFunctions(functions map[string]function.Function) *ParsingContext {
+	ctx.Functions = functions
+	return &ctx
+}
+
+func (ctx ParsingContext) WithFeatureFlags(featureFlags *cty.Value) *ParsingContext {
+	ctx.FeatureFlags = featureFlags
+	return &ctx
+}
+
+func (ctx ParsingContext) WithIncludedFiles(includedFiles map[string][]*hclparse.File) *ParsingContext {
+	ctx.IncludedFiles = includedFiles
+	return &ctx
+}
+
 func (ctx ParsingContext) WithTrackInclude(trackInclude *TrackInclude) *ParsingContext {
 	ctx.TrackInclude = trackInclude
 	return &ctx
 }
+
+func (ctx ParsingContext) WithReadRecorder(recorder *configReadRecorder) *ParsingContext {
+	ctx.ReadRecorder = recorder
+	return &ctx
+}
+
+// WithStaticAnalysis returns a context to analyze the configs without running the commands, plugins and external
+// calls they may contain.
+func (ctx ParsingContext) WithStaticAnalysis() *ParsingContext {
+	ctx.StaticAnalysis = true
+
+	predefinedFunctions := map[string]function.Function{}
+	for _, name := range staticAnalysisStubbedFunctions {
+		predefinedFunctions[name] = wrapAnyToUnknownAsFuncImpl()
+	}
+	for name, fn := range ctx.PredefinedFunctions {
+		predefinedFunctions[name] = fn
+	}
+	ctx.PredefinedFunctions = predefinedFunctions
+	return &ctx
+}
+
+// staticAnalysisStubbedFunctions are the functions that run commands, reach external services or parse other configs
+// along with their dependencies, which evaluate to unknown values during a static analysis.
+var staticAnalysisStubbedFunctions = []string{
+	FuncNameRunCmd,
+	FuncNameSopsDecryptFile,
+	FuncNameReadTerragruntConfig,
+	FuncNameGetAWSAccountID,
+	FuncNameGetAWSCallerIdentityArn,
+	FuncNameGetAWSCallerIdentityUserID,
+}
@@ -0,0 +1,579 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// partialParseConfigCacheVersion is part of the key of every entry of the on-disk partial parse config cache. Bump it
+// whenever the format of the entries or the way configs are parsed changes, so that stale entries are not reused.
+const partialParseConfigCacheVersion = 1
+
+// The terraform functions reading the file given as first argument, relative to the directory of the config.
+var terraformFileFunctions = []string{
+	"file",
+	"filebase64",
+	"filebase64sha256",
+	"filebase64sha512",
+	"fileexists",
+	"filemd5",
+	"filesha1",
+	"filesha256",
+	"filesha512",
+	"templatefile",
+}
+
+// The terraform functions whose result can't be checked to be unchanged, so that configs calling them are not cached
+// on disk.
+var terraformUncacheableFunctions = []string{
+	"abspath",
+	"bcrypt",
+	"fileset",
+	"timestamp",
+	"uuid",
+}
+
+// partialParseConfigReads is the record of what a partially parsed config read besides the config file itself. An
+// entry of the on-disk cache is only reused if all of them are unchanged.
+type partialParseConfigReads struct {
+	// Files maps the absolute path of each file read to the sha256 hash of its contents, or to an empty string if the
+	// file did not exist.
+	Files map[string]string `json:"files"`
+	// Env maps the name of each environment variable read to its value, or to nil if it was not set.
+	Env map[string]*string `json:"env"`
+	// Commands are the commands run with run_cmd.
+	Commands []partialParseConfigCommand `json:"commands"`
+}
+
+// partialParseConfigCommand is a command run with run_cmd while parsing a config.
+type partialParseConfigCommand struct {
+	ConfigPath string   `json:"config_path"`
+	Args       []string `json:"args"`
+	OutputHash string   `json:"output_hash"`
+}
+
+// configReadRecorder records what is read while parsing a config. The reads are recorded in the recorders of all the
+// configs being parsed, since a config reads everything read by the configs it includes or reads.
+type configReadRecorder struct {
+	parent *configReadRecorder
+
+	mutex sync.Mutex
+	reads partialParseConfigReads
+	// uncacheableReason is set when the config read something that can't be checked to be unchanged.
+	uncacheableReason string
+}
+
+func newConfigReadRecorder(parent *configReadRecorder) *configReadRecorder {
+	return &configReadRecorder{
+		parent: parent,
+		reads: partialParseConfigReads{
+			Files: map[string]string{},
+			Env:   map[string]*string{},
+		},
+	}
+}
+
+// update calls the given function with this recorder and each of its parents, holding their lock.
+func (recorder *configReadRecorder) update(updateFunc func(recorder *configReadRecorder)) {
+	for ; recorder != nil; recorder = recorder.parent {
+		recorder.mutex.Lock()
+		updateFunc(recorder)
+		recorder.mutex.Unlock()
+	}
+}
+
+// recordFile records the current contents of the given file.
+func (recorder *configReadRecorder) recordFile(path string) {
+	if recorder == nil {
+		return
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		recorder.markUncacheable(fmt.Sprintf("failed to get the absolute path of %s: %v", path, err))
+		return
+	}
+	hash, err := hashFileIfExists(path)
+	if err != nil {
+		recorder.markUncacheable(fmt.Sprintf("failed to read %s: %v", path, err))
+		return
+	}
+
+	recorder.update(func(recorder *configReadRecorder) {
+		recorder.reads.Files[path] = hash
+	})
+}
+
+// recordEnv records the value of the given environment variable, nil if it is not set.
+func (recorder *configReadRecorder) recordEnv(name string, value *string) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.update(func(recorder *configReadRecorder) {
+		recorder.reads.Env[name] = value
+	})
+}
+
+// recordCommand records a command run with run_cmd, which makes the config uncacheable unless
+// --terragrunt-partial-parse-config-cache-run-cmd is set.
+func (recorder *configReadRecorder) recordCommand(ctx *ParsingContext, args []string, output string) {
+	if recorder == nil {
+		return
+	}
+	if !ctx.TerragruntOptions.PartialParseConfigCacheRunCmd {
+		recorder.markUncacheable(fmt.Sprintf("it calls %s", FuncNameRunCmd))
+		return
+	}
+
+	command := partialParseConfigCommand{
+		ConfigPath: ctx.TerragruntOptions.TerragruntConfigPath,
+		Args:       args,
+		OutputHash: hashString(output),
+	}
+	recorder.update(func(recorder *configReadRecorder) {
+		recorder.reads.Commands = append(recorder.reads.Commands, command)
+	})
+}
+
+// markUncacheable records that the config can't be cached on disk for the given reason.
+func (recorder *configReadRecorder) markUncacheable(reason string) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.update(func(recorder *configReadRecorder) {
+		if recorder.uncacheableReason == "" {
+			recorder.uncacheableReason = reason
+		}
+	})
+}
+
+// merge records the given reads, e.g. the reads of a config taken from the cache.
+func (recorder *configReadRecorder) merge(reads partialParseConfigReads, uncacheableReason string) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.update(func(recorder *configReadRecorder) {
+		for path, hash := range reads.Files {
+			recorder.reads.Files[path] = hash
+		}
+		for name, value := range reads.Env {
+			recorder.reads.Env[name] = value
+		}
+		recorder.reads.Commands = append(recorder.reads.Commands, reads.Commands...)
+		if recorder.uncacheableReason == "" {
+			recorder.uncacheableReason = uncacheableReason
+		}
+	})
+}
+
+// mergeFrom records the reads of the given recorder.
+func (recorder *configReadRecorder) mergeFrom(source *configReadRecorder) {
+	if recorder == nil {
+		return
+	}
+
+	source.mutex.Lock()
+	reads, uncacheableReason := source.reads, source.uncacheableReason
+	source.mutex.Unlock()
+
+	recorder.merge(reads, uncacheableReason)
+}
+
+// result returns the recorded reads, and the reason why the config can't be cached, if any.
+func (recorder *configReadRecorder) result() (partialParseConfigReads, string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.reads, recorder.uncacheableReason
+}
+
+// partialParseConfigCacheReads holds the reads of the configs in the in-memory partial parse config cache, so that they
+// are recorded when a config parsed for the on-disk cache includes or reads one of them.
+var partialParseConfigCacheReads = sync.Map{}
+
+// partialParseConfigCacheEntry is the content of a file of the on-disk partial parse config cache.
+type partialParseConfigCacheEntry struct {
+	ConfigPath   string                   `json:"config_path"`
+	Reads        partialParseConfigReads  `json:"reads"`
+	Config       TerragruntConfig         `json:"config"`
+	Dependencies []partialParseDependency `json:"dependencies"`
+}
+
+// partialParseDependency is a dependency block in the on-disk partial parse config cache, with its cty values encoded
+// along with their type.
+type partialParseDependency struct {
+	Dependency
+	MockOutputs     *partialParseCtyValue
+	RenderedOutputs *partialParseCtyValue
+	Inputs          *partialParseCtyValue
+}
+
+// partialParseCtyValue is a cty value encoded as JSON along with its type, so that it can be decoded back.
+type partialParseCtyValue struct {
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// partialParseConfigWithDiskCache partially parses the given config, reusing the config cached in the on-disk cache
+// configured with --terragrunt-partial-parse-config-cache-dir if neither the config nor anything it reads changed. The
+// returned recorder holds what the config read, and is nil if the on-disk cache is disabled.
+func partialParseConfigWithDiskCache(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (*TerragruntConfig, *configReadRecorder, error) {
+	terragruntOptions := ctx.TerragruntOptions
+	if !terragruntOptions.UsePartialParseConfigCache || terragruntOptions.PartialParseConfigCacheDir == "" {
+		config, err := PartialParseConfig(ctx, file, includeFromChild)
+		return config, nil, err
+	}
+
+	recorder := newConfigReadRecorder(ctx.ReadRecorder)
+	ctx = ctx.WithReadRecorder(recorder)
+	recorder.recordFile(file.ConfigPath)
+
+	// The configs parsed with values from the config including or reading them can't be cached on their own.
+	switch {
+	case ctx.DecodedDependencies != nil:
+		recorder.markUncacheable("it is parsed with the outputs of dependencies")
+	case len(ctx.PredefinedFunctions) > 0:
+		recorder.markUncacheable("it is parsed with predefined functions")
+	case ctx.ConvertToTerragruntConfigFunc != nil:
+		recorder.markUncacheable("it is parsed with a custom converter")
+	}
+
+	cachePath, err := partialParseConfigCachePath(ctx, file, includeFromChild)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, uncacheableReason := recorder.result(); uncacheableReason == "" {
+		if entry, isCached := readPartialParseConfigCache(ctx, cachePath, file.ConfigPath); isCached {
+			config, err := entry.toTerragruntConfig()
+			if err == nil {
+				terragruntOptions.Logger.Debugf("Using the partially parsed config %s cached in %s, decodeList: '%v'.", file.ConfigPath, cachePath, ctx.PartialParseDecodeList)
+				recorder.merge(entry.Reads, "")
+				return config, recorder, nil
+			}
+			terragruntOptions.Logger.Debugf("Ignoring the partially parsed config cached in %s: %v", cachePath, err)
+		}
+	}
+
+	config, err := PartialParseConfig(ctx, file, includeFromChild)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reads, uncacheableReason := recorder.result()
+	if uncacheableReason != "" {
+		terragruntOptions.Logger.Debugf("Not caching the partially parsed config %s on disk, because %s.", file.ConfigPath, uncacheableReason)
+		return config, recorder, nil
+	}
+
+	if err := writePartialParseConfigCache(cachePath, file.ConfigPath, reads, config); err != nil {
+		// The config was parsed, so failing to cache it shouldn't fail the command.
+		terragruntOptions.Logger.Warnf("Failed to cache the partially parsed config %s in %s: %v", file.ConfigPath, cachePath, err)
+	}
+
+	return config, recorder, nil
+}
+
+// partialParseConfigCachePath returns the path of the file the given config is cached in. The name of the file is a
+// hash of the config, of how it is parsed, and of the options it can read while parsed.
+func partialParseConfigCachePath(ctx *ParsingContext, file *hclparse.File, includeFromChild *IncludeConfig) (string, error) {
+	terragruntOptions := ctx.TerragruntOptions
+
+	configPath, err := filepath.Abs(file.ConfigPath)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	var childFeatureFlags string
+	if includeFromChild != nil && ctx.FeatureFlags != nil {
+		childFeatureFlags = fmt.Sprintf("%#v", *ctx.FeatureFlags)
+	}
+
+	key, err := json.Marshal(map[string]interface{}{
+		"version":                partialParseConfigCacheVersion,
+		"terragrunt_version":     fmt.Sprint(terragruntOptions.TerragruntVersion),
+		"os":                     runtime.GOOS,
+		"config_path":            configPath,
+		"content":                hashString(file.Content()),
+		"include_from_child":     includeFromChild,
+		"decode_list":            ctx.PartialParseDecodeList,
+		"child_feature_flags":    childFeatureFlags,
+		"terragrunt_config_path": terragruntOptions.TerragruntConfigPath,
+		"original_config_path":   terragruntOptions.OriginalTerragruntConfigPath,
+		"working_dir":            terragruntOptions.WorkingDir,
+		"terraform_command":      terragruntOptions.TerraformCommand,
+		"original_command":       terragruntOptions.OriginalTerraformCommand,
+		"terraform_cli_args":     terragruntOptions.TerraformCliArgs,
+		"source":                 terragruntOptions.Source,
+		"source_map":             terragruntOptions.SourceMap,
+		"feature_flags":          terragruntOptions.FeatureFlags,
+		"max_folders_to_check":   terragruntOptions.MaxFoldersToCheck,
+		"cache_run_cmd":          terragruntOptions.PartialParseConfigCacheRunCmd,
+	})
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	cacheDir := terragruntOptions.PartialParseConfigCacheDir
+	if !filepath.IsAbs(cacheDir) {
+		cacheDir = util.JoinPath(terragruntOptions.WorkingDir, cacheDir)
+	}
+	return filepath.Join(cacheDir, hashString(string(key))+".json"), nil
+}
+
+// readPartialParseConfigCache returns the entry cached in the given file, if everything the config read is unchanged.
+func readPartialParseConfigCache(ctx *ParsingContext, cachePath string, configPath string) (*partialParseConfigCacheEntry, bool) {
+	terragruntOptions := ctx.TerragruntOptions
+	if !util.FileExists(cachePath) {
+		return nil, false
+	}
+
+	contents, err := os.ReadFile(cachePath)
+	if err != nil {
+		terragruntOptions.Logger.Debugf("Failed to read the partial parse config cache %s: %v", cachePath, err)
+		return nil, false
+	}
+
+	entry := &partialParseConfigCacheEntry{}
+	if err := json.Unmarshal(contents, entry); err != nil {
+		terragruntOptions.Logger.Debugf("Ignoring the corrupt partial parse config cache %s: %v", cachePath, err)
+		return nil, false
+	}
+	if entry.ConfigPath != configPath {
+		return nil, false
+	}
+
+	if changed := entry.Reads.findChange(ctx); changed != "" {
+		terragruntOptions.Logger.Debugf("The partially parsed config %s cached in %s is stale: %s changed.", configPath, cachePath, changed)
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// findChange returns a description of the first read that changed, or an empty string if none did. The commands are
+// run again to compare their output.
+func (reads partialParseConfigReads) findChange(ctx *ParsingContext) string {
+	for path, hash := range reads.Files {
+		if currentHash, err := hashFileIfExists(path); err != nil || currentHash != hash {
+			return path
+		}
+	}
+
+	for name, value := range reads.Env {
+		currentValue, isSet := ctx.TerragruntOptions.Env[name]
+		if isSet != (value != nil) || (isSet && currentValue != *value) {
+			return fmt.Sprintf("environment variable %s", name)
+		}
+	}
+
+	for _, command := range reads.Commands {
+		commandCtx := NewParsingContext(ctx, ctx.TerragruntOptions.Clone(command.ConfigPath))
+		// runCommand modifies the args it is given.
+		output, err := runCommand(commandCtx, append([]string{}, command.Args...))
+		if err != nil || hashString(output) != command.OutputHash {
+			return fmt.Sprintf("the output of %s %v", FuncNameRunCmd, command.Args)
+		}
+	}
+
+	return ""
+}
+
+// writePartialParseConfigCache writes the given config to the on-disk cache along with what it read. The file is only
+// readable by the current user, as the locals of the config may hold the values of environment variables.
+func writePartialParseConfigCache(cachePath string, configPath string, reads partialParseConfigReads, config *TerragruntConfig) error {
+	entry := partialParseConfigCacheEntry{
+		ConfigPath: configPath,
+		Reads:      reads,
+		Config:     *config,
+	}
+	entry.Config.TerragruntDependencies = nil
+
+	if config.TerragruntDependencies != nil {
+		entry.Dependencies = make([]partialParseDependency, 0, len(config.TerragruntDependencies))
+	}
+	for _, dependency := range config.TerragruntDependencies {
+		cachedDependency := partialParseDependency{Dependency: dependency}
+		cachedDependency.Dependency.MockOutputs = nil
+		cachedDependency.Dependency.RenderedOutputs = nil
+		cachedDependency.Dependency.Inputs = nil
+
+		var err error
+		if cachedDependency.MockOutputs, err = newPartialParseCtyValue(dependency.MockOutputs); err != nil {
+			return err
+		}
+		if cachedDependency.RenderedOutputs, err = newPartialParseCtyValue(dependency.RenderedOutputs); err != nil {
+			return err
+		}
+		if cachedDependency.Inputs, err = newPartialParseCtyValue(dependency.Inputs); err != nil {
+			return err
+		}
+		entry.Dependencies = append(entry.Dependencies, cachedDependency)
+	}
+
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if err := util.EnsureDirectory(filepath.Dir(cachePath)); err != nil {
+		return err
+	}
+	return writeCacheFile(cachePath, contents)
+}
+
+// toTerragruntConfig returns the config of the cache entry.
+func (entry *partialParseConfigCacheEntry) toTerragruntConfig() (*TerragruntConfig, error) {
+	config := entry.Config
+
+	if entry.Dependencies != nil {
+		config.TerragruntDependencies = make([]Dependency, 0, len(entry.Dependencies))
+	}
+	for _, cachedDependency := range entry.Dependencies {
+		dependency := cachedDependency.Dependency
+
+		var err error
+		if dependency.MockOutputs, err = cachedDependency.MockOutputs.toCtyValue(); err != nil {
+			return nil, err
+		}
+		if dependency.RenderedOutputs, err = cachedDependency.RenderedOutputs.toCtyValue(); err != nil {
+			return nil, err
+		}
+		if dependency.Inputs, err = cachedDependency.Inputs.toCtyValue(); err != nil {
+			return nil, err
+		}
+		config.TerragruntDependencies = append(config.TerragruntDependencies, dependency)
+	}
+
+	return &config, nil
+}
+
+func newPartialParseCtyValue(value *cty.Value) (*partialParseCtyValue, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	typeJSON, err := ctyjson.MarshalType(value.Type())
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	valueJSON, err := ctyjson.Marshal(*value, value.Type())
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return &partialParseCtyValue{Type: typeJSON, Value: valueJSON}, nil
+}
+
+func (cachedValue *partialParseCtyValue) toCtyValue() (*cty.Value, error) {
+	if cachedValue == nil {
+		return nil, nil
+	}
+
+	valueType, err := ctyjson.UnmarshalType(cachedValue.Type)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	value, err := ctyjson.Unmarshal(cachedValue.Value, valueType)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return &value, nil
+}
+
+// wrapTerraformFunctionsForReadRecorder wraps the given terraform functions reading files so that the files are
+// recorded, and the ones whose result can't be checked to be unchanged so that the config is not cached on disk.
+func wrapTerraformFunctionsForReadRecorder(recorder *configReadRecorder, baseDir string, functions map[string]function.Function) {
+	for _, name := range terraformFileFunctions {
+		fn, found := functions[name]
+		if !found {
+			continue
+		}
+		functions[name] = wrapFunctionWithRecorder(fn, func(args []cty.Value) {
+			if len(args) == 0 {
+				return
+			}
+			path, _ := args[0].Unmark()
+			if path.IsNull() || !path.IsKnown() || path.Type() != cty.String {
+				return
+			}
+			filePath, err := homedir.Expand(path.AsString())
+			if err != nil {
+				recorder.markUncacheable(fmt.Sprintf("failed to expand the path %s: %v", path.AsString(), err))
+				return
+			}
+			if !filepath.IsAbs(filePath) {
+				filePath = filepath.Join(baseDir, filePath)
+			}
+			recorder.recordFile(filePath)
+		})
+	}
+
+	for _, name := range terraformUncacheableFunctions {
+		fn, found := functions[name]
+		if !found {
+			continue
+		}
+		reason := fmt.Sprintf("it calls %s", name)
+		functions[name] = wrapFunctionWithRecorder(fn, func(args []cty.Value) {
+			recorder.markUncacheable(reason)
+		})
+	}
+}
+
+// wrapFunctionWithRecorder returns a function calling record with its arguments before calling the given function.
+func wrapFunctionWithRecorder(fn function.Function, record func(args []cty.Value)) function.Function {
+	return function.New(&function.Spec{
+		Params:   fn.Params(),
+		VarParam: fn.VarParam(),
+		Type: func(args []cty.Value) (cty.Type, error) {
+			return fn.ReturnTypeForValues(args)
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			record(args)
+			return fn.Call(args)
+		},
+	})
+}
+
+// hashFileIfExists returns the sha256 hash of the contents of the given file, or an empty string if it doesn't exist.
+// Directories are hashed as their path, so that they only differ from a missing file.
+func hashFileIfExists(path string) (string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	if info.IsDir() {
+		return "dir:" + hashString(path), nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return hashString(string(contents)), nil
+}
+
+func hashString(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+const partialParseCacheTestRootConfig = `
+prevent_destroy = true
+`
+
+const partialParseCacheTestChildConfig = `
+include "root" {
+  path = find_in_parent_folders("root.hcl")
+}
+
+terraform {
+  source = "git::git@github.com:acme/modules.git//app?ref=${get_env("TG_TEST_MODULES_REF", "v1")}"
+}
+`
+
+// partialParseWithDiskCache partially parses the given config as a new terragrunt process would, i.e. without the
+// in-memory cache of the config itself.
+func partialParseWithDiskCache(t *testing.T, opts *options.TerragruntOptions) *TerragruntConfig {
+	file, err := hclparse.NewParser().ParseFromFile(opts.TerragruntConfigPath)
+	require.NoError(t, err)
+
+	ctx := NewParsingContext(context.Background(), opts).WithDecodeList(TerraformSource, TerragruntFlags, DependencyBlock)
+	config, _, err := partialParseConfigWithDiskCache(ctx, file, nil)
+	require.NoError(t, err)
+	return config
+}
+
+func partialParseCacheTestOptions(t *testing.T, childPath string, cacheDir string) *options.TerragruntOptions {
+	opts := terragruntOptionsForTestWithEnv(t, childPath, map[string]string{})
+	opts.UsePartialParseConfigCache = true
+	opts.PartialParseConfigCacheDir = cacheDir
+	return opts
+}
+
+// tamperPartialParseConfigCache changes the terraform source of the given config in the cache, to detect when it is
+// reused.
+func tamperPartialParseConfigCache(t *testing.T, cacheDir string, configPath string) {
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	require.NoError(t, err)
+
+	tampered := 0
+	for _, cacheFile := range cacheFiles {
+		contents, err := os.ReadFile(cacheFile)
+		require.NoError(t, err)
+		entry := partialParseConfigCacheEntry{}
+		require.NoError(t, json.Unmarshal(contents, &entry))
+		if entry.ConfigPath != configPath {
+			continue
+		}
+
+		source := "tampered"
+		entry.Config.Terraform.Source = &source
+		contents, err = json.Marshal(entry)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(cacheFile, contents, 0600))
+		tampered++
+	}
+	require.Equal(t, 1, tampered)
+}
+
+func TestPartialParseConfigDiskCache(t *testing.T) {
+	t.Parallel()
+
+	childPath := writeFunctionsTestConfigs(t, partialParseCacheTestRootConfig, partialParseCacheTestChildConfig)
+	cacheDir := t.TempDir()
+	opts := partialParseCacheTestOptions(t, childPath, cacheDir)
+
+	config := partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "git::git@github.com:acme/modules.git//app?ref=v1", *config.Terraform.Source)
+	assert.True(t, *config.PreventDestroy)
+
+	// Nothing the config reads changed, so it is taken from the cache.
+	tamperPartialParseConfigCache(t, cacheDir, childPath)
+	config = partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "tampered", *config.Terraform.Source)
+
+	// The config reads the environment variable.
+	opts.Env["TG_TEST_MODULES_REF"] = "v2"
+	config = partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "git::git@github.com:acme/modules.git//app?ref=v2", *config.Terraform.Source)
+
+	// The config includes the root config.
+	tamperPartialParseConfigCache(t, cacheDir, childPath)
+	rootPath := filepath.Join(filepath.Dir(filepath.Dir(childPath)), "root.hcl")
+	require.NoError(t, os.WriteFile(rootPath, []byte("prevent_destroy = false"), 0644))
+	config = partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "git::git@github.com:acme/modules.git//app?ref=v2", *config.Terraform.Source)
+	assert.False(t, *config.PreventDestroy)
+}
+
+func TestPartialParseConfigDiskCacheDependencies(t *testing.T) {
+	t.Parallel()
+
+	childConfig := partialParseCacheTestChildConfig + `
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    vpc_id  = "vpc-mock"
+    subnets = ["a", "b"]
+  }
+}
+`
+	childPath := writeFunctionsTestConfigs(t, partialParseCacheTestRootConfig, childConfig)
+	opts := partialParseCacheTestOptions(t, childPath, t.TempDir())
+
+	parsed := partialParseWithDiskCache(t, opts)
+	cached := partialParseWithDiskCache(t, opts)
+	require.Len(t, cached.TerragruntDependencies, 1)
+	assert.Equal(t, parsed, cached)
+	assert.True(t, parsed.TerragruntDependencies[0].MockOutputs.Equals(*cached.TerragruntDependencies[0].MockOutputs).True())
+}
+
+func TestPartialParseConfigDiskCacheRunCmd(t *testing.T) {
+	t.Parallel()
+
+	childConfig := `
+terraform {
+  source = "git::git@github.com:acme/modules.git//app?ref=${run_cmd("echo", "v1")}"
+}
+`
+	childPath := writeFunctionsTestConfigs(t, partialParseCacheTestRootConfig, childConfig)
+	cacheDir := t.TempDir()
+	opts := partialParseCacheTestOptions(t, childPath, cacheDir)
+
+	// The configs calling run_cmd are not cached, unless opted in.
+	partialParseWithDiskCache(t, opts)
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, cacheFiles)
+
+	opts.PartialParseConfigCacheRunCmd = true
+	config := partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "git::git@github.com:acme/modules.git//app?ref=v1", *config.Terraform.Source)
+
+	tamperPartialParseConfigCache(t, cacheDir, childPath)
+	config = partialParseWithDiskCache(t, opts)
+	assert.Equal(t, "tampered", *config.Terraform.Source)
+}
+
+func TestPartialParseConfigDiskCacheUncacheable(t *testing.T) {
+	t.Parallel()
+
+	childConfig := `
+locals {
+  id = uuid()
+}
+`
+	childPath := writeFunctionsTestConfigs(t, partialParseCacheTestRootConfig, childConfig)
+	cacheDir := t.TempDir()
+	opts := partialParseCacheTestOptions(t, childPath, cacheDir)
+
+	partialParseWithDiskCache(t, opts)
+	cacheFiles, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, cacheFiles)
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config/hclparse"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+const (
+	// TerragruntRCFileName is the name of the file, looked up in the directory of the config and its parents, where
+	// the plugins available to all the configs below it are declared.
+	TerragruntRCFileName = ".terragruntrc"
+
+	// PluginProtocolVersion is the version of the protocol spoken with the plugins, sent in every request.
+	PluginProtocolVersion = 1
+
+	pluginMethodDescribe = "describe"
+	pluginMethodCall     = "call"
+)
+
+// PluginConfig is a plugin declared with a plugin block: an executable that serves functions to the configs.
+type PluginConfig struct {
+	Name    string   `hcl:"name,label"`
+	Command string   `hcl:"command,attr"`
+	Args    []string `hcl:"args,optional"`
+
+	// dir is the directory of the file the plugin is declared in, which relative commands are resolved against and
+	// the plugin runs in.
+	dir string
+}
+
+// terragruntPlugins is a struct that can be used to only decode the plugin blocks.
+type terragruntPlugins struct {
+	Plugins []PluginConfig `hcl:"plugin,block"`
+	Remain  hcl.Body       `hcl:",remain"`
+}
+
+// terragruntRCFile is the content of a .terragruntrc file.
+type terragruntRCFile struct {
+	Plugins []PluginConfig `hcl:"plugin,block"`
+}
+
+// pluginRequest is a request sent to a plugin on stdin.
+type pluginRequest struct {
+	Version  int               `json:"version"`
+	Method   string            `json:"method"`
+	Function string            `json:"function,omitempty"`
+	Args     []json.RawMessage `json:"args,omitempty"`
+}
+
+// pluginDescribeResponse is the response of a plugin to the describe method.
+type pluginDescribeResponse struct {
+	Functions []pluginFunctionSpec `json:"functions"`
+}
+
+// pluginFunctionSpec describes a function served by a plugin. Types are HCL type constraints, e.g. list(string).
+type pluginFunctionSpec struct {
+	Name          string            `json:"name"`
+	Params        []pluginParamSpec `json:"params"`
+	VariadicParam *pluginParamSpec  `json:"variadic_param,omitempty"`
+	ReturnType    string            `json:"return_type"`
+}
+
+type pluginParamSpec struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// pluginCallResponse is the response of a plugin to the call method: either the result, or an error.
+type pluginCallResponse struct {
+	Result json.RawMessage      `json:"result"`
+	Error  *pluginErrorResponse `json:"error,omitempty"`
+}
+
+// pluginErrorResponse is an error returned by a plugin function. If Argument is set, the error is reported on the
+// argument with that index.
+type pluginErrorResponse struct {
+	Message  string `json:"message"`
+	Argument *int   `json:"argument,omitempty"`
+}
+
+// The responses of the plugins are cached for the duration of the run, so that each plugin is described once, and
+// each function called once with the same arguments.
+var pluginDescribeCache = NewStringCache()
+var pluginCallCache = NewStringCache()
+
+// decodePluginFunctions decodes the plugin blocks of the given file and returns the functions their plugins serve.
+func decodePluginFunctions(ctx *ParsingContext, file *hclparse.File) (map[string]function.Function, error) {
+	evalCtx, err := createTerragruntEvalContext(ctx, file.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := terragruntPlugins{}
+	if err := file.Decode(&decoded, evalCtx); err != nil {
+		return nil, err
+	}
+
+	return pluginsFunctions(ctx, file.ConfigPath, decoded.Plugins)
+}
+
+// findTerragruntRCPluginFunctions returns the functions served by the plugins of the closest .terragruntrc file in
+// the directory of the given config or its parents, if there is one.
+func findTerragruntRCPluginFunctions(ctx *ParsingContext, configPath string) (map[string]function.Function, error) {
+	dir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	for i := 0; i < ctx.TerragruntOptions.MaxFoldersToCheck; i++ {
+		rcPath := filepath.Join(dir, TerragruntRCFileName)
+		ctx.ReadRecorder.recordFile(rcPath)
+		if util.FileExists(rcPath) {
+			return parseTerragruntRCPluginFunctions(ctx, rcPath)
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			break
+		}
+		dir = parentDir
+	}
+	return nil, nil
+}
+
+// parseTerragruntRCPluginFunctions parses the given .terragruntrc file and returns the functions its plugins serve.
+func parseTerragruntRCPluginFunctions(ctx *ParsingContext, rcPath string) (map[string]function.Function, error) {
+	file, err := hclparse.NewParser().WithOptions(ctx.ParserOptions...).ParseFromFile(rcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	evalCtx, err := createTerragruntEvalContext(ctx.WithFunctions(nil), rcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := terragruntRCFile{}
+	if err := file.Decode(&decoded, evalCtx); err != nil {
+		return nil, err
+	}
+
+	return pluginsFunctions(ctx, rcPath, decoded.Plugins)
+}
+
+// pluginsFunctions returns the functions served by the given plugins, declared in the file at the given path. Two
+// plugins of the same file can not serve functions with the same name.
+func pluginsFunctions(ctx *ParsingContext, declaredIn string, plugins []PluginConfig) (map[string]function.Function, error) {
+	// The plugins are not run during a static analysis, so the configs calling their functions can not be evaluated.
+	if ctx.StaticAnalysis {
+		return map[string]function.Function{}, nil
+	}
+
+	functions := map[string]function.Function{}
+	servedBy := map[string]string{}
+	for _, plugin := range plugins {
+		plugin.dir = filepath.Dir(declaredIn)
+
+		specs, err := describePlugin(ctx, plugin)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, spec := range specs {
+			if otherPlugin, found := servedBy[spec.Name]; found {
+				return nil, errors.WithStackTrace(DuplicatePluginFunctionError{Function: spec.Name, Plugins: []string{otherPlugin, plugin.Name}, Path: declaredIn})
+			}
+			fn, err := newPluginFunction(ctx, plugin, spec)
+			if err != nil {
+				return nil, err
+			}
+			functions[spec.Name] = fn
+			servedBy[spec.Name] = plugin.Name
+		}
+	}
+	return functions, nil
+}
+
+// describePlugin asks the plugin for the functions it serves.
+func describePlugin(ctx *ParsingContext, plugin PluginConfig) ([]pluginFunctionSpec, error) {
+	output, err := runPlugin(ctx, plugin, pluginRequest{Version: PluginProtocolVersion, Method: pluginMethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+
+	response := pluginDescribeResponse{}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, errors.WithStackTrace(PluginError{Plugin: plugin.Name, Err: fmt.Errorf("invalid describe response: %w", err)})
+	}
+	return response.Functions, nil
+}
+
+// newPluginFunction creates the function that calls the given function of the plugin. The arguments are converted to
+// the types of the parameters before they are sent, and the result is converted to the return type.
+func newPluginFunction(ctx *ParsingContext, plugin PluginConfig, spec pluginFunctionSpec) (function.Function, error) {
+	parseType := func(typeExpr string) (cty.Type, error) {
+		varType, err := parsePluginType(typeExpr)
+		if err != nil {
+			return cty.NilType, errors.WithStackTrace(PluginError{Plugin: plugin.Name, Err: fmt.Errorf("invalid type %q in function %s: %w", typeExpr, spec.Name, err)})
+		}
+		return varType, nil
+	}
+
+	funcSpec := &function.Spec{}
+	for _, param := range spec.Params {
+		paramType, err := parseType(param.Type)
+		if err != nil {
+			return function.Function{}, err
+		}
+		funcSpec.Params = append(funcSpec.Params, function.Parameter{Name: param.Name, Type: paramType})
+	}
+	if spec.VariadicParam != nil {
+		paramType, err := parseType(spec.VariadicParam.Type)
+		if err != nil {
+			return function.Function{}, err
+		}
+		funcSpec.VarParam = &function.Parameter{Name: spec.VariadicParam.Name, Type: paramType}
+	}
+
+	returnType, err := parseType(spec.ReturnType)
+	if err != nil {
+		return function.Function{}, err
+	}
+	funcSpec.Type = function.StaticReturnType(returnType)
+	funcSpec.Impl = func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return callPluginFunction(ctx, plugin, spec.Name, args, retType)
+	}
+
+	return function.New(funcSpec), nil
+}
+
+// callPluginFunction calls the function of the plugin with the given arguments, and returns its result converted to
+// the given type. An error returned by the plugin for one of the arguments is reported on that argument.
+func callPluginFunction(ctx *ParsingContext, plugin PluginConfig, name string, args []cty.Value, retType cty.Type) (cty.Value, error) {
+	ctx.ReadRecorder.markUncacheable(fmt.Sprintf("it calls the function %s of the plugin %s", name, plugin.Name))
+	request := pluginRequest{Version: PluginProtocolVersion, Method: pluginMethodCall, Function: name}
+	for _, arg := range args {
+		argJSON, err := ctyjson.Marshal(arg, arg.Type())
+		if err != nil {
+			return cty.NilVal, errors.WithStackTrace(err)
+		}
+		request.Args = append(request.Args, argJSON)
+	}
+
+	output, err := runPlugin(ctx, plugin, request)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	response := pluginCallResponse{}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return cty.NilVal, errors.WithStackTrace(PluginError{Plugin: plugin.Name, Err: fmt.Errorf("invalid response from function %s: %w", name, err)})
+	}
+	if response.Error != nil {
+		if response.Error.Argument != nil && *response.Error.Argument >= 0 && *response.Error.Argument < len(args) {
+			return cty.NilVal, function.NewArgErrorf(*response.Error.Argument, "%s", response.Error.Message)
+		}
+		return cty.NilVal, errors.WithStackTrace(PluginFunctionError{Plugin: plugin.Name, Function: name, Message: response.Error.Message})
+	}
+
+	var result cty.Value
+	if retType == cty.DynamicPseudoType {
+		var simple ctyjson.SimpleJSONValue
+		err = simple.UnmarshalJSON(response.Result)
+		result = simple.Value
+	} else {
+		result, err = ctyjson.Unmarshal(response.Result, retType)
+	}
+	if err != nil {
+		return cty.NilVal, errors.WithStackTrace(PluginError{Plugin: plugin.Name, Err: fmt.Errorf("result of function %s does not match its return type: %w", name, err)})
+	}
+	return result, nil
+}
+
+// runPlugin runs the plugin with the request on stdin, and returns what it writes to stdout. The responses are cached
+// for the run, by plugin and request.
+func runPlugin(ctx *ParsingContext, plugin PluginConfig, request pluginRequest) ([]byte, error) {
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	cache := pluginCallCache
+	if request.Method == pluginMethodDescribe {
+		cache = pluginDescribeCache
+	}
+	cacheKey := fmt.Sprintf("%v-%v-%v-%s", plugin.dir, plugin.Command, plugin.Args, requestJSON)
+	if cached, found := cache.Get(cacheKey); found {
+		ctx.TerragruntOptions.Logger.Debugf("Using the cached response of plugin %s to %s", plugin.Name, requestJSON)
+		return []byte(cached), nil
+	}
+
+	command := plugin.Command
+	if strings.ContainsRune(command, '/') && !filepath.IsAbs(command) {
+		command = filepath.Join(plugin.dir, command)
+	}
+
+	ctx.TerragruntOptions.Logger.Debugf("Sending %s to plugin %s", requestJSON, plugin.Name)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, plugin.Args...)
+	cmd.Dir = plugin.dir
+	cmd.Env = os.Environ()
+	for key, value := range ctx.TerragruntOptions.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Stdin = bytes.NewReader(requestJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, errors.WithStackTrace(PluginError{Plugin: plugin.Name, Err: err})
+	}
+
+	cache.Put(cacheKey, stdout.String())
+	return stdout.Bytes(), nil
+}
+
+// parsePluginType parses a type constraint of a plugin function, e.g. list(string).
+func parsePluginType(typeExpr string) (cty.Type, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(typeExpr), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilType, diags
+	}
+	varType, diags := typeexpr.TypeConstraint(expr)
+	if diags.HasErrors() {
+		return cty.NilType, diags
+	}
+	return varType, nil
+}
@@ -0,0 +1,169 @@
+//go:build !windows
+// +build !windows
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+// pluginTestScript is a plugin serving the host_info function, which returns the owner and port of a host, and fails
+// on its argument for unknown hosts. Every request it receives is logged to requests.log.
+const pluginTestScript = `#!/bin/sh
+request=$(cat)
+echo "$request" >> requests.log
+case "$request" in
+  *'"method":"describe"'*)
+    echo '{"functions":[{"name":"host_info","params":[{"name":"host","type":"string"}],"return_type":"object({owner=string,port=number})"}]}'
+    ;;
+  *'"args":["web"]'*)
+    echo '{"result":{"owner":"platform","port":8080}}'
+    ;;
+  *)
+    echo '{"error":{"message":"unknown host","argument":0}}'
+    ;;
+esac
+`
+
+// writePluginTestConfig writes the plugin script and the given .terragruntrc and config, and returns the path of the
+// config.
+func writePluginTestConfig(t *testing.T, rcConfig string, config string) string {
+	files := map[string]string{
+		"plugin.sh":                            pluginTestScript,
+		"child/" + DefaultTerragruntConfigPath: config,
+	}
+	if rcConfig != "" {
+		files[TerragruntRCFileName] = rcConfig
+	}
+	rootDir := helpers.WriteTempFiles(t, files)
+	require.NoError(t, os.Chmod(filepath.Join(rootDir, "plugin.sh"), 0755))
+	return filepath.Join(rootDir, "child", DefaultTerragruntConfigPath)
+}
+
+func TestParseConfigWithPluginFunctionsFromTerragruntRC(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePluginTestConfig(t, `
+plugin "cmdb" {
+  command = "./plugin.sh"
+}
+`, `
+locals {
+  web = host_info("web")
+}
+
+inputs = {
+  owner = local.web.owner
+  port  = host_info("web").port
+}
+`)
+	opts := terragruntOptionsForTest(t, configPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), configPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "platform", cfg.Inputs["owner"])
+	assert.Equal(t, float64(8080), cfg.Inputs["port"])
+
+	// The plugin is described once, and the function called once, even if the config is decoded several times.
+	requests, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(configPath)), "requests.log"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"version":1,"method":"describe"}`,
+		`{"version":1,"method":"call","function":"host_info","args":["web"]}`,
+	}, strings.Split(strings.TrimSpace(string(requests)), "\n"))
+}
+
+func TestPluginDescribedOncePerRun(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePluginTestConfig(t, `
+plugin "cmdb" {
+  command = "./plugin.sh"
+}
+`, `
+inputs = {
+  owner = host_info("web").owner
+}
+`)
+	rootDir := filepath.Dir(filepath.Dir(configPath))
+	otherConfigPath := filepath.Join(rootDir, "other", DefaultTerragruntConfigPath)
+	helpers.WriteFiles(t, rootDir, map[string]string{
+		"other/" + DefaultTerragruntConfigPath: `
+inputs = {
+  port = host_info("web").port
+}
+`,
+	})
+
+	// The configs of the run share the plugin of the .terragruntrc file, which is described by the first one parsed.
+	for _, path := range []string{configPath, otherConfigPath, configPath} {
+		opts := terragruntOptionsForTest(t, path)
+		_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), path, nil)
+		require.NoError(t, err)
+	}
+
+	requests, err := os.ReadFile(filepath.Join(rootDir, "requests.log"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(requests), `"method":"describe"`))
+}
+
+func TestParseConfigWithPluginBlock(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePluginTestConfig(t, "", `
+plugin "cmdb" {
+  command = "../plugin.sh"
+}
+
+inputs = {
+  owner = host_info("web").owner
+}
+`)
+	opts := terragruntOptionsForTest(t, configPath)
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), configPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "platform", cfg.Inputs["owner"])
+}
+
+func TestParseConfigWithPluginFunctionErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{"ErrorOnArgument", `host_info("db")`, `Invalid value for "host" parameter: unknown host`},
+		{"ArgumentOfWrongType", `host_info(["web"])`, "string required"},
+		{"WrongNumberOfArguments", `host_info()`, "Not enough function arguments"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			configPath := writePluginTestConfig(t, `
+plugin "cmdb" {
+  command = "./plugin.sh"
+}
+`, "inputs = {\n  host = "+testCase.input+"\n}\n")
+			opts := terragruntOptionsForTest(t, configPath)
+
+			_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), configPath, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
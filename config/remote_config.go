@@ -0,0 +1,324 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-getter"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/terraform"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// remoteConfigSourceRegexp matches the go-getter sources that are fetched rather than read from the local file system:
+// the ones forcing a getter, e.g. `git::`, and the URLs, e.g. `https://`. The scheme is at least two characters long, so
+// that Windows paths such as `C:/` are not taken for URLs.
+var remoteConfigSourceRegexp = regexp.MustCompile(`^([A-Za-z0-9]+::|[A-Za-z][A-Za-z0-9+.-]+://)`)
+
+// remoteConfigCommitRegexp matches the full SHA-1 or SHA-256 hash of a commit, which the `ref` or `rev` of a source
+// must be to pin it: the branches and the tags can be moved to another commit.
+var remoteConfigCommitRegexp = regexp.MustCompile(`^([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+
+// remoteConfigVersionRegexp matches an exact semantic version, which the `version` of a source must be to pin it,
+// unlike the version constraints such as `~> 1.0`.
+var remoteConfigVersionRegexp = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// remoteConfigPaths caches the local paths of the remote configs fetched by this process, by cache directory and source.
+var remoteConfigPaths sync.Map
+
+// remoteConfigIndexEntry is the content of an index file of the remote config cache. Each source has its own index
+// file, pointing to the directory of its content in the cache.
+type remoteConfigIndexEntry struct {
+	Source      string `json:"source"`
+	ContentHash string `json:"content_hash"`
+	Path        string `json:"path"`
+}
+
+// isRemoteConfigPath returns true if the given include or read_terragrunt_config path is a go-getter source to fetch.
+func isRemoteConfigPath(path string) bool {
+	return remoteConfigSourceRegexp.MatchString(path) && !strings.HasPrefix(path, "file://")
+}
+
+// resolveRemoteConfigPath returns the local path of the config at the given path. Remote configs are fetched in the
+// cache configured with --terragrunt-remote-config-cache-dir first, while the local paths are returned unchanged.
+func resolveRemoteConfigPath(ctx *ParsingContext, path string) (string, error) {
+	if !isRemoteConfigPath(path) {
+		return path, nil
+	}
+	return fetchRemoteConfig(ctx, path)
+}
+
+// resolveRemoteIncludes replaces the remote paths of the given include blocks by the local paths of the fetched
+// configs, keeping the remote paths in RemoteSource.
+func resolveRemoteIncludes(ctx *ParsingContext, includes []IncludeConfig) ([]IncludeConfig, error) {
+	for i := range includes {
+		if !isRemoteConfigPath(includes[i].Path) {
+			continue
+		}
+
+		localPath, err := fetchRemoteConfig(ctx, includes[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		includes[i].RemoteSource = includes[i].Path
+		includes[i].Path = localPath
+	}
+	return includes, nil
+}
+
+// fetchRemoteConfig fetches the config at the given go-getter source, unless it is already in the cache, and returns
+// its local path. The source must be pinned, as its content is never fetched again once cached. The content of each
+// source is stored in a directory named after its hash, so that the sources with the same content share it, and the
+// other files of the source, e.g. the ones next to the config, are available as well.
+func fetchRemoteConfig(ctx *ParsingContext, source string) (string, error) {
+	terragruntOptions := ctx.TerragruntOptions
+
+	if !isPinnedRemoteConfigSource(source) {
+		return "", errors.WithStackTrace(UnpinnedRemoteConfigError{Source: source})
+	}
+
+	cacheDir := remoteConfigCacheDir(terragruntOptions)
+
+	memoKey := cacheDir + "|" + source
+	if localPath, found := remoteConfigPaths.Load(memoKey); found {
+		return localPath.(string), nil
+	}
+
+	sourceHash := sha256.Sum256([]byte(source))
+	indexPath := filepath.Join(cacheDir, "sources", hex.EncodeToString(sourceHash[:])+".json")
+	if err := util.EnsureDirectory(filepath.Dir(indexPath)); err != nil {
+		return "", err
+	}
+
+	unlock, err := lockFile(indexPath + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	localPath, isCached := readRemoteConfigIndex(terragruntOptions, cacheDir, indexPath, source)
+	if !isCached {
+		terragruntOptions.Logger.Debugf("Fetching the remote config %s in %s.", source, cacheDir)
+
+		entry, err := downloadRemoteConfig(ctx, cacheDir, source)
+		if err != nil {
+			return "", errors.WithStackTrace(RemoteConfigFetchError{Source: source, Err: err})
+		}
+
+		contents, err := json.Marshal(entry)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+		if err := writeCacheFile(indexPath, contents); err != nil {
+			return "", err
+		}
+		localPath = remoteConfigLocalPath(cacheDir, *entry)
+	}
+
+	remoteConfigPaths.Store(memoKey, localPath)
+	return localPath, nil
+}
+
+// isPinnedRemoteConfigSource returns true if the given go-getter source is pinned to content that can't change: its
+// `ref` or `rev` is a full commit hash, its `version` an exact semantic version, or it has a `checksum`.
+func isPinnedRemoteConfigSource(source string) bool {
+	_, rawQuery, found := strings.Cut(source, "?")
+	if !found {
+		return false
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return false
+	}
+	return remoteConfigCommitRegexp.MatchString(query.Get("ref")) ||
+		remoteConfigCommitRegexp.MatchString(query.Get("rev")) ||
+		remoteConfigVersionRegexp.MatchString(query.Get("version")) ||
+		query.Get("checksum") != ""
+}
+
+// remoteConfigCacheDir returns the absolute path of the remote config cache directory.
+func remoteConfigCacheDir(terragruntOptions *options.TerragruntOptions) string {
+	cacheDir := terragruntOptions.RemoteConfigCacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			userCacheDir = os.TempDir()
+		}
+		return filepath.Join(userCacheDir, "terragrunt", "remote-configs")
+	}
+	if !filepath.IsAbs(cacheDir) {
+		cacheDir = util.JoinPath(terragruntOptions.WorkingDir, cacheDir)
+	}
+	return cacheDir
+}
+
+// readRemoteConfigIndex returns the local path of the config of the given source from its index file, if the source
+// was already fetched and its content is still in the cache.
+func readRemoteConfigIndex(terragruntOptions *options.TerragruntOptions, cacheDir string, indexPath string, source string) (string, bool) {
+	contents, err := os.ReadFile(indexPath)
+	if err != nil {
+		return "", false
+	}
+
+	entry := remoteConfigIndexEntry{}
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		terragruntOptions.Logger.Debugf("Ignoring the invalid remote config cache index %s: %v", indexPath, err)
+		return "", false
+	}
+	if entry.Source != source {
+		return "", false
+	}
+
+	localPath := remoteConfigLocalPath(cacheDir, entry)
+	if !util.FileExists(localPath) {
+		return "", false
+	}
+	return localPath, true
+}
+
+// remoteConfigLocalPath returns the local path of the config of the given index entry.
+func remoteConfigLocalPath(cacheDir string, entry remoteConfigIndexEntry) string {
+	return filepath.Join(cacheDir, "content", entry.ContentHash, filepath.FromSlash(entry.Path))
+}
+
+// downloadRemoteConfig fetches the given source in a temporary directory of the cache, and moves it to the directory
+// named after the hash of its content.
+func downloadRemoteConfig(ctx *ParsingContext, cacheDir string, source string) (*remoteConfigIndexEntry, error) {
+	// The subdirectory of the source is the path of the config in its content, which may be a file.
+	sourceURL, subDir := getter.SourceDirSubdir(source)
+
+	tmpDir, err := os.MkdirTemp(cacheDir, "fetch-")
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The getters expect the destination directory not to exist yet.
+	downloadDir := filepath.Join(tmpDir, "content")
+	client := &getter.Client{
+		Ctx:           ctx,
+		Src:           sourceURL,
+		Dst:           downloadDir,
+		Pwd:           filepath.Dir(ctx.TerragruntOptions.TerragruntConfigPath),
+		Mode:          getter.ClientModeAny,
+		Detectors:     getter.Detectors,
+		Decompressors: getter.Decompressors,
+		Getters:       remoteConfigGetters(),
+	}
+	if err := client.Get(); err != nil {
+		return nil, err
+	}
+
+	configPath, err := remoteConfigPathInContent(downloadDir, subDir)
+	if err != nil {
+		return nil, err
+	}
+
+	contentHash, err := hashDirectory(downloadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	contentDir := filepath.Join(cacheDir, "content", contentHash)
+	if err := util.EnsureDirectory(filepath.Dir(contentDir)); err != nil {
+		return nil, err
+	}
+	// Another source with the same content may already be in the cache.
+	if !util.IsDir(contentDir) {
+		if err := os.Rename(downloadDir, contentDir); err != nil && !util.IsDir(contentDir) {
+			return nil, errors.WithStackTrace(err)
+		}
+	}
+
+	return &remoteConfigIndexEntry{Source: source, ContentHash: contentHash, Path: filepath.ToSlash(configPath)}, nil
+}
+
+// remoteConfigGetters returns the go-getter getters used to fetch the remote configs: the default ones, and the
+// Terraform registry getter for the `tfr://` sources. The map is copied, as the default one is shared globally.
+func remoteConfigGetters() map[string]getter.Getter {
+	getters := map[string]getter.Getter{}
+	for getterName, getterValue := range getter.Getters {
+		getters[getterName] = getterValue
+	}
+	getters["tfr"] = &terraform.RegistryGetter{}
+	return getters
+}
+
+// remoteConfigPathInContent returns the path of the config relative to the given fetched content: the given
+// subdirectory, or if it is empty, the only file fetched, e.g. from an `https://` source. The default config file is
+// used in the directories.
+func remoteConfigPathInContent(downloadDir string, subDir string) (string, error) {
+	if subDir == "" {
+		entries, err := os.ReadDir(downloadDir)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+		if len(entries) == 1 && entries[0].Type().IsRegular() {
+			return entries[0].Name(), nil
+		}
+	}
+
+	configPath := GetDefaultConfigPath(filepath.Join(downloadDir, filepath.FromSlash(subDir)))
+	if !util.FileExists(configPath) {
+		return "", errors.WithStackTrace(TerragruntConfigNotFoundError{Path: configPath})
+	}
+	return filepath.Rel(downloadDir, configPath)
+}
+
+// hashDirectory returns a hash of the paths and contents of the files of the given directory, ignoring the `.git`
+// directories, so that it identifies the content fetched whatever the getter used.
+func hashDirectory(dir string) (string, error) {
+	paths := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if entry.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, path := range paths {
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+		fileHash := sha256.New()
+		_, err = io.Copy(fileHash, file)
+		file.Close()
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+
+		fmt.Fprintf(hash, "%s\x00%x\n", filepath.ToSlash(relPath), fileHash.Sum(nil))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+func TestIsRemoteConfigPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"git::git@github.com:acme/infra.git//root.hcl?ref=v1", true},
+		{"git::https://github.com/acme/infra.git//root.hcl?ref=v1", true},
+		{"s3::https://s3.amazonaws.com/acme/root.hcl?version=1", true},
+		{"https://example.com/root.hcl?checksum=sha256:abc", true},
+		{"tfr://registry.terraform.io/acme/root/aws?version=1.0.0", true},
+		{"../root.hcl", false},
+		{"/infra/root.hcl", false},
+		{"C:/infra/root.hcl", false},
+		{"file:///infra/root.hcl", false},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, isRemoteConfigPath(testCase.path), testCase.path)
+	}
+}
+
+// createRemoteConfigTestRepos creates a git repo with the given files, as the remote source of the configs, and a git
+// repo for the child config, in its app directory. It returns the remote repo, the hash of its commit and the child
+// config path.
+func createRemoteConfigTestRepos(t *testing.T, remoteFiles map[string]string) (string, string, string) {
+	helpers.SkipWithoutGit(t)
+
+	remoteRepo := helpers.WriteTempFiles(t, remoteFiles)
+	helpers.RunGit(t, remoteRepo, "init", "--quiet")
+	helpers.RunGit(t, remoteRepo, "add", "-A")
+	helpers.RunGit(t, remoteRepo, "commit", "--quiet", "-m", "initial")
+
+	childRepo, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	helpers.RunGit(t, childRepo, "init", "--quiet")
+	childPath := filepath.Join(childRepo, "app", DefaultTerragruntConfigPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(childPath), os.ModePerm))
+
+	return remoteRepo, remoteConfigTestCommit(t, remoteRepo), childPath
+}
+
+// remoteConfigTestCommit returns the hash of the commit checked out in the given repo.
+func remoteConfigTestCommit(t *testing.T, repo string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repo
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func TestParseConfigWithRemoteInclude(t *testing.T) {
+	t.Parallel()
+
+	rootConfig := `
+locals {
+  common = read_terragrunt_config("${get_parent_terragrunt_dir()}/common.hcl")
+}
+
+inputs = {
+  state_key  = "${path_relative_to_include()}/terraform.tfstate"
+  to_root    = path_relative_from_include()
+  parent_dir = get_parent_terragrunt_dir()
+  region     = local.common.locals.region
+}
+`
+	remoteRepo, commit, childPath := createRemoteConfigTestRepos(t, map[string]string{
+		"root.hcl":   rootConfig,
+		"common.hcl": `locals { region = "us-east-1" }`,
+	})
+	childConfig := `
+include "root" {
+  path = "git::file://` + filepath.ToSlash(remoteRepo) + `//root.hcl?ref=` + commit + `"
+}
+`
+	require.NoError(t, os.WriteFile(childPath, []byte(childConfig), 0644))
+
+	cacheDir := t.TempDir()
+	opts := terragruntOptionsForTest(t, childPath)
+	opts.RemoteConfigCacheDir = cacheDir
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+
+	// The paths relative to the remote config are relative to the root of the repo of the child config, while the
+	// remote config is read next to the other files of its source in the cache.
+	assert.Equal(t, "app/terraform.tfstate", cfg.Inputs["state_key"])
+	assert.Equal(t, "..", cfg.Inputs["to_root"])
+	assert.Equal(t, "us-east-1", cfg.Inputs["region"])
+	parentDir := cfg.Inputs["parent_dir"].(string)
+	assert.Equal(t, filepath.Join(cacheDir, "content"), filepath.Dir(filepath.FromSlash(parentDir)))
+	assert.FileExists(t, filepath.Join(parentDir, "common.hcl"))
+
+	include := cfg.ProcessedIncludes["root"]
+	assert.Equal(t, "git::file://"+filepath.ToSlash(remoteRepo)+"//root.hcl?ref="+commit, include.RemoteSource)
+	assert.Equal(t, filepath.Join(filepath.FromSlash(parentDir), "root.hcl"), include.Path)
+}
+
+func TestReadRemoteTerragruntConfig(t *testing.T) {
+	t.Parallel()
+
+	remoteRepo, initial, childPath := createRemoteConfigTestRepos(t, map[string]string{
+		"common.hcl": `locals { region = "us-east-1" }`,
+	})
+	helpers.RunGit(t, remoteRepo, "commit", "--quiet", "--allow-empty", "-m", "empty")
+	empty := remoteConfigTestCommit(t, remoteRepo)
+
+	source := "git::file://" + filepath.ToSlash(remoteRepo) + "//common.hcl"
+	childConfig := `
+locals {
+  initial = read_terragrunt_config("` + source + `?ref=` + initial + `")
+  empty   = read_terragrunt_config("` + source + `?ref=` + empty + `")
+}
+
+inputs = {
+  region = local.initial.locals.region
+}
+`
+	require.NoError(t, os.WriteFile(childPath, []byte(childConfig), 0644))
+
+	cacheDir := t.TempDir()
+	opts := terragruntOptionsForTest(t, childPath)
+	opts.RemoteConfigCacheDir = cacheDir
+
+	cfg, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", cfg.Inputs["region"])
+
+	// Both sources have the same content, which is cached once.
+	sources, err := filepath.Glob(filepath.Join(cacheDir, "sources", "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, sources, 2)
+	contents, err := os.ReadDir(filepath.Join(cacheDir, "content"))
+	require.NoError(t, err)
+	assert.Len(t, contents, 1)
+}
+
+func TestIsPinnedRemoteConfigSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source   string
+		expected bool
+	}{
+		{"git::https://github.com/acme/infra.git//root.hcl?ref=5d6c9bbf0e2d4a8c1f3b7e9a0c2d4f6b8a1c3e5f", true},
+		{"hg::https://example.com/infra//root.hcl?rev=5d6c9bbf0e2d4a8c1f3b7e9a0c2d4f6b8a1c3e5f", true},
+		{"tfr://registry.terraform.io/acme/root/aws?version=1.0.0", true},
+		{"tfr://registry.terraform.io/acme/root/aws?version=v1.0.0-rc.1", true},
+		{"https://example.com/root.hcl?checksum=sha256:abc", true},
+		{"git::https://github.com/acme/infra.git//root.hcl", false},
+		{"git::https://github.com/acme/infra.git//root.hcl?ref=main", false},
+		{"git::https://github.com/acme/infra.git//root.hcl?ref=v1.0.0", false},
+		{"git::https://github.com/acme/infra.git//root.hcl?ref=5d6c9bb", false},
+		{"tfr://registry.terraform.io/acme/root/aws?version=~>1.0", false},
+		{"tfr://registry.terraform.io/acme/root/aws?version=1.0", false},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, isPinnedRemoteConfigSource(testCase.source), testCase.source)
+	}
+}
+
+func TestRemoteConfigMustBePinned(t *testing.T) {
+	t.Parallel()
+
+	for _, source := range []string{
+		"git::https://github.com/acme/infra.git//root.hcl",
+		"git::https://github.com/acme/infra.git//root.hcl?ref=main",
+	} {
+		childPath := writeFunctionsTestConfigs(t, "", `
+include "root" {
+  path = "`+source+`"
+}
+`)
+		opts := terragruntOptionsForTest(t, childPath)
+		opts.RemoteConfigCacheDir = t.TempDir()
+
+		_, err := ParseConfigFile(opts, NewParsingContext(context.Background(), opts), childPath, nil)
+		require.Error(t, err, source)
+
+		var unpinnedErr UnpinnedRemoteConfigError
+		require.ErrorAs(t, errors.Unwrap(err), &unpinnedErr, source)
+		assert.Equal(t, source, unpinnedErr.Source)
+	}
+}
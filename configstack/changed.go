@@ -0,0 +1,160 @@
+package configstack
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/gruntwork-io/terragrunt/terraform"
+)
+
+// flagUnaffectedModules flags as excluded all the modules that are not affected by the changes made since the git ref
+// specified via the terragrunt-changed-since CLI flag. Only the changed modules and the modules that depend on them
+// remain included: unlike with terragrunt-include-dir, the dependencies of the affected modules are not included.
+func flagUnaffectedModules(modules []*TerraformModule, terragruntOptions *options.TerragruntOptions) ([]*TerraformModule, error) {
+	if terragruntOptions.ChangedSince == "" {
+		return modules, nil
+	}
+
+	changedFiles, err := shell.GitChangedFiles(terragruntOptions, terragruntOptions.WorkingDir, terragruntOptions.ChangedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedModules := findAffectedModules(modules, changedFiles, terragruntOptions)
+
+	affectedCount := 0
+	for _, module := range modules {
+		module.FlagExcluded = module.FlagExcluded || !affectedModules[module.Path]
+		if !module.FlagExcluded {
+			affectedCount++
+		}
+	}
+
+	terragruntOptions.Logger.Infof("%d of %d modules are affected by the changes since %s", affectedCount, len(modules), terragruntOptions.ChangedSince)
+
+	return modules, nil
+}
+
+// findAffectedModules returns the paths of the modules affected by the given changed files, together with all the
+// modules that depend on them, directly or transitively. A module is affected if one of the changed files is in the
+// module directory (and not in the directory of a nested module), is read by the module config, or is part of the
+// local terraform source of the module.
+func findAffectedModules(modules []*TerraformModule, changedFiles []string, terragruntOptions *options.TerragruntOptions) map[string]bool {
+	changed := map[string]bool{}
+	for _, file := range changedFiles {
+		changed[file] = true
+	}
+
+	affected := map[string]bool{}
+
+	for _, file := range changedFiles {
+		if module := findDeepestModuleContaining(modules, file); module != nil {
+			affected[module.Path] = true
+		}
+	}
+
+	for _, module := range modules {
+		if affected[module.Path] {
+			continue
+		}
+
+		if isModuleConfigAffected(module, changed) || isModuleSourceAffected(module, changedFiles) {
+			affected[module.Path] = true
+		}
+	}
+
+	dependents := map[string][]*TerraformModule{}
+	for _, module := range modules {
+		for _, dependency := range module.Dependencies {
+			dependents[dependency.Path] = append(dependents[dependency.Path], module)
+		}
+	}
+
+	var flagDependents func(path string)
+	flagDependents = func(path string) {
+		for _, dependent := range dependents[path] {
+			if affected[dependent.Path] {
+				continue
+			}
+			terragruntOptions.Logger.Debugf("Module %s is affected because it depends on %s", dependent.Path, path)
+			affected[dependent.Path] = true
+			flagDependents(dependent.Path)
+		}
+	}
+
+	for _, module := range modules {
+		if affected[module.Path] {
+			flagDependents(module.Path)
+		}
+	}
+
+	return affected
+}
+
+// findDeepestModuleContaining returns the module with the longest path that contains the given file, or nil if the
+// file is not in any of the module directories.
+func findDeepestModuleContaining(modules []*TerraformModule, file string) *TerraformModule {
+	var deepest *TerraformModule
+	for _, module := range modules {
+		if !isPathUnder(file, module.Path) {
+			continue
+		}
+		if deepest == nil || len(module.Path) > len(deepest.Path) {
+			deepest = module
+		}
+	}
+	return deepest
+}
+
+// isModuleConfigAffected returns true if one of the files included or read by the module config changed. If the files
+// can not be determined, the module is assumed to be affected.
+func isModuleConfigAffected(module *TerraformModule, changed map[string]bool) bool {
+	terragruntOptions := module.TerragruntOptions
+
+	files, err := config.FindFilesReadByConfig(config.NewParsingContext(context.Background(), terragruntOptions), terragruntOptions.TerragruntConfigPath)
+	if err != nil {
+		terragruntOptions.Logger.Warnf("Could not determine the files read by %s, assuming the module is affected: %v", terragruntOptions.TerragruntConfigPath, err)
+		return true
+	}
+
+	for _, file := range files {
+		if changed[file] {
+			terragruntOptions.Logger.Debugf("Module %s is affected because %s changed", module.Path, file)
+			return true
+		}
+	}
+	return false
+}
+
+// isModuleSourceAffected returns true if the module uses a local terraform source that contains one of the changed
+// files.
+func isModuleSourceAffected(module *TerraformModule, changedFiles []string) bool {
+	if module.Config.Terraform == nil || module.Config.Terraform.Source == nil {
+		return false
+	}
+
+	sourceUrl, err := terraform.ToSourceUrl(*module.Config.Terraform.Source, module.Path)
+	if err != nil || !terraform.IsLocalSource(sourceUrl) {
+		return false
+	}
+
+	// The double-slash only marks the root of the copied folder, the module itself is the full path.
+	sourcePath := filepath.Clean(strings.Replace(sourceUrl.Path, "//", "/", 1))
+
+	for _, file := range changedFiles {
+		if isPathUnder(file, sourcePath) {
+			module.TerragruntOptions.Logger.Debugf("Module %s is affected because its source %s changed", module.Path, sourcePath)
+			return true
+		}
+	}
+	return false
+}
+
+// isPathUnder returns true if path is dir itself or is located inside of it.
+func isPathUnder(path string, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,152 @@
+package configstack
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createChangedTestStack creates a git repo with the stack below and commits it:
+//
+//	vpc   (source = ../modules/vpc)
+//	app   (depends on vpc)
+//	db    (reads common.hcl)
+//	other
+func createChangedTestStack(t *testing.T) (string, []*TerraformModule) {
+	helpers.SkipWithoutGit(t)
+
+	rootDir := helpers.WriteTempFiles(t, map[string]string{
+		"modules/vpc/main.tf":  `output "id" { value = "vpc" }`,
+		"common.hcl":           `locals { region = "us-east-1" }`,
+		"vpc/terragrunt.hcl":   `terraform { source = "../modules/vpc" }`,
+		"app/terragrunt.hcl":   `dependency "vpc" { config_path = "../vpc" }`,
+		"db/terragrunt.hcl":    `locals { common = read_terragrunt_config("${get_terragrunt_dir()}/../common.hcl") }`,
+		"other/terragrunt.hcl": ``,
+	})
+	helpers.RunGit(t, rootDir, "init", "--quiet")
+	helpers.RunGit(t, rootDir, "add", "-A")
+	helpers.RunGit(t, rootDir, "commit", "--quiet", "-m", "initial")
+
+	newModule := func(name string, dependencies ...*TerraformModule) *TerraformModule {
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, name, config.DefaultTerragruntConfigPath))
+		require.NoError(t, err)
+
+		return &TerraformModule{
+			Path:              filepath.Join(rootDir, name),
+			Dependencies:      dependencies,
+			TerragruntOptions: opts,
+		}
+	}
+
+	vpc := newModule("vpc")
+	source := "../modules/vpc"
+	vpc.Config.Terraform = &config.TerraformConfig{Source: &source}
+
+	app := newModule("app", vpc)
+	db := newModule("db")
+	other := newModule("other")
+
+	return rootDir, []*TerraformModule{vpc, app, db, other}
+}
+
+func flagUnaffectedTestModules(t *testing.T, rootDir string, modules []*TerraformModule) map[string]bool {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.ChangedSince = "HEAD"
+
+	modules, err = flagUnaffectedModules(modules, opts)
+	require.NoError(t, err)
+
+	included := map[string]bool{}
+	for _, module := range modules {
+		included[filepath.Base(module.Path)] = !module.FlagExcluded
+	}
+	return included
+}
+
+func TestFlagUnaffectedModulesLocalSourceChanged(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"modules/vpc/main.tf": `output "id" { value = "changed" }`})
+
+	expected := map[string]bool{"vpc": true, "app": true, "db": false, "other": false}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesDependencyNotIncluded(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"app/terragrunt.hcl": `dependency "vpc" { config_path = "../vpc" }
+inputs = { name = "app" }`})
+
+	// vpc is an upstream dependency of the changed app module, which it is not affected by.
+	expected := map[string]bool{"vpc": false, "app": true, "db": false, "other": false}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesReadConfigChanged(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"common.hcl": `locals { region = "eu-west-1" }`})
+
+	expected := map[string]bool{"vpc": false, "app": false, "db": true, "other": false}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesUntrackedFileInModule(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"other/extra.tf": `locals {}`})
+
+	expected := map[string]bool{"vpc": false, "app": false, "db": false, "other": true}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesNoChanges(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+
+	expected := map[string]bool{"vpc": false, "app": false, "db": false, "other": false}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesFileMoved(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"other/extra.tf": `locals { extra = true }`})
+	helpers.RunGit(t, rootDir, "add", "-A")
+	helpers.RunGit(t, rootDir, "commit", "--quiet", "-m", "extra")
+	helpers.RunGit(t, rootDir, "mv", "other/extra.tf", "db/extra.tf")
+
+	// The old path of the moved file is reported as changed, even though git detects the move as a rename.
+	expected := map[string]bool{"vpc": false, "app": false, "db": true, "other": true}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
+
+func TestFlagUnaffectedModulesSpecialCharactersInPath(t *testing.T) {
+	t.Parallel()
+
+	rootDir, modules := createChangedTestStack(t)
+	helpers.WriteFiles(t, rootDir, map[string]string{"db/extra é.tf": `locals {}`})
+	helpers.RunGit(t, rootDir, "add", "-A")
+	helpers.RunGit(t, rootDir, "commit", "--quiet", "-m", "extra")
+	helpers.WriteFiles(t, rootDir, map[string]string{
+		"db/extra é.tf":     `locals { changed = true }`,
+		"other/new file.tf": `locals {}`,
+	})
+
+	// The paths quoted by git's core.quotePath, in the diff and in the untracked files, are matched.
+	expected := map[string]bool{"vpc": false, "app": false, "db": true, "other": true}
+	assert.Equal(t, expected, flagUnaffectedTestModules(t, rootDir, modules))
+}
@@ -0,0 +1,88 @@
+package configstack
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// concurrencyLimits holds the semaphores that limit how many modules of a run execute at the same time: the global one
+// set with --terragrunt-parallelism, which hands out its slots by priority, and one per value of every label with a
+// limit set with --terragrunt-label-parallelism. A module only runs once it holds a slot in all the semaphores that
+// apply to it.
+type concurrencyLimits struct {
+	global           *moduleScheduler
+	labelParallelism map[string]int
+
+	mutex           sync.Mutex
+	labelSemaphores map[string]chan struct{}
+}
+
+// newConcurrencyLimits creates the limits for a run with the given global and per-label parallelism.
+func newConcurrencyLimits(parallelism int, labelParallelism map[string]int) (*concurrencyLimits, error) {
+	for label, limit := range labelParallelism {
+		if limit < 1 {
+			return nil, errors.WithStackTrace(InvalidLabelParallelism{Label: label, Limit: limit})
+		}
+	}
+
+	return &concurrencyLimits{
+		global:           newModuleScheduler(parallelism),
+		labelParallelism: labelParallelism,
+		labelSemaphores:  map[string]chan struct{}{},
+	}, nil
+}
+
+// acquire blocks until the given module can run without exceeding any of the limits, and returns the function that
+// releases the slots it took.
+func (limits *concurrencyLimits) acquire(module *runningModule) func() {
+	semaphores := limits.labelSemaphoresFor(module.Module)
+
+	// A module that waits for a label semaphore must not hold back the modules that wait for the global one.
+	if len(semaphores) > 0 {
+		limits.global.arrive(module)
+	}
+
+	// The label semaphores are always taken in the same order, and before the global one, so that two modules can never
+	// hold a slot the other one is waiting for.
+	for _, semaphore := range semaphores {
+		semaphore <- struct{}{}
+	}
+	limits.global.acquire(module) // Will block if parallelism limit is met
+
+	return func() {
+		limits.global.release()
+		for i := len(semaphores) - 1; i >= 0; i-- {
+			<-semaphores[i]
+		}
+	}
+}
+
+// labelSemaphoresFor returns the semaphores of all the limited labels of the module, sorted by label.
+func (limits *concurrencyLimits) labelSemaphoresFor(module *TerraformModule) []chan struct{} {
+	labels := []string{}
+	for label := range module.Config.Labels {
+		if _, isLimited := limits.labelParallelism[label]; isLimited {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	limits.mutex.Lock()
+	defer limits.mutex.Unlock()
+
+	semaphores := make([]chan struct{}, 0, len(labels))
+	for _, label := range labels {
+		key := fmt.Sprintf("%s=%s", label, module.Config.Labels[label])
+		semaphore, ok := limits.labelSemaphores[key]
+		if !ok {
+			semaphore = make(chan struct{}, limits.labelParallelism[label])
+			limits.labelSemaphores[key] = semaphore
+		}
+		semaphores = append(semaphores, semaphore)
+	}
+
+	return semaphores
+}
@@ -0,0 +1,76 @@
+package configstack
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunModulesLabelParallelism(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	running := map[string]int{}
+	maxRunning := map[string]int{}
+	totalRunning, maxTotalRunning := 0, 0
+
+	modules := []*TerraformModule{}
+	for i := 0; i < 8; i++ {
+		account := fmt.Sprintf("account-%d", i%2)
+		labels := map[string]string{"account": account}
+		if i < 2 {
+			// The team label has no limit, so it does not affect the scheduling.
+			labels["team"] = "platform"
+		}
+
+		executed := false
+		opts := optionsWithMockTerragruntCommand(t, fmt.Sprintf("module-%d", i), nil, &executed)
+		opts.RunTerragrunt = func(_ *options.TerragruntOptions) error {
+			mutex.Lock()
+			running[account]++
+			totalRunning++
+			maxRunning[account] = max(maxRunning[account], running[account])
+			maxTotalRunning = max(maxTotalRunning, totalRunning)
+			mutex.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mutex.Lock()
+			running[account]--
+			totalRunning--
+			mutex.Unlock()
+			return nil
+		}
+
+		modules = append(modules, &TerraformModule{
+			Path:              fmt.Sprintf("module-%d", i),
+			Dependencies:      []*TerraformModule{},
+			Config:            config.TerragruntConfig{Labels: labels},
+			TerragruntOptions: opts,
+		})
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	opts.LabelParallelism = map[string]int{"account": 2}
+
+	require.NoError(t, RunModules(opts, modules, 3))
+
+	assert.Equal(t, map[string]int{"account-0": 2, "account-1": 2}, maxRunning)
+	assert.LessOrEqual(t, maxTotalRunning, 3)
+}
+
+func TestNewConcurrencyLimitsInvalidLimit(t *testing.T) {
+	t.Parallel()
+
+	_, err := newConcurrencyLimits(options.DefaultParallelism, map[string]int{"account": 0})
+	require.Error(t, err)
+	assert.IsType(t, InvalidLabelParallelism{}, errors.Unwrap(err))
+}
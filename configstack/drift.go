@@ -0,0 +1,116 @@
+package configstack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// DriftCommand is the run-all command that detects the modules whose real infrastructure drifted from their state.
+const DriftCommand = "drift"
+
+// terraform plan -detailed-exitcode exits with this code when the plan has changes, which for a refresh-only plan
+// means that the infrastructure drifted.
+const driftDetectedExitCode = 2
+
+// Drift status of a module, as reported by run-all drift.
+const (
+	DriftStatusInSync  = "in-sync"
+	DriftStatusDrifted = "drifted"
+	DriftStatusErrored = "errored"
+	DriftStatusSkipped = "skipped"
+)
+
+// prepareDriftDetection makes every module run a refresh-only plan with -detailed-exitcode instead of the drift
+// command. A plan that exits with code 2 is not a failure: the module is flagged as drifted and the modules that
+// depend on it still run.
+func prepareDriftDetection(terragruntOptions *options.TerragruntOptions, runningModules map[string]*runningModule) {
+	args := []string{"plan", "-refresh-only", "-detailed-exitcode", "-input=false"}
+	// Any extra args passed after the drift command are passed to the plan.
+	if len(terragruntOptions.TerraformCliArgs) > 1 {
+		args = append(args, terragruntOptions.TerraformCliArgs[1:]...)
+	}
+
+	for _, module := range runningModules {
+		module := module
+		moduleOptions := module.Module.TerragruntOptions
+		moduleOptions.TerraformCommand = "plan"
+		moduleOptions.TerraformCliArgs = util.CloneStringList(args)
+		// The drift is reported by the exit code, so it must not be logged as a failure or retried.
+		moduleOptions.NonFatalExitCodes = []int{driftDetectedExitCode}
+
+		runTerragrunt := moduleOptions.RunTerragrunt
+		moduleOptions.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+			err := runTerragrunt(opts)
+			if err != nil && opts.TerraformCommand == "plan" {
+				if exitCode, exitCodeErr := shell.GetExitCode(err); exitCodeErr == nil && exitCode == driftDetectedExitCode {
+					module.Drifted = true
+					return nil
+				}
+			}
+			return err
+		}
+	}
+}
+
+// driftStatus returns the drift status of a module after the run.
+func driftStatus(module *runningModule) string {
+	switch {
+	case module.Err != nil:
+		return DriftStatusErrored
+	case module.Drifted:
+		return DriftStatusDrifted
+	case module.StartTime.IsZero():
+		return DriftStatusSkipped
+	default:
+		return DriftStatusInSync
+	}
+}
+
+// summarizeDrift prints the drift status of every module and returns a DriftDetected error if any module drifted.
+// Modules that failed to plan are reported, but don't make the command fail: only drift does.
+func (stack *Stack) summarizeDrift(terragruntOptions *options.TerragruntOptions, runningModules map[string]*runningModule) error {
+	paths := []string{}
+	for path := range runningModules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	drifted := []string{}
+	errored := []string{}
+	rows := []string{"MODULE\tSTATUS"}
+	for _, path := range paths {
+		status := driftStatus(runningModules[path])
+		switch status {
+		case DriftStatusDrifted:
+			drifted = append(drifted, path)
+		case DriftStatusErrored:
+			errored = append(errored, path)
+		}
+		rows = append(rows, fmt.Sprintf("%s\t%s", path, status))
+	}
+
+	table := tabwriter.NewWriter(terragruntOptions.Writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintf(table, "\n%s\n", strings.Join(rows, "\n")); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if err := table.Flush(); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if len(errored) > 0 {
+		terragruntOptions.Logger.Errorf("Could not detect drift in %d module(s): %s", len(errored), strings.Join(errored, ", "))
+	}
+	if len(drifted) > 0 {
+		return errors.WithStackTrace(DriftDetected{Modules: drifted})
+	}
+
+	terragruntOptions.Logger.Infof("No drift detected in %d module(s)", len(paths)-len(errored))
+	return nil
+}
@@ -0,0 +1,119 @@
+package configstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type planExitCodeError int
+
+func (err planExitCodeError) Error() string {
+	return fmt.Sprintf("terraform plan exited with code %d", int(err))
+}
+
+func (err planExitCodeError) ExitStatus() (int, error) {
+	return int(err), nil
+}
+
+// driftRuns records the args each module of the test stack was run with.
+type driftRuns struct {
+	mutex sync.Mutex
+	args  map[string][]string
+}
+
+// driftTestModule creates a module whose plan fails with the given exit code, or succeeds if it is 0, and records the
+// args it was run with.
+func driftTestModule(t *testing.T, path string, exitCode int, ran *driftRuns, dependencies ...*TerraformModule) *TerraformModule {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(path, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+
+	opts.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		ran.mutex.Lock()
+		ran.args[filepath.Base(path)] = opts.TerraformCliArgs
+		ran.mutex.Unlock()
+		if exitCode != 0 {
+			return planExitCodeError(exitCode)
+		}
+		return nil
+	}
+
+	return &TerraformModule{Path: path, TerragruntOptions: opts, Dependencies: dependencies}
+}
+
+func driftTestOptions(t *testing.T, stackDir string, stdout *bytes.Buffer) *options.TerragruntOptions {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = DriftCommand
+	opts.TerraformCliArgs = []string{DriftCommand, "-lock=false"}
+	opts.WorkingDir = stackDir
+	opts.Writer = stdout
+	return opts
+}
+
+func TestStackRunDrift(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	ran := &driftRuns{args: map[string][]string{}}
+	vpc := driftTestModule(t, filepath.Join(stackDir, "vpc"), 2, ran)
+	app := driftTestModule(t, filepath.Join(stackDir, "app"), 0, ran, vpc)
+	db := driftTestModule(t, filepath.Join(stackDir, "db"), 1, ran)
+	stack := &Stack{Path: stackDir, Modules: []*TerraformModule{vpc, app, db}}
+
+	var stdout bytes.Buffer
+	opts := driftTestOptions(t, stackDir, &stdout)
+	opts.ReportFile = "report.json"
+
+	err := stack.Run(opts)
+	require.Error(t, err)
+	assert.Equal(t, DriftDetected{Modules: []string{vpc.Path}}, errors.Unwrap(err))
+
+	exitCode, exitCodeErr := shell.GetExitCode(err)
+	require.NoError(t, exitCodeErr)
+	assert.Equal(t, 2, exitCode)
+
+	// The module that depends on the drifted module still runs.
+	expectedArgs := []string{"plan", "-refresh-only", "-detailed-exitcode", "-input=false", "-lock=false"}
+	assert.Equal(t, map[string][]string{"vpc": expectedArgs, "app": expectedArgs, "db": expectedArgs}, ran.args)
+
+	assert.Regexp(t, `vpc\s+drifted`, stdout.String())
+	assert.Regexp(t, `app\s+in-sync`, stdout.String())
+	assert.Regexp(t, `db\s+errored`, stdout.String())
+
+	contents, err := os.ReadFile(filepath.Join(stackDir, "report.json"))
+	require.NoError(t, err)
+	report := Report{}
+	require.NoError(t, json.Unmarshal(contents, &report))
+	statuses := map[string]ReportModuleStatus{}
+	for _, module := range report.Modules {
+		statuses[filepath.Base(module.Path)] = module.Status
+	}
+	assert.Equal(t, map[string]ReportModuleStatus{"vpc": ReportStatusDrifted, "app": ReportStatusSucceeded, "db": ReportStatusFailed}, statuses)
+}
+
+func TestStackRunDriftOnlyFailsOnDrift(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	ran := &driftRuns{args: map[string][]string{}}
+	vpc := driftTestModule(t, filepath.Join(stackDir, "vpc"), 0, ran)
+	db := driftTestModule(t, filepath.Join(stackDir, "db"), 1, ran)
+	stack := &Stack{Path: stackDir, Modules: []*TerraformModule{vpc, db}}
+
+	var stdout bytes.Buffer
+	require.NoError(t, stack.Run(driftTestOptions(t, stackDir, &stdout)))
+	assert.Regexp(t, `vpc\s+in-sync`, stdout.String())
+	assert.Regexp(t, `db\s+errored`, stdout.String())
+}
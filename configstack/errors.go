@@ -1,6 +1,9 @@
 package configstack
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Custom error types
 
@@ -32,3 +35,85 @@ type InfiniteRecursion struct {
 func (err InfiniteRecursion) Error() string {
 	return fmt.Sprintf("Hit what seems to be an infinite recursion after going %d levels deep. Please check for a circular dependency! Modules involved: %v", err.RecursionLevel, err.Modules)
 }
+
+type RunJournalParseError struct {
+	Path string
+	Err  error
+}
+
+func (err RunJournalParseError) Error() string {
+	return fmt.Sprintf("Could not parse the run journal %s: %v", err.Path, err.Err)
+}
+
+type RunHistoryParseError struct {
+	Path string
+	Err  error
+}
+
+func (err RunHistoryParseError) Error() string {
+	return fmt.Sprintf("Could not parse the run history %s: %v", err.Path, err.Err)
+}
+
+type RunJournalCommandMismatch struct {
+	Path           string
+	JournalCommand string
+	Command        string
+}
+
+func (err RunJournalCommandMismatch) Error() string {
+	return fmt.Sprintf("Cannot resume: the run journal %s was recorded for command '%s', but the current command is '%s'. Remove the journal or run without --terragrunt-resume.", err.Path, err.JournalCommand, err.Command)
+}
+
+type UnsupportedReportFormat string
+
+func (err UnsupportedReportFormat) Error() string {
+	return fmt.Sprintf("Unsupported report format '%s'. Supported formats are: json, junit.", string(err))
+}
+
+type InvalidLabelParallelism struct {
+	Label string
+	Limit int
+}
+
+func (err InvalidLabelParallelism) Error() string {
+	return fmt.Sprintf("Invalid parallelism %d for label '%s': the limit must be at least 1.", err.Limit, err.Label)
+}
+
+type PlanSummaryWithOutArg string
+
+func (err PlanSummaryWithOutArg) Error() string {
+	return fmt.Sprintf("Cannot summarize the plans of the stack when the plan is saved with '%s': run-all plan saves the plan of each module itself when --terragrunt-plan-summary or --terragrunt-plan-summary-file is set.", string(err))
+}
+
+type UnsupportedGraphFormat string
+
+func (err UnsupportedGraphFormat) Error() string {
+	return fmt.Sprintf("Unsupported graph format '%s'. Supported formats are: %s.", string(err), strings.Join(GraphFormats, ", "))
+}
+
+type InvalidFilterExpression struct {
+	Expression string
+	Err        error
+}
+
+func (err InvalidFilterExpression) Error() string {
+	return fmt.Sprintf("Invalid filter expression '%s': %v", err.Expression, err.Err)
+}
+
+type InvalidShard string
+
+func (err InvalidShard) Error() string {
+	return fmt.Sprintf("Invalid shard '%s'. Expected i/N, where N is the number of shards and i, between 1 and N, the shard to run.", string(err))
+}
+
+type DriftDetected struct {
+	Modules []string
+}
+
+func (err DriftDetected) Error() string {
+	return fmt.Sprintf("Drift detected in %d module(s): %s", len(err.Modules), strings.Join(err.Modules, ", "))
+}
+
+func (err DriftDetected) ExitStatus() (int, error) {
+	return driftDetectedExitCode, nil
+}
@@ -0,0 +1,393 @@
+package configstack
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/mattn/go-zglob"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// The selectors of the filter expression that select the modules related to the modules matched by the term that
+// follows them.
+const (
+	filterDependentsOf   = "dependents-of:"
+	filterDependenciesOf = "dependencies-of:"
+)
+
+// flagFilteredModules flags as excluded all the modules that don't match the filter expression passed via the
+// terragrunt-filter CLI flag. Like terragrunt-modules-that-include, the filter only narrows down the set of modules:
+// modules that were already excluded through other means stay excluded.
+func flagFilteredModules(modules []*TerraformModule, terragruntOptions *options.TerragruntOptions) ([]*TerraformModule, error) {
+	if terragruntOptions.Filter == "" {
+		return modules, nil
+	}
+
+	expr, err := parseFilter(terragruntOptions.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := expr.eval(newFilterContext(modules, terragruntOptions.WorkingDir))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, module := range modules {
+		if !selected[module.Path] {
+			module.FlagExcluded = true
+		}
+	}
+
+	terragruntOptions.Logger.Debugf("%d of %d modules match the filter %s", len(selected), len(modules), terragruntOptions.Filter)
+
+	return modules, nil
+}
+
+// filterContext holds the modules a filter expression is evaluated against.
+type filterContext struct {
+	modules    []*TerraformModule
+	dependents map[string][]*TerraformModule
+	workingDir string
+}
+
+func newFilterContext(modules []*TerraformModule, workingDir string) *filterContext {
+	dependents := map[string][]*TerraformModule{}
+	for _, module := range modules {
+		for _, dependency := range module.Dependencies {
+			dependents[dependency.Path] = append(dependents[dependency.Path], module)
+		}
+	}
+	return &filterContext{modules: modules, dependents: dependents, workingDir: workingDir}
+}
+
+// selectModules returns the paths of the modules for which the given function returns true.
+func (ctx *filterContext) selectModules(matches func(module *TerraformModule) (bool, error)) (map[string]bool, error) {
+	selected := map[string]bool{}
+	for _, module := range ctx.modules {
+		isMatch, err := matches(module)
+		if err != nil {
+			return nil, err
+		}
+		if isMatch {
+			selected[module.Path] = true
+		}
+	}
+	return selected, nil
+}
+
+// filterExpr is a node of a parsed filter expression. Evaluating it returns the paths of the modules it selects.
+type filterExpr interface {
+	eval(ctx *filterContext) (map[string]bool, error)
+}
+
+type filterAnd struct{ left, right filterExpr }
+
+func (expr filterAnd) eval(ctx *filterContext) (map[string]bool, error) {
+	left, err := expr.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{}
+	for path := range left {
+		if right[path] {
+			selected[path] = true
+		}
+	}
+	return selected, nil
+}
+
+type filterOr struct{ left, right filterExpr }
+
+func (expr filterOr) eval(ctx *filterContext) (map[string]bool, error) {
+	left, err := expr.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	right, err := expr.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for path := range right {
+		left[path] = true
+	}
+	return left, nil
+}
+
+type filterNot struct{ expr filterExpr }
+
+func (expr filterNot) eval(ctx *filterContext) (map[string]bool, error) {
+	excluded, err := expr.expr.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.selectModules(func(module *TerraformModule) (bool, error) {
+		return !excluded[module.Path], nil
+	})
+}
+
+// filterLabel selects the modules with (or, if negated, without) the given label value.
+type filterLabel struct {
+	key, value string
+	negated    bool
+}
+
+func (expr filterLabel) eval(ctx *filterContext) (map[string]bool, error) {
+	return ctx.selectModules(func(module *TerraformModule) (bool, error) {
+		value, hasLabel := module.Config.Labels[expr.key]
+		return (hasLabel && value == expr.value) != expr.negated, nil
+	})
+}
+
+// filterPath selects the modules whose path matches the given glob. Relative globs are matched against the path of
+// the module relative to the working directory.
+type filterPath struct{ glob string }
+
+func (expr filterPath) eval(ctx *filterContext) (map[string]bool, error) {
+	glob := expr.glob
+	if !filepath.IsAbs(glob) {
+		glob = filepath.Join(ctx.workingDir, glob)
+	}
+	glob = filepath.ToSlash(filepath.Clean(glob))
+
+	return ctx.selectModules(func(module *TerraformModule) (bool, error) {
+		isMatch, err := zglob.Match(glob, filepath.ToSlash(module.Path))
+		if err != nil {
+			return false, errors.WithStackTrace(InvalidFilterExpression{Expression: expr.glob, Err: err})
+		}
+		return isMatch, nil
+	})
+}
+
+// filterRelated selects the modules that depend on (or, for dependencies, are depended on by) the modules selected by
+// the nested expression, directly or transitively. The modules selected by the nested expression are only selected
+// if they are related to another selected module.
+type filterRelated struct {
+	expr         filterExpr
+	dependencies bool
+}
+
+func (expr filterRelated) eval(ctx *filterContext) (map[string]bool, error) {
+	targets, err := expr.expr.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	related := func(module *TerraformModule) []*TerraformModule {
+		if expr.dependencies {
+			return module.Dependencies
+		}
+		return ctx.dependents[module.Path]
+	}
+
+	selected := map[string]bool{}
+	var walk func(module *TerraformModule)
+	walk = func(module *TerraformModule) {
+		for _, relatedModule := range related(module) {
+			if selected[relatedModule.Path] {
+				continue
+			}
+			selected[relatedModule.Path] = true
+			walk(relatedModule)
+		}
+	}
+
+	for _, module := range ctx.modules {
+		if targets[module.Path] {
+			walk(module)
+		}
+	}
+	return selected, nil
+}
+
+// parseFilter parses a filter expression. The grammar, from the lowest to the highest precedence, is:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "dependents-of:" unary | "dependencies-of:" unary | primary
+//	primary = "(" expr ")" | key "=" value | key "!=" value | path-glob
+func parseFilter(filter string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{filter: filter, tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.done() {
+		return nil, parser.errorf("unexpected '%s'", parser.peek())
+	}
+	return expr, nil
+}
+
+type filterParser struct {
+	filter string
+	tokens []string
+	pos    int
+}
+
+func (parser *filterParser) done() bool {
+	return parser.pos >= len(parser.tokens)
+}
+
+func (parser *filterParser) peek() string {
+	if parser.done() {
+		return ""
+	}
+	return parser.tokens[parser.pos]
+}
+
+func (parser *filterParser) next() string {
+	token := parser.peek()
+	parser.pos++
+	return token
+}
+
+func (parser *filterParser) errorf(format string, args ...interface{}) error {
+	return errors.WithStackTrace(InvalidFilterExpression{Expression: parser.filter, Err: fmt.Errorf(format, args...)})
+}
+
+func (parser *filterParser) parseOr() (filterExpr, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek() == "||" {
+		parser.next()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *filterParser) parseAnd() (filterExpr, error) {
+	left, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek() == "&&" {
+		parser.next()
+		right, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *filterParser) parseUnary() (filterExpr, error) {
+	switch parser.peek() {
+	case "!", filterDependentsOf, filterDependenciesOf:
+		operator := parser.next()
+		expr, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operator == "!" {
+			return filterNot{expr: expr}, nil
+		}
+		return filterRelated{expr: expr, dependencies: operator == filterDependenciesOf}, nil
+	}
+	return parser.parsePrimary()
+}
+
+func (parser *filterParser) parsePrimary() (filterExpr, error) {
+	token := parser.next()
+	switch {
+	case token == "":
+		return nil, parser.errorf("unexpected end of expression")
+	case token == "(":
+		expr, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.next() != ")" {
+			return nil, parser.errorf("missing ')'")
+		}
+		return expr, nil
+	case isFilterOperator(token):
+		return nil, parser.errorf("unexpected '%s'", token)
+	}
+
+	switch parser.peek() {
+	case "=", "!=":
+		operator := parser.next()
+		value := parser.next()
+		if value == "" || isFilterOperator(value) {
+			return nil, parser.errorf("missing value for label '%s'", token)
+		}
+		return filterLabel{key: token, value: value, negated: operator == "!="}, nil
+	}
+	return filterPath{glob: token}, nil
+}
+
+func isFilterOperator(token string) bool {
+	switch token {
+	case "(", ")", "!", "&&", "||", "=", "!=", filterDependentsOf, filterDependenciesOf:
+		return true
+	}
+	return false
+}
+
+// tokenizeFilter splits a filter expression into operators and terms. Terms are label keys, label values and path
+// globs, and can be double quoted to contain spaces or operator characters.
+func tokenizeFilter(filter string) ([]string, error) {
+	tokens := []string{}
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case strings.HasPrefix(string(runes[i:]), "&&"), strings.HasPrefix(string(runes[i:]), "||"), strings.HasPrefix(string(runes[i:]), "!="):
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case runes[i] == '(' || runes[i] == ')' || runes[i] == '!' || runes[i] == '=':
+			tokens = append(tokens, string(runes[i]))
+			i++
+		case runes[i] == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.WithStackTrace(InvalidFilterExpression{Expression: filter, Err: fmt.Errorf("unterminated quoted string")})
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()!=&|"`, runes[end]) {
+				end++
+				// The related module selectors are split from the term that follows them, e.g. dependents-of:vpc.
+				if word := string(runes[i:end]); word == filterDependentsOf || word == filterDependenciesOf {
+					break
+				}
+			}
+			if end == i {
+				return nil, errors.WithStackTrace(InvalidFilterExpression{Expression: filter, Err: fmt.Errorf("unexpected '%c'", runes[i])})
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,116 @@
+package configstack
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFilterTestModules creates the modules below, where app depends on vpc and dashboards depends on app:
+//
+//	prod/vpc         env=prod team=net
+//	prod/app         env=prod team=data
+//	prod/dashboards  env=prod
+//	dev/vpc          env=dev  team=net
+func createFilterTestModules() []*TerraformModule {
+	newModule := func(path string, labels map[string]string, dependencies ...*TerraformModule) *TerraformModule {
+		return &TerraformModule{
+			Path:         filepath.Join("/stack", path),
+			Config:       config.TerragruntConfig{Labels: labels},
+			Dependencies: dependencies,
+		}
+	}
+
+	prodVpc := newModule("prod/vpc", map[string]string{"env": "prod", "team": "net"})
+	prodApp := newModule("prod/app", map[string]string{"env": "prod", "team": "data"}, prodVpc)
+	prodDashboards := newModule("prod/dashboards", map[string]string{"env": "prod"}, prodApp)
+	devVpc := newModule("dev/vpc", map[string]string{"env": "dev", "team": "net"})
+
+	return []*TerraformModule{prodVpc, prodApp, prodDashboards, devVpc}
+}
+
+func TestFlagFilteredModules(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		filter   string
+		expected []string
+	}{
+		{`env=prod`, []string{"prod/app", "prod/dashboards", "prod/vpc"}},
+		{`env=prod && team!=data`, []string{"prod/dashboards", "prod/vpc"}},
+		{`team=net || env=dev`, []string{"dev/vpc", "prod/vpc"}},
+		{`!(env=prod)`, []string{"dev/vpc"}},
+		{`*/vpc`, []string{"dev/vpc", "prod/vpc"}},
+		{`prod/** && !prod/app`, []string{"prod/dashboards", "prod/vpc"}},
+		{`dependents-of:prod/vpc`, []string{"prod/app", "prod/dashboards"}},
+		{`dependencies-of:(env=prod && team!=net)`, []string{"prod/app", "prod/vpc"}},
+		{`prod/vpc || dependents-of: prod/vpc`, []string{"prod/app", "prod/dashboards", "prod/vpc"}},
+		{`team="data"`, []string{"prod/app"}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.filter, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := options.NewTerragruntOptionsForTest("/stack/terragrunt.hcl")
+			require.NoError(t, err)
+			opts.WorkingDir = "/stack"
+			opts.Filter = testCase.filter
+
+			modules, err := flagFilteredModules(createFilterTestModules(), opts)
+			require.NoError(t, err)
+
+			actual := []string{}
+			for _, module := range modules {
+				if !module.FlagExcluded {
+					actual = append(actual, module.Path[len("/stack/"):])
+				}
+			}
+			sort.Strings(actual)
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestFlagFilteredModulesKeepsExcludedModules(t *testing.T) {
+	t.Parallel()
+
+	opts, err := options.NewTerragruntOptionsForTest("/stack/terragrunt.hcl")
+	require.NoError(t, err)
+	opts.WorkingDir = "/stack"
+	opts.Filter = "team=net"
+
+	modules := createFilterTestModules()
+	modules[3].FlagExcluded = true
+
+	modules, err = flagFilteredModules(modules, opts)
+	require.NoError(t, err)
+	assert.False(t, modules[0].FlagExcluded)
+	assert.True(t, modules[3].FlagExcluded)
+}
+
+func TestParseFilterInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, filter := range []string{
+		`env=`,
+		`env=prod &&`,
+		`(env=prod`,
+		`env=prod)`,
+		`env=prod & team=net`,
+		`team="net`,
+		`dependents-of:`,
+	} {
+		_, err := parseFilter(filter)
+		if assert.Error(t, err, filter) {
+			assert.IsType(t, InvalidFilterExpression{}, errors.Unwrap(err), filter)
+		}
+	}
+}
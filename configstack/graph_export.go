@@ -0,0 +1,134 @@
+package configstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// The formats the dependency graph can be exported to with graph-dependencies --format.
+const (
+	GraphFormatDot     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatJSON    = "json"
+)
+
+var GraphFormats = []string{GraphFormatDot, GraphFormatMermaid, GraphFormatJSON}
+
+// WriteMermaid is used to emit a Mermaid flowchart definition for the directed graph of the modules, which can be
+// rendered by wikis and code hosting platforms. Like WriteDot, excluded modules are styled differently and all paths
+// are relative to the TerragruntConfigPath.
+func WriteMermaid(w io.Writer, terragruntOptions *options.TerragruntOptions, modules []*TerraformModule) error {
+	prefix := filepath.Dir(terragruntOptions.TerragruntConfigPath) + "/"
+
+	// Mermaid node IDs can't contain most punctuation, so the nodes get generated IDs and are labeled with their path.
+	nodeIDs := map[string]string{}
+	nodeID := func(path string) string {
+		if id, ok := nodeIDs[path]; ok {
+			return id
+		}
+		nodeIDs[path] = fmt.Sprintf("m%d", len(nodeIDs))
+		return nodeIDs[path]
+	}
+
+	lines := []string{"flowchart TD"}
+	excluded := []string{}
+	for _, source := range modules {
+		lines = append(lines, fmt.Sprintf("\t%s[\"%s\"]", nodeID(source.Path), mermaidLabel(strings.TrimPrefix(source.Path, prefix))))
+		if source.FlagExcluded {
+			excluded = append(excluded, nodeID(source.Path))
+		}
+	}
+	for _, source := range modules {
+		for _, target := range source.Dependencies {
+			if _, isNode := nodeIDs[target.Path]; !isNode {
+				lines = append(lines, fmt.Sprintf("\t%s[\"%s\"]", nodeID(target.Path), mermaidLabel(strings.TrimPrefix(target.Path, prefix))))
+			}
+			lines = append(lines, fmt.Sprintf("\t%s --> %s", nodeID(source.Path), nodeID(target.Path)))
+		}
+	}
+	if len(excluded) > 0 {
+		lines = append(lines, "\tclassDef excluded stroke:red,color:red", fmt.Sprintf("\tclass %s excluded", strings.Join(excluded, ",")))
+	}
+
+	if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
+
+// mermaidLabel escapes the double quotes of a node label, which would otherwise end the label.
+func mermaidLabel(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
+
+// GraphJSONModule is the representation of a module in the JSON export of the dependency graph.
+type GraphJSONModule struct {
+	Path                 string   `json:"path"`
+	Excluded             bool     `json:"excluded"`
+	External             bool     `json:"external"`
+	AssumeAlreadyApplied bool     `json:"assume_already_applied"`
+	Dependencies         []string `json:"dependencies"`
+	// Group is the index, starting from 1, of the group the module runs in, as logged by run-all. Modules that don't
+	// run, such as excluded modules, don't have a group.
+	Group *int `json:"group"`
+}
+
+// WriteGraphJSON is used to emit the dependency graph of the modules as JSON, so that it can be consumed by tools
+// without parsing DOT. Modules outside the directory of the TerragruntConfigPath are marked as external.
+func WriteGraphJSON(w io.Writer, terragruntOptions *options.TerragruntOptions, stack *Stack) error {
+	runGraph, err := stack.getModuleRunGraph(terragruntOptions.TerraformCommand)
+	if err != nil {
+		return err
+	}
+
+	groups := map[string]int{}
+	for i, group := range runGraph {
+		for _, module := range group {
+			groups[module.Path] = i + 1
+		}
+	}
+
+	prefix := filepath.Dir(terragruntOptions.TerragruntConfigPath) + "/"
+
+	graph := []GraphJSONModule{}
+	for _, module := range stack.Modules {
+		dependencies := []string{}
+		for _, dependency := range module.Dependencies {
+			dependencies = append(dependencies, dependency.Path)
+		}
+		sort.Strings(dependencies)
+
+		graphModule := GraphJSONModule{
+			Path:                 module.Path,
+			Excluded:             module.FlagExcluded,
+			External:             !strings.HasPrefix(module.Path, prefix),
+			AssumeAlreadyApplied: module.AssumeAlreadyApplied,
+			Dependencies:         dependencies,
+		}
+		if group, hasGroup := groups[module.Path]; hasGroup {
+			graphModule.Group = &group
+		}
+		graph = append(graph, graphModule)
+	}
+
+	sort.Slice(graph, func(i, j int) bool {
+		return graph[i].Path < graph[j].Path
+	})
+
+	contents, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if _, err := fmt.Fprintln(w, string(contents)); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
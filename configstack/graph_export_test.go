@@ -0,0 +1,102 @@
+package configstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMermaid(t *testing.T) {
+	t.Parallel()
+
+	a := &TerraformModule{Path: "/config/a"}
+	b := &TerraformModule{Path: "/config/alpha/b", Dependencies: []*TerraformModule{a}}
+	c := &TerraformModule{Path: "/config/c", Dependencies: []*TerraformModule{a, b}, FlagExcluded: true}
+
+	var stdout bytes.Buffer
+	terragruntOptions, err := options.NewTerragruntOptionsWithConfigPath("/config/terragrunt.hcl")
+	require.NoError(t, err)
+	require.NoError(t, WriteMermaid(&stdout, terragruntOptions, []*TerraformModule{a, b, c}))
+
+	expected := strings.TrimSpace(`
+flowchart TD
+	m0["a"]
+	m1["alpha/b"]
+	m2["c"]
+	m1 --> m0
+	m2 --> m0
+	m2 --> m1
+	classDef excluded stroke:red,color:red
+	class m2 excluded
+`)
+	assert.Equal(t, expected, strings.TrimSpace(stdout.String()))
+}
+
+func TestWriteGraphJSON(t *testing.T) {
+	t.Parallel()
+
+	external := &TerraformModule{Path: "/other/vpc", AssumeAlreadyApplied: true}
+	a := &TerraformModule{Path: "/config/a", Dependencies: []*TerraformModule{external}}
+	b := &TerraformModule{Path: "/config/b", Dependencies: []*TerraformModule{a}}
+	c := &TerraformModule{Path: "/config/c", Dependencies: []*TerraformModule{a}, FlagExcluded: true}
+	d := &TerraformModule{Path: "/config/d", Dependencies: []*TerraformModule{b, a}}
+
+	var stdout bytes.Buffer
+	terragruntOptions, err := options.NewTerragruntOptionsWithConfigPath("/config/terragrunt.hcl")
+	require.NoError(t, err)
+	stack := &Stack{Path: "/config", Modules: []*TerraformModule{d, c, b, a, external}}
+	require.NoError(t, WriteGraphJSON(&stdout, terragruntOptions, stack))
+
+	actual := []GraphJSONModule{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &actual))
+
+	group := func(group int) *int { return &group }
+	expected := []GraphJSONModule{
+		{Path: "/config/a", Dependencies: []string{"/other/vpc"}, Group: group(1)},
+		{Path: "/config/b", Dependencies: []string{"/config/a"}, Group: group(2)},
+		{Path: "/config/c", Excluded: true, Dependencies: []string{"/config/a"}},
+		{Path: "/config/d", Dependencies: []string{"/config/a", "/config/b"}, Group: group(3)},
+		{Path: "/other/vpc", External: true, AssumeAlreadyApplied: true, Dependencies: []string{}},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestStackGraphUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsWithConfigPath("/config/terragrunt.hcl")
+	require.NoError(t, err)
+	terragruntOptions.GraphFormat = "svg"
+
+	stack := &Stack{Path: "/config", Modules: []*TerraformModule{{Path: "/config/a"}}}
+	err = stack.Graph(terragruntOptions)
+	assert.IsType(t, UnsupportedGraphFormat(""), errors.Unwrap(err))
+}
+
+// failingWriter is a writer that fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, assert.AnError
+}
+
+func TestStackGraphWriteError(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsWithConfigPath("/config/terragrunt.hcl")
+	require.NoError(t, err)
+	terragruntOptions.Writer = failingWriter{}
+
+	stack := &Stack{Path: "/config", Modules: []*TerraformModule{{Path: "/config/a"}}}
+	for _, format := range GraphFormats {
+		terragruntOptions.GraphFormat = format
+		err = stack.Graph(terragruntOptions)
+		assert.ErrorIs(t, err, assert.AnError, format)
+	}
+}
@@ -0,0 +1,100 @@
+package configstack
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// groupedOutputLock makes sure that the output blocks of modules that finish at the same time are not interleaved.
+var groupedOutputLock sync.Mutex
+
+// outputChunk is a piece of output and the stream it was written to.
+type outputChunk struct {
+	stream *groupedOutputWriter
+	data   []byte
+}
+
+// groupedOutput buffers the stdout and stderr of a module, in the order they are written, until the module finishes.
+// The delimiters of the block are written to delimiterWriter.
+type groupedOutput struct {
+	mutex           sync.Mutex
+	chunks          []*outputChunk
+	delimiterWriter io.Writer
+}
+
+// groupedOutputWriter buffers the output meant for writer in a groupedOutput.
+type groupedOutputWriter struct {
+	output *groupedOutput
+	writer io.Writer
+}
+
+func (writer *groupedOutputWriter) Write(p []byte) (int, error) {
+	output := writer.output
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+
+	// Consecutive writes to the same stream are merged into a single chunk.
+	if last := len(output.chunks) - 1; last >= 0 && output.chunks[last].stream == writer {
+		output.chunks[last].data = append(output.chunks[last].data, p...)
+	} else {
+		output.chunks = append(output.chunks, &outputChunk{stream: writer, data: append([]byte{}, p...)})
+	}
+	return len(p), nil
+}
+
+// groupModuleOutput makes every module buffer its output and write it as a single block once it finishes, so that the
+// output of modules that run in parallel is not interleaved. Unless opts.GroupOutputLiveStderr is set, stderr is
+// buffered along with stdout.
+func groupModuleOutput(opts *options.TerragruntOptions, runningModules map[string]*runningModule) {
+	for _, module := range runningModules {
+		output := &groupedOutput{delimiterWriter: opts.ErrWriter}
+		moduleOptions := module.Module.TerragruntOptions
+		moduleOptions.Writer = &groupedOutputWriter{output: output, writer: moduleOptions.Writer}
+		if !opts.GroupOutputLiveStderr {
+			moduleOptions.ErrWriter = &groupedOutputWriter{output: output, writer: moduleOptions.ErrWriter}
+		}
+		module.Output = output
+	}
+}
+
+// flushOutput writes the buffered output of the module as one block, between delimiters that name the module and
+// tell how it finished. Modules that wrote nothing are left out.
+func (module *runningModule) flushOutput(moduleErr error) {
+	output := module.Output
+	output.mutex.Lock()
+	chunks := output.chunks
+	output.chunks = nil
+	output.mutex.Unlock()
+
+	if len(chunks) == 0 {
+		return
+	}
+
+	result := "succeeded"
+	if moduleErr != nil {
+		result = "failed"
+	}
+
+	groupedOutputLock.Lock()
+	defer groupedOutputLock.Unlock()
+
+	logger := module.Module.TerragruntOptions.Logger
+	if _, err := fmt.Fprintf(output.delimiterWriter, "==> Output of module %s\n", module.Module.Path); err != nil {
+		logger.Warnf("Error writing the output of module %s: %v", module.Module.Path, err)
+	}
+	for _, chunk := range chunks {
+		if _, err := chunk.stream.writer.Write(chunk.data); err != nil {
+			logger.Warnf("Error writing the output of module %s: %v", module.Module.Path, err)
+		}
+	}
+	// Terminate the output with a new line, so that the delimiter starts on a line of its own.
+	if last := chunks[len(chunks)-1].data; len(last) > 0 && last[len(last)-1] != '\n' {
+		fmt.Fprintln(chunks[len(chunks)-1].stream.writer) //nolint:errcheck
+	}
+	if _, err := fmt.Fprintf(output.delimiterWriter, "<== End of output of module %s (%s)\n", module.Module.Path, result); err != nil {
+		logger.Warnf("Error writing the output of module %s: %v", module.Module.Path, err)
+	}
+}
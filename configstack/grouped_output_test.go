@@ -0,0 +1,108 @@
+package configstack
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that can be written concurrently.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (buffer *syncBuffer) Write(p []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.Write(p)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.String()
+}
+
+// groupedOutputTestModule creates a module that writes two lines to stdout and one to stderr, pausing in between so
+// that the output of modules running in parallel would be interleaved.
+func groupedOutputTestModule(t *testing.T, path string, output *syncBuffer, runErr error) *TerraformModule {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(path, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.Writer = output
+	opts.ErrWriter = output
+
+	name := filepath.Base(path)
+	opts.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		fmt.Fprintf(opts.Writer, "%s stdout 1\n", name)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintf(opts.ErrWriter, "%s stderr\n", name)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprintf(opts.Writer, "%s stdout 2", name)
+		return runErr
+	}
+
+	return &TerraformModule{Path: path, TerragruntOptions: opts}
+}
+
+func TestStackRunGroupOutput(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	output := &syncBuffer{}
+	vpc := groupedOutputTestModule(t, filepath.Join(stackDir, "vpc"), output, nil)
+	app := groupedOutputTestModule(t, filepath.Join(stackDir, "app"), output, assert.AnError)
+	stack := &Stack{Path: stackDir, Modules: []*TerraformModule{vpc, app}}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = "validate"
+	opts.TerraformCliArgs = []string{"validate"}
+	opts.ErrWriter = output
+	opts.GroupOutput = true
+
+	require.Error(t, stack.Run(opts))
+
+	assert.Contains(t, output.String(), fmt.Sprintf(
+		"==> Output of module %[1]s\nvpc stdout 1\nvpc stderr\nvpc stdout 2\n<== End of output of module %[1]s (succeeded)\n",
+		vpc.Path,
+	))
+	assert.Contains(t, output.String(), fmt.Sprintf(
+		"==> Output of module %[1]s\napp stdout 1\napp stderr\napp stdout 2\n<== End of output of module %[1]s (failed)\n",
+		app.Path,
+	))
+}
+
+func TestStackRunGroupOutputLiveStderr(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	output := &syncBuffer{}
+	vpc := groupedOutputTestModule(t, filepath.Join(stackDir, "vpc"), output, nil)
+	stack := &Stack{Path: stackDir, Modules: []*TerraformModule{vpc}}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = "validate"
+	opts.TerraformCliArgs = []string{"validate"}
+	opts.ErrWriter = output
+	opts.GroupOutput = true
+	opts.GroupOutputLiveStderr = true
+
+	require.NoError(t, stack.Run(opts))
+
+	// stderr is written before the block of the module, as soon as the module writes it.
+	expected := fmt.Sprintf(
+		"vpc stderr\n==> Output of module %[1]s\nvpc stdout 1\nvpc stdout 2\n<== End of output of module %[1]s (succeeded)\n",
+		vpc.Path,
+	)
+	assert.Contains(t, output.String(), expected)
+}
@@ -0,0 +1,172 @@
+package configstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// runJournalPath returns the path of the file, stored in the download dir, where the result of the last run-all of the
+// stack at the given path is recorded so that it can be resumed with --terragrunt-resume. The file is named after a
+// hash of the stack path, so that the stacks sharing a download dir don't overwrite each other's journal.
+func runJournalPath(downloadDir string, stackPath string) string {
+	return filepath.Join(downloadDir, fmt.Sprintf(".terragrunt-run-journal-%s.json", util.EncodeBase64Sha1(stackPath)))
+}
+
+// runJournal records the final status of every module of a run-all, so that a subsequent run can skip the modules
+// that already finished successfully.
+type runJournal struct {
+	path  string
+	mutex sync.Mutex
+
+	Command string                      `json:"command"`
+	Modules map[string]*runJournalEntry `json:"modules"`
+}
+
+// runJournalEntry is the final state of a single module in the journal. Skipped is set for the modules excluded from
+// the run, which never ran.
+type runJournalEntry struct {
+	Status  ModuleStatus `json:"status"`
+	Error   string       `json:"error,omitempty"`
+	Skipped bool         `json:"skipped,omitempty"`
+}
+
+// succeeded returns true if the module ran and finished without an error.
+func (entry *runJournalEntry) succeeded() bool {
+	return entry.Status == Finished && entry.Error == "" && !entry.Skipped
+}
+
+// newRunJournal creates an empty journal for the stack at the given path, stored in the given download dir.
+func newRunJournal(downloadDir string, stackPath string, terraformCommand string) *runJournal {
+	return &runJournal{
+		path:    runJournalPath(downloadDir, stackPath),
+		Command: terraformCommand,
+		Modules: map[string]*runJournalEntry{},
+	}
+}
+
+// load reads the journal of the previous run from disk. Returns false if there is no journal to read.
+func (journal *runJournal) load() (bool, error) {
+	if !util.FileExists(journal.path) {
+		return false, nil
+	}
+
+	contents, err := os.ReadFile(journal.path)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+
+	previous := &runJournal{}
+	if err := json.Unmarshal(contents, previous); err != nil {
+		return false, errors.WithStackTrace(RunJournalParseError{Path: journal.path, Err: err})
+	}
+
+	if previous.Command != journal.Command {
+		return false, errors.WithStackTrace(RunJournalCommandMismatch{Path: journal.path, JournalCommand: previous.Command, Command: journal.Command})
+	}
+
+	if previous.Modules != nil {
+		journal.Modules = previous.Modules
+	}
+
+	return true, nil
+}
+
+// resume reloads the journal of the previous run and marks every module that succeeded in that run as finished, so it
+// is not run again. Modules that failed or never started are scheduled again together with all the modules that
+// depend on them.
+func (journal *runJournal) resume(terragruntOptions *options.TerragruntOptions, modules map[string]*runningModule) error {
+	found, err := journal.load()
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		terragruntOptions.Logger.Warnf("No run journal found at %s, all modules will be run.", journal.path)
+		return nil
+	}
+
+	toRun := map[string]bool{}
+
+	var scheduleWithDependents func(path string)
+	scheduleWithDependents = func(path string) {
+		if toRun[path] {
+			return
+		}
+		toRun[path] = true
+
+		if module, ok := modules[path]; ok {
+			for _, dependent := range module.NotifyWhenDone {
+				scheduleWithDependents(dependent.Module.Path)
+			}
+		}
+	}
+
+	for path := range modules {
+		entry, ok := journal.Modules[path]
+		if ok && entry.Skipped {
+			terragruntOptions.Logger.Infof("Module %s was excluded from the previous run, it will be run.", path)
+		}
+		if !ok || !entry.succeeded() {
+			scheduleWithDependents(path)
+		}
+	}
+
+	for path, module := range modules {
+		if toRun[path] {
+			continue
+		}
+		terragruntOptions.Logger.Infof("Module %s finished successfully in the previous run, it will not be run again.", path)
+		module.Status = Finished
+	}
+
+	return nil
+}
+
+// skip records the modules at the given paths as excluded from the run. They are written with the next record.
+func (journal *runJournal) skip(paths ...string) {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	for _, path := range paths {
+		journal.Modules[path] = &runJournalEntry{Status: Waiting, Skipped: true}
+	}
+}
+
+// record stores the current status and error of the given modules and writes the journal file. It is called before
+// the run starts and every time a module finishes, so that the journal stays accurate even if the run is interrupted.
+func (journal *runJournal) record(modules ...*runningModule) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	for _, module := range modules {
+		entry := &runJournalEntry{Status: module.Status}
+		if module.Err != nil {
+			entry.Error = module.Err.Error()
+		}
+		journal.Modules[module.Module.Path] = entry
+	}
+
+	contents, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(journal.path), os.ModePerm); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	// Write into a temporary file first so that an interrupted write never leaves a truncated journal behind.
+	tmpPath := journal.path + ".tmp"
+	if err := os.WriteFile(tmpPath, contents, os.FileMode(0644)); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	return errors.WithStackTrace(os.Rename(tmpPath, journal.path))
+}
@@ -0,0 +1,243 @@
+package configstack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunJournalRecordsModuleStatus(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	bRan := false
+	expectedErrB := errors.WithStackTrace(assert.AnError)
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", expectedErrB, &bRan),
+	}
+
+	cRan := false
+	moduleC := &TerraformModule{
+		Path:              "c",
+		Dependencies:      []*TerraformModule{moduleB},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "c", nil, &cRan),
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules([]*TerraformModule{moduleA, moduleB, moduleC}, NormalOrder)
+	require.NoError(t, err)
+
+	downloadDir := t.TempDir()
+	err = runModules(opts, runningModules, options.DefaultParallelism, newRunJournal(downloadDir, "stack", "apply"))
+	require.Error(t, err)
+
+	journal := newRunJournal(downloadDir, "stack", "apply")
+	found, err := journal.load()
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Len(t, journal.Modules, 3)
+	assert.True(t, journal.Modules["a"].succeeded())
+	assert.False(t, journal.Modules["b"].succeeded())
+	assert.Equal(t, Finished, journal.Modules["b"].Status)
+	assert.Contains(t, journal.Modules["b"].Error, assert.AnError.Error())
+	assert.False(t, journal.Modules["c"].succeeded())
+	assert.False(t, cRan)
+}
+
+func TestRunJournalResumeRunsOnlyFailedModulesAndDependents(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	bRan := false
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", nil, &bRan),
+	}
+
+	cRan := false
+	moduleC := &TerraformModule{
+		Path:              "c",
+		Dependencies:      []*TerraformModule{moduleA},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "c", nil, &cRan),
+	}
+
+	// d succeeded in the previous run (its dependency errors were ignored), but must run again after b is re-run.
+	dRan := false
+	moduleD := &TerraformModule{
+		Path:              "d",
+		Dependencies:      []*TerraformModule{moduleB},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "d", nil, &dRan),
+	}
+
+	eRan := false
+	moduleE := &TerraformModule{
+		Path:              "e",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "e", nil, &eRan),
+	}
+
+	downloadDir := t.TempDir()
+	previous := newRunJournal(downloadDir, "stack", "apply")
+	previous.Modules = map[string]*runJournalEntry{
+		"a": {Status: Finished},
+		"b": {Status: Finished, Error: "failed"},
+		"c": {Status: Finished},
+		"d": {Status: Finished},
+		"e": {Status: Waiting},
+	}
+	require.NoError(t, previous.record())
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules([]*TerraformModule{moduleA, moduleB, moduleC, moduleD, moduleE}, NormalOrder)
+	require.NoError(t, err)
+
+	journal := newRunJournal(downloadDir, "stack", "apply")
+	require.NoError(t, journal.resume(opts, runningModules))
+
+	err = runModules(opts, runningModules, options.DefaultParallelism, journal)
+	require.NoError(t, err)
+
+	assert.False(t, aRan)
+	assert.True(t, bRan)
+	assert.False(t, cRan)
+	assert.True(t, dRan)
+	assert.True(t, eRan)
+
+	for path, entry := range journal.Modules {
+		assert.True(t, entry.succeeded(), "Expected module %s to be recorded as succeeded", path)
+	}
+}
+
+func TestRunJournalResumeWithoutJournalRunsAllModules(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules([]*TerraformModule{moduleA}, NormalOrder)
+	require.NoError(t, err)
+
+	journal := newRunJournal(t.TempDir(), "stack", "apply")
+	require.NoError(t, journal.resume(opts, runningModules))
+
+	err = runModules(opts, runningModules, options.DefaultParallelism, journal)
+	require.NoError(t, err)
+	assert.True(t, aRan)
+}
+
+func TestRunJournalResumeCommandMismatch(t *testing.T) {
+	t.Parallel()
+
+	// The download dir is created when the journal is first written.
+	downloadDir := filepath.Join(t.TempDir(), util.TerragruntCacheDir)
+	require.NoError(t, newRunJournal(downloadDir, "stack", "plan").record())
+	require.FileExists(t, runJournalPath(downloadDir, "stack"))
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	err = newRunJournal(downloadDir, "stack", "apply").resume(opts, map[string]*runningModule{})
+	require.Error(t, err)
+	assert.IsType(t, RunJournalCommandMismatch{}, errors.Unwrap(err))
+}
+
+func TestRunJournalInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	downloadDir := t.TempDir()
+	require.NoError(t, os.WriteFile(runJournalPath(downloadDir, "stack"), []byte(`{"modules": {"a": {"status": "bogus"}}}`), 0644))
+
+	_, err := newRunJournal(downloadDir, "stack", "").load()
+	require.Error(t, err)
+	assert.IsType(t, RunJournalParseError{}, errors.Unwrap(err))
+}
+
+func TestRunJournalPerStack(t *testing.T) {
+	t.Parallel()
+
+	downloadDir := t.TempDir()
+	require.NoError(t, newRunJournal(downloadDir, "stack-a", "plan").record())
+	require.NoError(t, newRunJournal(downloadDir, "stack-b", "apply").record())
+
+	// The stacks sharing the download dir each have their own journal.
+	assert.NotEqual(t, runJournalPath(downloadDir, "stack-a"), runJournalPath(downloadDir, "stack-b"))
+	found, err := newRunJournal(downloadDir, "stack-a", "plan").load()
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestRunJournalResumeRunsSkippedModules(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	downloadDir := t.TempDir()
+	previous := newRunJournal(downloadDir, "stack", "apply")
+	previous.skip("a")
+	require.NoError(t, previous.record())
+
+	journal := newRunJournal(downloadDir, "stack", "apply")
+	found, err := journal.load()
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, journal.Modules["a"].Skipped)
+	assert.False(t, journal.Modules["a"].succeeded())
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules([]*TerraformModule{moduleA}, NormalOrder)
+	require.NoError(t, err)
+	require.NoError(t, journal.resume(opts, runningModules))
+	require.NoError(t, runModules(opts, runningModules, options.DefaultParallelism, journal))
+	assert.True(t, aRan)
+}
@@ -74,9 +74,24 @@ func ResolveTerraformModules(terragruntConfigPaths []string, terragruntOptions *
 
 	includedModules := flagIncludedDirs(crossLinkedModules, terragruntOptions)
 
-	includedModulesWithExcluded := flagExcludedDirs(includedModules, terragruntOptions)
+	affectedModules, err := flagUnaffectedModules(includedModules, terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
 
-	finalModules, err := flagModulesThatDontInclude(includedModulesWithExcluded, terragruntOptions)
+	includedModulesWithExcluded := flagExcludedDirs(affectedModules, terragruntOptions)
+
+	modulesThatInclude, err := flagModulesThatDontInclude(includedModulesWithExcluded, terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredModules, err := flagFilteredModules(modulesThatInclude, terragruntOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	finalModules, err := flagModulesOutsideShard(filteredModules, terragruntOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -217,26 +232,7 @@ func flagModulesThatDontInclude(modules []*TerraformModule, terragruntOptions *o
 // into a TerraformModule struct. Note that this method will NOT fill in the Dependencies field of the TerraformModule
 // struct (see the crosslinkDependencies method for that). Return a map from module path to TerraformModule struct.
 func resolveModules(canonicalTerragruntConfigPaths []string, terragruntOptions *options.TerragruntOptions, childTerragruntConfig *config.TerragruntConfig, howTheseModulesWereFound string) (map[string]*TerraformModule, error) {
-	moduleMap := map[string]*TerraformModule{}
-
-	for _, terragruntConfigPath := range canonicalTerragruntConfigPaths {
-		module, err := resolveTerraformModule(terragruntConfigPath, moduleMap, terragruntOptions, childTerragruntConfig, howTheseModulesWereFound)
-		if err != nil {
-			return moduleMap, err
-		}
-
-		if module != nil {
-			moduleMap[module.Path] = module
-
-			dependencies, err := resolveDependenciesForModule(module, moduleMap, terragruntOptions, childTerragruntConfig, true)
-			if err != nil {
-				return moduleMap, err
-			}
-			moduleMap = collections.MergeMaps(moduleMap, dependencies)
-		}
-	}
-
-	return moduleMap, nil
+	return newModuleResolver(terragruntOptions, childTerragruntConfig).resolveModules(canonicalTerragruntConfigPaths, howTheseModulesWereFound)
 }
 
 // Create a TerraformModule struct for the Terraform module specified by the given Terragrunt configuration file path.
@@ -281,6 +277,9 @@ func resolveTerraformModule(terragruntConfigPath string, moduleMap map[string]*T
 		// Need for parsing out the dependencies
 		config.DependenciesBlock,
 		config.DependencyBlock,
+
+		// Need for the label-scoped concurrency limits
+		config.TerragruntLabels,
 	)
 
 	// We only partially parse the config, only using the pieces that we need in this section. This config will be fully
@@ -390,30 +389,47 @@ func resolveExternalDependenciesForModules(moduleMap map[string]*TerraformModule
 // If `skipExternal` is true, the func returns only dependencies that are inside of the current working directory, which means they are part of the environment the
 // user is trying to apply-all or destroy-all. Note that this method will NOT fill in the Dependencies field of the TerraformModule struct (see the crosslinkDependencies method for that).
 func resolveDependenciesForModule(module *TerraformModule, moduleMap map[string]*TerraformModule, terragruntOptions *options.TerragruntOptions, chilTerragruntConfig *config.TerragruntConfig, skipExternal bool) (map[string]*TerraformModule, error) {
-	if module.Config.Dependencies == nil || len(module.Config.Dependencies.Paths) == 0 {
-		return map[string]*TerraformModule{}, nil
+	dependencyPaths, err := dependencyConfigPaths(module, terragruntOptions, skipExternal)
+	if err != nil {
+		return map[string]*TerraformModule{}, err
 	}
 
 	externalTerragruntConfigPaths := []string{}
+	for _, terragruntConfigPath := range dependencyPaths {
+		if _, alreadyContainsModule := moduleMap[filepath.Dir(terragruntConfigPath)]; !alreadyContainsModule {
+			externalTerragruntConfigPaths = append(externalTerragruntConfigPaths, terragruntConfigPath)
+		}
+	}
+
+	return resolveModules(externalTerragruntConfigPaths, terragruntOptions, chilTerragruntConfig, dependencyHowFound(module))
+}
+
+// dependencyConfigPaths returns the paths of the Terragrunt configuration files of the dependencies of the given module.
+// If `skipExternal` is true, the dependencies outside of the current working directory are skipped.
+func dependencyConfigPaths(module *TerraformModule, terragruntOptions *options.TerragruntOptions, skipExternal bool) ([]string, error) {
+	if module.Config.Dependencies == nil || len(module.Config.Dependencies.Paths) == 0 {
+		return nil, nil
+	}
+
+	terragruntConfigPaths := []string{}
 	for _, dependency := range module.Config.Dependencies.Paths {
 		dependencyPath, err := util.CanonicalPath(dependency, module.Path)
 		if err != nil {
-			return map[string]*TerraformModule{}, err
+			return nil, err
 		}
 
 		if skipExternal && !util.HasPathPrefix(dependencyPath, terragruntOptions.WorkingDir) {
 			continue
 		}
 
-		terragruntConfigPath := config.GetDefaultConfigPath(dependencyPath)
-
-		if _, alreadyContainsModule := moduleMap[dependencyPath]; !alreadyContainsModule {
-			externalTerragruntConfigPaths = append(externalTerragruntConfigPaths, terragruntConfigPath)
-		}
+		terragruntConfigPaths = append(terragruntConfigPaths, config.GetDefaultConfigPath(dependencyPath))
 	}
+	return terragruntConfigPaths, nil
+}
 
-	howThesePathsWereFound := fmt.Sprintf("dependency of module at '%s'", module.Path)
-	return resolveModules(externalTerragruntConfigPaths, terragruntOptions, chilTerragruntConfig, howThesePathsWereFound)
+// dependencyHowFound describes how the dependencies of the given module were found, for the error messages.
+func dependencyHowFound(module *TerraformModule) string {
+	return fmt.Sprintf("dependency of module at '%s'", module.Path)
 }
 
 // Confirm with the user whether they want Terragrunt to assume the given dependency of the given module is already
@@ -541,6 +557,10 @@ func FindWhereWorkingDirIsIncluded(terragruntOptions *options.TerragruntOptions,
 	if useIncludes { // detection failed, trying to use include directories as source for stacks
 		uniquePaths := make(map[string]bool)
 		for _, includePath := range terragruntConfig.ProcessedIncludes {
+			// The remote includes are fetched in the remote config cache, outside of any stack.
+			if includePath.RemoteSource != "" {
+				continue
+			}
 			uniquePaths[filepath.Dir(includePath.Path)] = true
 		}
 		for path := range uniquePaths {
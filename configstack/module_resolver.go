@@ -0,0 +1,184 @@
+package configstack
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gruntwork-io/go-commons/collections"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// moduleResolution is the result of resolving the module of a Terragrunt configuration file. done is closed once the
+// module and err are set.
+type moduleResolution struct {
+	done   chan struct{}
+	module *TerraformModule
+	err    error
+}
+
+// moduleResolver resolves the modules of Terragrunt configuration files concurrently. Each configuration file is only
+// parsed once, by one of a bounded number of workers, and the modules it depends on are resolved in the background as
+// soon as it is parsed. The resolved modules are then assembled in the same order as if they were resolved one at a
+// time, so that the result and the error reported don't depend on which parse finishes first.
+type moduleResolver struct {
+	terragruntOptions     *options.TerragruntOptions
+	childTerragruntConfig *config.TerragruntConfig
+
+	workers   chan struct{}
+	waitGroup sync.WaitGroup
+	cancelled atomic.Bool
+
+	mutex       sync.Mutex
+	resolutions map[string]*moduleResolution
+}
+
+func newModuleResolver(terragruntOptions *options.TerragruntOptions, childTerragruntConfig *config.TerragruntConfig) *moduleResolver {
+	return &moduleResolver{
+		terragruntOptions:     terragruntOptions,
+		childTerragruntConfig: childTerragruntConfig,
+		workers:               make(chan struct{}, resolveModulesParallelism(terragruntOptions)),
+		resolutions:           map[string]*moduleResolution{},
+	}
+}
+
+// resolveModulesParallelism returns the number of configuration files parsed at the same time while resolving the
+// modules: one per CPU, but no more than --terragrunt-parallelism.
+func resolveModulesParallelism(terragruntOptions *options.TerragruntOptions) int {
+	return max(1, min(terragruntOptions.Parallelism, runtime.NumCPU()))
+}
+
+// resolveModules resolves the modules of the given configuration files and of their dependencies inside the working
+// directory. It waits for all the parses started in the background before returning.
+func (resolver *moduleResolver) resolveModules(canonicalTerragruntConfigPaths []string, howTheseModulesWereFound string) (map[string]*TerraformModule, error) {
+	defer func() {
+		// The parses of the modules that are not needed anymore, e.g. after an error, are skipped.
+		resolver.cancelled.Store(true)
+		resolver.waitGroup.Wait()
+	}()
+
+	for _, terragruntConfigPath := range canonicalTerragruntConfigPaths {
+		resolver.prefetch(terragruntConfigPath, howTheseModulesWereFound)
+	}
+
+	return resolver.assemble(canonicalTerragruntConfigPaths, howTheseModulesWereFound, map[string]bool{})
+}
+
+// prefetch starts resolving the module of the given configuration file in the background, unless it is already being
+// resolved. Once it is resolved, the modules it depends on are prefetched as well.
+func (resolver *moduleResolver) prefetch(terragruntConfigPath string, howThisModuleWasFound string) *moduleResolution {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	if resolution, found := resolver.resolutions[terragruntConfigPath]; found {
+		return resolution
+	}
+
+	resolution := &moduleResolution{done: make(chan struct{})}
+	resolver.resolutions[terragruntConfigPath] = resolution
+
+	resolver.waitGroup.Add(1)
+	go func() {
+		defer resolver.waitGroup.Done()
+
+		resolver.workers <- struct{}{}
+		// Once cancelled, nothing waits for the module anymore.
+		if !resolver.cancelled.Load() {
+			resolution.module, resolution.err = resolveTerraformModule(terragruntConfigPath, map[string]*TerraformModule{}, resolver.terragruntOptions, resolver.childTerragruntConfig, howThisModuleWasFound)
+		}
+		<-resolver.workers
+		close(resolution.done)
+
+		if resolution.err != nil || resolution.module == nil {
+			return
+		}
+		// Errors are reported when the modules are assembled.
+		dependencyPaths, err := dependencyConfigPaths(resolution.module, resolver.terragruntOptions, true)
+		if err != nil {
+			return
+		}
+		howTheseModulesWereFound := dependencyHowFound(resolution.module)
+		for _, dependencyPath := range dependencyPaths {
+			resolver.prefetch(dependencyPath, howTheseModulesWereFound)
+		}
+	}()
+
+	return resolution
+}
+
+// resolve returns the module of the given configuration file, waiting for it to be resolved.
+func (resolver *moduleResolver) resolve(terragruntConfigPath string, howThisModuleWasFound string) (*TerraformModule, error) {
+	resolution := resolver.prefetch(terragruntConfigPath, howThisModuleWasFound)
+	<-resolution.done
+
+	if resolution.err != nil {
+		// The module may have been resolved while found another way, so report how it is found here.
+		if processingErr, ok := errors.Unwrap(resolution.err).(ErrorProcessingModule); ok {
+			processingErr.HowThisModuleWasFound = howThisModuleWasFound
+			return nil, errors.WithStackTrace(processingErr)
+		}
+		return nil, resolution.err
+	}
+	if resolution.module == nil {
+		return nil, nil
+	}
+
+	// Each module found is a distinct struct, as the modules are later modified, e.g. to link their dependencies.
+	module := *resolution.module
+	return &module, nil
+}
+
+// assemble builds the map of the modules of the given configuration files and of their dependencies inside the working
+// directory, in the same order as they would be resolved one at a time, so that the first error in that order is
+// returned. The modules whose dependencies are being assembled are skipped, so that a dependency cycle doesn't recurse
+// forever: the cycle is reported once the dependencies are linked.
+func (resolver *moduleResolver) assemble(canonicalTerragruntConfigPaths []string, howTheseModulesWereFound string, ancestors map[string]bool) (map[string]*TerraformModule, error) {
+	moduleMap := map[string]*TerraformModule{}
+
+	for _, terragruntConfigPath := range canonicalTerragruntConfigPaths {
+		modulePath, err := util.CanonicalPath(filepath.Dir(terragruntConfigPath), ".")
+		if err != nil {
+			return moduleMap, err
+		}
+		if _, ok := moduleMap[modulePath]; ok {
+			continue
+		}
+
+		module, err := resolver.resolve(terragruntConfigPath, howTheseModulesWereFound)
+		if err != nil {
+			return moduleMap, err
+		}
+		if module == nil {
+			continue
+		}
+
+		moduleMap[module.Path] = module
+
+		dependencyPaths, err := dependencyConfigPaths(module, resolver.terragruntOptions, true)
+		if err != nil {
+			return moduleMap, err
+		}
+
+		ancestors[module.Path] = true
+		pendingDependencyPaths := []string{}
+		for _, dependencyPath := range dependencyPaths {
+			dependencyModulePath := filepath.Dir(dependencyPath)
+			if _, alreadyContainsModule := moduleMap[dependencyModulePath]; alreadyContainsModule || ancestors[dependencyModulePath] {
+				continue
+			}
+			pendingDependencyPaths = append(pendingDependencyPaths, dependencyPath)
+		}
+		dependencies, err := resolver.assemble(pendingDependencyPaths, dependencyHowFound(module), ancestors)
+		delete(ancestors, module.Path)
+		if err != nil {
+			return moduleMap, err
+		}
+		moduleMap = collections.MergeMaps(moduleMap, dependencies)
+	}
+
+	return moduleMap, nil
+}
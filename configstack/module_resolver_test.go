@@ -0,0 +1,112 @@
+package configstack
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+)
+
+// writeResolverTestModules writes a module with the given Terragrunt config in a directory of a new working directory
+// for each of the given configs, and returns the working directory.
+func writeResolverTestModules(t *testing.T, configs map[string]string) string {
+	files := map[string]string{}
+	for name, configContents := range configs {
+		files[name+"/"+config.DefaultTerragruntConfigPath] = configContents
+		files[name+"/main.tf"] = ""
+	}
+	return helpers.WriteTempFiles(t, files)
+}
+
+func resolverTestOptions(t *testing.T, workingDir string, parallelism int) *options.TerragruntOptions {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(workingDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.WorkingDir = workingDir
+	opts.Parallelism = parallelism
+	return opts
+}
+
+func TestResolveTerraformModulesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	workingDir := writeResolverTestModules(t, map[string]string{
+		"vpc":  ``,
+		"db":   `dependencies { paths = ["../vpc"] }`,
+		"app":  `dependencies { paths = ["../vpc", "../db"] }`,
+		"jobs": `dependencies { paths = ["../db"] }`,
+	})
+
+	for _, parallelism := range []int{1, 4} {
+		opts := resolverTestOptions(t, workingDir, parallelism)
+		configPaths := []string{
+			filepath.Join(workingDir, "app", config.DefaultTerragruntConfigPath),
+			filepath.Join(workingDir, "jobs", config.DefaultTerragruntConfigPath),
+		}
+
+		modules, err := ResolveTerraformModules(configPaths, opts, nil, mockHowThesePathsWereFound)
+		require.NoError(t, err)
+
+		dependencies := map[string][]string{}
+		for _, module := range modules {
+			dependencies[filepath.Base(module.Path)] = []string{}
+			for _, dependency := range module.Dependencies {
+				dependencies[filepath.Base(module.Path)] = append(dependencies[filepath.Base(module.Path)], filepath.Base(dependency.Path))
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"app":  {"vpc", "db"},
+			"db":   {"vpc"},
+			"jobs": {"db"},
+			"vpc":  {},
+		}, dependencies)
+	}
+}
+
+func TestResolveTerraformModulesDeterministicError(t *testing.T) {
+	t.Parallel()
+
+	workingDir := writeResolverTestModules(t, map[string]string{
+		"a":      `dependencies { paths = ["../broken"] }`,
+		"b":      `dependencies { paths = [`,
+		"broken": `locals {`,
+	})
+	opts := resolverTestOptions(t, workingDir, 4)
+	configPaths := []string{
+		filepath.Join(workingDir, "a", config.DefaultTerragruntConfigPath),
+		filepath.Join(workingDir, "b", config.DefaultTerragruntConfigPath),
+	}
+
+	// The error is the first one met when resolving the modules one at a time, whichever parse fails first.
+	for i := 0; i < 10; i++ {
+		_, err := ResolveTerraformModules(configPaths, opts, nil, mockHowThesePathsWereFound)
+		require.Error(t, err)
+
+		processingErr, ok := errors.Unwrap(err).(ErrorProcessingModule)
+		require.True(t, ok, "Expected an ErrorProcessingModule but got %v", err)
+		assert.Equal(t, filepath.Join(workingDir, "broken", config.DefaultTerragruntConfigPath), processingErr.ModulePath)
+		assert.Equal(t, "dependency of module at '"+filepath.Join(workingDir, "a")+"'", processingErr.HowThisModuleWasFound)
+	}
+}
+
+func TestResolveTerraformModulesDependencyCycle(t *testing.T) {
+	t.Parallel()
+
+	workingDir := writeResolverTestModules(t, map[string]string{
+		"a": `dependencies { paths = ["../b"] }`,
+		"b": `dependencies { paths = ["../a"] }`,
+	})
+	opts := resolverTestOptions(t, workingDir, 4)
+	configPaths := []string{filepath.Join(workingDir, "a", config.DefaultTerragruntConfigPath)}
+
+	// The modules are resolved, and the cycle is left to be reported when the stack is checked for cycles.
+	modules, err := ResolveTerraformModules(configPaths, opts, nil, mockHowThesePathsWereFound)
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+	assert.Error(t, CheckForCycles(modules))
+}
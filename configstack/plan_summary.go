@@ -0,0 +1,249 @@
+package configstack
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+const planSummaryFileName = "tfplan"
+
+// PlanSummary is the consolidated result of a run-all plan, with the changes planned in every module of the stack.
+type PlanSummary struct {
+	Modules []*ModulePlanSummary `json:"modules"`
+	Total   PlanChanges          `json:"total"`
+}
+
+// ModulePlanSummary is the summary of the changes planned in a single module, together with the plan as rendered by
+// `terraform show -json`.
+type ModulePlanSummary struct {
+	Path    string          `json:"path"`
+	Changes *PlanChanges    `json:"changes,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Plan    json.RawMessage `json:"plan,omitempty"`
+}
+
+// PlanChanges counts the resource changes of a plan by action.
+type PlanChanges struct {
+	Create  int `json:"create"`
+	Update  int `json:"update"`
+	Delete  int `json:"delete"`
+	Replace int `json:"replace"`
+}
+
+func (changes *PlanChanges) add(other PlanChanges) {
+	changes.Create += other.Create
+	changes.Update += other.Update
+	changes.Delete += other.Delete
+	changes.Replace += other.Replace
+}
+
+// planJSON holds the parts of the `terraform show -json` output of a plan that are needed for the summary.
+type planJSON struct {
+	ResourceChanges []struct {
+		Change struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// countPlanChanges counts the resource changes of the given `terraform show -json` output.
+func countPlanChanges(planJSONOutput []byte) (PlanChanges, error) {
+	plan := planJSON{}
+	if err := json.Unmarshal(planJSONOutput, &plan); err != nil {
+		return PlanChanges{}, errors.WithStackTrace(err)
+	}
+
+	changes := PlanChanges{}
+	for _, resourceChange := range plan.ResourceChanges {
+		actions := resourceChange.Change.Actions
+		switch {
+		case len(actions) == 2 && util.ListContainsElement(actions, "create") && util.ListContainsElement(actions, "delete"):
+			changes.Replace++
+		case len(actions) == 1 && actions[0] == "create":
+			changes.Create++
+		case len(actions) == 1 && actions[0] == "update":
+			changes.Update++
+		case len(actions) == 1 && actions[0] == "delete":
+			changes.Delete++
+		}
+	}
+	return changes, nil
+}
+
+// planSummaryEnabled returns true if the run-all plan should save the plans of the modules to summarize them.
+func planSummaryEnabled(terragruntOptions *options.TerragruntOptions) bool {
+	return terragruntOptions.TerraformCommand == "plan" && (terragruntOptions.PlanSummary || terragruntOptions.PlanSummaryFile != "")
+}
+
+// planFiles tracks the plan file each module of the stack saves its plan to.
+type planFiles struct {
+	dir   string
+	paths map[string]string
+}
+
+// savePlans makes every module of the stack save its plan in a temporary directory, so that the plans can be summarized
+// once the run is over.
+func (stack *Stack) savePlans(terragruntOptions *options.TerragruntOptions) (*planFiles, error) {
+	for _, arg := range terragruntOptions.TerraformCliArgs {
+		if arg == "-out" || strings.HasPrefix(arg, "-out=") {
+			return nil, errors.WithStackTrace(PlanSummaryWithOutArg(arg))
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "terragrunt-plan-summary")
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	plans := &planFiles{dir: dir, paths: map[string]string{}}
+	for _, module := range stack.Modules {
+		// Plan files are named after the hash of the module path, so that they never collide.
+		hash := sha256.Sum256([]byte(module.Path))
+		planPath := filepath.Join(dir, hex.EncodeToString(hash[:8]), planSummaryFileName)
+		if err := os.MkdirAll(filepath.Dir(planPath), os.ModePerm); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		plans.paths[module.Path] = planPath
+
+		module.TerragruntOptions.TerraformCliArgs = append(util.CloneStringList(module.TerragruntOptions.TerraformCliArgs), "-out="+planPath)
+	}
+
+	return plans, nil
+}
+
+// summarizePlans renders the saved plan of every module that was planned successfully with `terraform show -json`,
+// prints the summary table and writes the combined plan JSON to --terragrunt-plan-summary-file, if set.
+func (stack *Stack) summarizePlans(terragruntOptions *options.TerragruntOptions, plans *planFiles, runningModules map[string]*runningModule) error {
+	defer func() {
+		if err := os.RemoveAll(plans.dir); err != nil {
+			terragruntOptions.Logger.Warnf("Failed to remove the plan summary directory %s: %v", plans.dir, err)
+		}
+	}()
+
+	summary := &PlanSummary{}
+	for _, module := range stack.Modules {
+		running, wasScheduled := runningModules[module.Path]
+		if !wasScheduled {
+			continue
+		}
+
+		moduleSummary := &ModulePlanSummary{Path: module.Path}
+		summary.Modules = append(summary.Modules, moduleSummary)
+
+		switch {
+		case running.Err != nil:
+			moduleSummary.Error = running.Err.Error()
+			continue
+		case !util.FileExists(plans.paths[module.Path]):
+			// The module was not planned, e.g. because it was already planned in the run being resumed.
+			moduleSummary.Error = "no plan was saved for this module"
+			continue
+		}
+
+		planJSONOutput, err := showPlanJSON(module, plans.paths[module.Path])
+		if err != nil {
+			terragruntOptions.Logger.Errorf("Failed to render the plan of module %s: %v", module.Path, err)
+			moduleSummary.Error = err.Error()
+			continue
+		}
+
+		changes, err := countPlanChanges(planJSONOutput)
+		if err != nil {
+			moduleSummary.Error = err.Error()
+			continue
+		}
+
+		moduleSummary.Changes = &changes
+		moduleSummary.Plan = planJSONOutput
+		summary.Total.add(changes)
+	}
+
+	sort.Slice(summary.Modules, func(i, j int) bool {
+		return summary.Modules[i].Path < summary.Modules[j].Path
+	})
+
+	if err := summary.WriteTable(terragruntOptions.Writer); err != nil {
+		return err
+	}
+
+	if terragruntOptions.PlanSummaryFile == "" {
+		return nil
+	}
+
+	summaryPath := terragruntOptions.PlanSummaryFile
+	if !filepath.IsAbs(summaryPath) {
+		summaryPath = util.JoinPath(terragruntOptions.WorkingDir, summaryPath)
+	}
+	if err := summary.WriteJSON(summaryPath); err != nil {
+		return err
+	}
+
+	terragruntOptions.Logger.Infof("Wrote the run-all plan summary to %s", summaryPath)
+	return nil
+}
+
+// showPlanJSON runs `terraform show -json` on the given plan file of the module and returns its output.
+func showPlanJSON(module *TerraformModule, planPath string) ([]byte, error) {
+	var stdout bytes.Buffer
+
+	opts := module.TerragruntOptions.Clone(module.TerragruntOptions.TerragruntConfigPath)
+	opts.TerraformCommand = "show"
+	opts.TerraformCliArgs = []string{"show", "-json", planPath}
+	opts.IncludeModulePrefix = false
+	opts.TerraformLogsToJson = false
+	opts.CheckDependentModules = false
+	opts.Writer = &stdout
+
+	if err := opts.RunTerragrunt(opts); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(stdout.Bytes()), nil
+}
+
+// WriteTable prints the changes of every module as a table, followed by the totals of the stack.
+func (summary *PlanSummary) WriteTable(writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	rows := []string{"MODULE\tCREATE\tUPDATE\tDELETE\tREPLACE"}
+	for _, module := range summary.Modules {
+		if module.Changes == nil {
+			rows = append(rows, fmt.Sprintf("%s\t-\t-\t-\t-\t(error)", module.Path))
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("%s\t%d\t%d\t%d\t%d", module.Path, module.Changes.Create, module.Changes.Update, module.Changes.Delete, module.Changes.Replace))
+	}
+	rows = append(rows, fmt.Sprintf("TOTAL\t%d\t%d\t%d\t%d", summary.Total.Create, summary.Total.Update, summary.Total.Delete, summary.Total.Replace))
+
+	if _, err := fmt.Fprintf(table, "\n%s\n", strings.Join(rows, "\n")); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(table.Flush())
+}
+
+// WriteJSON writes the summary, including the plan of every module, to the given file.
+func (summary *PlanSummary) WriteJSON(path string) error {
+	contents, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if err := util.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	return errors.WithStackTrace(os.WriteFile(path, contents, os.FileMode(0644)))
+}
@@ -0,0 +1,141 @@
+package configstack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "a.create", "change": {"actions": ["create"]}},
+    {"address": "a.update", "change": {"actions": ["update"]}},
+    {"address": "a.delete", "change": {"actions": ["delete"]}},
+    {"address": "a.replace", "change": {"actions": ["delete", "create"]}},
+    {"address": "a.replace_cbd", "change": {"actions": ["create", "delete"]}},
+    {"address": "a.noop", "change": {"actions": ["no-op"]}},
+    {"address": "a.read", "change": {"actions": ["read"]}}
+  ]
+}`
+
+func TestCountPlanChanges(t *testing.T) {
+	t.Parallel()
+
+	changes, err := countPlanChanges([]byte(testPlanJSON))
+	require.NoError(t, err)
+	assert.Equal(t, PlanChanges{Create: 1, Update: 1, Delete: 1, Replace: 2}, changes)
+}
+
+func TestCountPlanChangesInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := countPlanChanges([]byte("Error: not a plan"))
+	assert.Error(t, err)
+}
+
+// planSummaryTestModule creates a module whose plan saves an empty plan file to the -out path and whose show prints
+// testPlanJSON, or fails with planErr.
+func planSummaryTestModule(t *testing.T, path string, planErr error) *TerraformModule {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(path, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = "plan"
+
+	opts.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		switch opts.TerraformCommand {
+		case "plan":
+			if planErr != nil {
+				return planErr
+			}
+			for _, arg := range opts.TerraformCliArgs {
+				if planPath, isOut := strings.CutPrefix(arg, "-out="); isOut {
+					return os.WriteFile(planPath, []byte("plan"), 0644)
+				}
+			}
+			return fmt.Errorf("plan was not saved")
+		case "show":
+			if !assert.Equal(t, "-json", opts.TerraformCliArgs[1]) || !assert.FileExists(t, opts.TerraformCliArgs[2]) {
+				return fmt.Errorf("unexpected show arguments: %v", opts.TerraformCliArgs)
+			}
+			_, err := opts.Writer.Write([]byte(testPlanJSON))
+			return err
+		}
+		return fmt.Errorf("unexpected command %s", opts.TerraformCommand)
+	}
+
+	return &TerraformModule{Path: path, TerragruntOptions: opts}
+}
+
+func TestStackRunPlanSummary(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	planErr := fmt.Errorf("plan failed")
+	stack := &Stack{
+		Path: stackDir,
+		Modules: []*TerraformModule{
+			planSummaryTestModule(t, filepath.Join(stackDir, "app"), nil),
+			planSummaryTestModule(t, filepath.Join(stackDir, "db"), planErr),
+			planSummaryTestModule(t, filepath.Join(stackDir, "vpc"), nil),
+		},
+	}
+
+	var stdout bytes.Buffer
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = []string{"plan"}
+	opts.NonInteractive = true
+	opts.PlanSummaryFile = "out/plan.json"
+	opts.WorkingDir = stackDir
+	opts.Writer = &stdout
+
+	err = stack.Run(opts)
+	assertMultiErrorContains(t, err, planErr)
+
+	table := stdout.String()
+	assert.Regexp(t, `app\s+1\s+1\s+1\s+2`, table)
+	assert.Regexp(t, `db\s+-\s+-\s+-\s+-\s+\(error\)`, table)
+	assert.Regexp(t, `TOTAL\s+2\s+2\s+2\s+4`, table)
+
+	contents, err := os.ReadFile(filepath.Join(stackDir, "out", "plan.json"))
+	require.NoError(t, err)
+
+	summary := PlanSummary{}
+	require.NoError(t, json.Unmarshal(contents, &summary))
+	require.Len(t, summary.Modules, 3)
+	assert.Equal(t, PlanChanges{Create: 2, Update: 2, Delete: 2, Replace: 4}, summary.Total)
+	assert.Equal(t, &PlanChanges{Create: 1, Update: 1, Delete: 1, Replace: 2}, summary.Modules[0].Changes)
+	assert.JSONEq(t, testPlanJSON, string(summary.Modules[0].Plan))
+	assert.Nil(t, summary.Modules[1].Changes)
+	assert.Contains(t, summary.Modules[1].Error, "plan failed")
+}
+
+func TestStackRunPlanSummaryWithOutArg(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	stack := &Stack{
+		Path:    stackDir,
+		Modules: []*TerraformModule{planSummaryTestModule(t, filepath.Join(stackDir, "app"), nil)},
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = []string{"plan", "-out=tfplan"}
+	opts.NonInteractive = true
+	opts.PlanSummary = true
+
+	err = stack.Run(opts)
+	assert.Contains(t, err.Error(), "-out=tfplan")
+}
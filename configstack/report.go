@@ -0,0 +1,257 @@
+package configstack
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// Supported formats of the run-all report.
+const (
+	ReportFormatJSON  = "json"
+	ReportFormatJUnit = "junit"
+)
+
+// ReportModuleStatus is the outcome of a single module in the run-all report.
+type ReportModuleStatus string
+
+const (
+	ReportStatusSucceeded        ReportModuleStatus = "succeeded"
+	ReportStatusFailed           ReportModuleStatus = "failed"
+	ReportStatusSkipped          ReportModuleStatus = "skipped"
+	ReportStatusExcluded         ReportModuleStatus = "excluded"
+	ReportStatusDependencyFailed ReportModuleStatus = "dependency-failed"
+	ReportStatusCancelled        ReportModuleStatus = "cancelled"
+	ReportStatusDrifted          ReportModuleStatus = "drifted"
+	ReportStatusTimedOut         ReportModuleStatus = "timed-out"
+)
+
+// Report is the machine-readable summary of a run-all command.
+type Report struct {
+	Command string          `json:"command"`
+	Modules []*ModuleReport `json:"modules"`
+}
+
+// ModuleReport is the result of running the command in a single module of the stack.
+type ModuleReport struct {
+	Path        string             `json:"path"`
+	Status      ReportModuleStatus `json:"status"`
+	StartTime   *time.Time         `json:"start_time,omitempty"`
+	EndTime     *time.Time         `json:"end_time,omitempty"`
+	Duration    float64            `json:"duration_seconds"`
+	ExitCode    *int               `json:"exit_code,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	Explanation string             `json:"explanation,omitempty"`
+	// Errors of the module that were retried or ignored by a rule of its errors block, in the order they happened.
+	ErrorRules []options.ErrorRuleMatch `json:"error_rules,omitempty"`
+}
+
+// newReport builds the report for the given stack modules, using the results of their runs. Modules of the stack
+// that are missing from runningModules were excluded from the run.
+func newReport(terraformCommand string, modules []*TerraformModule, runningModules map[string]*runningModule) *Report {
+	report := &Report{Command: terraformCommand}
+
+	for _, module := range modules {
+		moduleReport := &ModuleReport{Path: module.Path}
+
+		running, wasScheduled := runningModules[module.Path]
+		switch {
+		case !wasScheduled:
+			moduleReport.Status = ReportStatusExcluded
+		case running.Err != nil:
+			switch errors.Unwrap(running.Err).(type) {
+			case ModuleCancelled:
+				moduleReport.Status = ReportStatusCancelled
+			case DependencyFinishedWithError:
+				moduleReport.Status = ReportStatusDependencyFailed
+			default:
+				moduleReport.Status = ReportStatusFailed
+				if shell.IsTimeoutError(running.Err) {
+					moduleReport.Status = ReportStatusTimedOut
+				}
+			}
+			moduleReport.Error = running.Err.Error()
+			moduleReport.Explanation = shell.ExplainError(running.Err)
+			if exitCode, err := shell.GetExitCode(running.Err); err == nil {
+				moduleReport.ExitCode = &exitCode
+			}
+		case running.StartTime.IsZero():
+			moduleReport.Status = ReportStatusSkipped
+		case running.Drifted:
+			moduleReport.Status = ReportStatusDrifted
+			exitCode := driftDetectedExitCode
+			moduleReport.ExitCode = &exitCode
+		default:
+			moduleReport.Status = ReportStatusSucceeded
+			exitCode := 0
+			moduleReport.ExitCode = &exitCode
+		}
+
+		if wasScheduled && !running.StartTime.IsZero() {
+			moduleReport.ErrorRules = running.ErrorRuleMatches
+			startTime, endTime := running.StartTime, running.EndTime
+			moduleReport.StartTime = &startTime
+			moduleReport.EndTime = &endTime
+			moduleReport.Duration = endTime.Sub(startTime).Seconds()
+		}
+
+		report.Modules = append(report.Modules, moduleReport)
+	}
+
+	sort.Slice(report.Modules, func(i, j int) bool {
+		return report.Modules[i].Path < report.Modules[j].Path
+	})
+
+	return report
+}
+
+// WriteReport writes the report to the given file. If format is empty, it is detected from the file extension: files
+// ending with .xml are written as JUnit XML, everything else as JSON.
+func (report *Report) WriteReport(path string, format string) error {
+	if format == "" {
+		format = ReportFormatJSON
+		if strings.EqualFold(filepath.Ext(path), ".xml") {
+			format = ReportFormatJUnit
+		}
+	}
+
+	var (
+		contents []byte
+		err      error
+	)
+
+	switch format {
+	case ReportFormatJSON:
+		contents, err = json.MarshalIndent(report, "", "  ")
+	case ReportFormatJUnit:
+		contents, err = report.junitXML()
+	default:
+		return errors.WithStackTrace(UnsupportedReportFormat(format))
+	}
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if err := util.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	return errors.WithStackTrace(os.WriteFile(path, contents, os.FileMode(0644)))
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// junitXML renders the report as a single JUnit test suite where every module is a test case.
+func (report *Report) junitXML() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:  fmt.Sprintf("terragrunt run-all %s", report.Command),
+		Tests: len(report.Modules),
+	}
+
+	var totalDuration float64
+	for _, module := range report.Modules {
+		totalDuration += module.Duration
+
+		testCase := junitTestCase{
+			Name:      module.Path,
+			ClassName: "terragrunt",
+			Time:      formatJUnitSeconds(module.Duration),
+		}
+
+		switch module.Status {
+		case ReportStatusFailed, ReportStatusDependencyFailed, ReportStatusDrifted, ReportStatusTimedOut:
+			suite.Failures++
+			body := module.Error
+			if module.Explanation != "" {
+				body = fmt.Sprintf("%s\n\n%s", module.Explanation, module.Error)
+			}
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("module %s", module.Status),
+				Type:    string(module.Status),
+				Body:    body,
+			}
+		case ReportStatusSkipped, ReportStatusExcluded, ReportStatusCancelled:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: string(module.Status)}
+		}
+
+		for _, match := range module.ErrorRules {
+			testCase.SystemOut += fmt.Sprintf("Error matched the %s rule %s", match.Action, match.Rule)
+			if match.Message != "" {
+				testCase.SystemOut += ": " + match.Message
+			}
+			testCase.SystemOut += "\n"
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = formatJUnitSeconds(totalDuration)
+
+	contents, err := xml.MarshalIndent(junitTestSuites{TestSuites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), contents...), nil
+}
+
+func formatJUnitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// writeRunReport writes the report of the run to the file configured with --terragrunt-report-file, if any.
+func (stack *Stack) writeRunReport(terragruntOptions *options.TerragruntOptions, runningModules map[string]*runningModule) error {
+	if terragruntOptions.ReportFile == "" {
+		return nil
+	}
+
+	reportPath := terragruntOptions.ReportFile
+	if !filepath.IsAbs(reportPath) {
+		reportPath = util.JoinPath(terragruntOptions.WorkingDir, reportPath)
+	}
+
+	report := newReport(terragruntOptions.TerraformCommand, stack.Modules, runningModules)
+	if err := report.WriteReport(reportPath, terragruntOptions.ReportFormat); err != nil {
+		return err
+	}
+
+	terragruntOptions.Logger.Infof("Wrote run-all report to %s", reportPath)
+	return nil
+}
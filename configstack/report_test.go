@@ -0,0 +1,244 @@
+package configstack
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runTestStackForReport(t *testing.T) *Report {
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	bRan := false
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", errors.WithStackTrace(assert.AnError), &bRan),
+	}
+
+	cRan := false
+	moduleC := &TerraformModule{
+		Path:              "c",
+		Dependencies:      []*TerraformModule{moduleB},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "c", nil, &cRan),
+	}
+
+	dRan := false
+	moduleD := &TerraformModule{
+		Path:                 "d",
+		Dependencies:         []*TerraformModule{},
+		Config:               config.TerragruntConfig{},
+		TerragruntOptions:    optionsWithMockTerragruntCommand(t, "d", nil, &dRan),
+		AssumeAlreadyApplied: true,
+	}
+
+	eRan := false
+	moduleE := &TerraformModule{
+		Path:              "e",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "e", nil, &eRan),
+		FlagExcluded:      true,
+	}
+
+	modules := []*TerraformModule{moduleA, moduleB, moduleC, moduleD, moduleE}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules(modules, NormalOrder)
+	require.NoError(t, err)
+
+	err = runModules(opts, runningModules, options.DefaultParallelism, nil)
+	require.Error(t, err)
+
+	return newReport("apply", modules, runningModules)
+}
+
+func TestNewReportModuleStatuses(t *testing.T) {
+	t.Parallel()
+
+	report := runTestStackForReport(t)
+	require.Len(t, report.Modules, 5)
+
+	expectedStatuses := map[string]ReportModuleStatus{
+		"a": ReportStatusSucceeded,
+		"b": ReportStatusFailed,
+		"c": ReportStatusDependencyFailed,
+		"d": ReportStatusSkipped,
+		"e": ReportStatusExcluded,
+	}
+	for _, module := range report.Modules {
+		assert.Equal(t, expectedStatuses[module.Path], module.Status, "Unexpected status for module %s", module.Path)
+	}
+
+	moduleA := report.Modules[0]
+	require.NotNil(t, moduleA.StartTime)
+	require.NotNil(t, moduleA.EndTime)
+	require.NotNil(t, moduleA.ExitCode)
+	assert.Equal(t, 0, *moduleA.ExitCode)
+	assert.Empty(t, moduleA.Error)
+
+	moduleB := report.Modules[1]
+	require.NotNil(t, moduleB.StartTime)
+	assert.Contains(t, moduleB.Error, assert.AnError.Error())
+
+	moduleC := report.Modules[2]
+	assert.Nil(t, moduleC.StartTime)
+	assert.NotEmpty(t, moduleC.Error)
+}
+
+func TestNewReportTimedOutModule(t *testing.T) {
+	t.Parallel()
+
+	timeoutErr := errors.WithStackTrace(shell.CommandTimeoutError{Command: "terraform", Args: []string{"apply"}, Timeout: time.Minute, WorkingDir: "a"})
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", timeoutErr, &aRan),
+	}
+
+	bRan := false
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{moduleA},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", nil, &bRan),
+	}
+
+	cRan := false
+	moduleC := &TerraformModule{
+		Path:              "c",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "c", nil, &cRan),
+	}
+
+	modules := []*TerraformModule{moduleA, moduleB, moduleC}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules(modules, NormalOrder)
+	require.NoError(t, err)
+	require.Error(t, runModules(opts, runningModules, options.DefaultParallelism, nil))
+
+	// The module that timed out fails on its own: the modules that don't depend on it still run.
+	assert.True(t, cRan)
+	report := newReport("apply", modules, runningModules)
+	require.Len(t, report.Modules, 3)
+	assert.Equal(t, ReportStatusTimedOut, report.Modules[0].Status)
+	assert.Equal(t, ReportStatusDependencyFailed, report.Modules[1].Status)
+	assert.Equal(t, ReportStatusSucceeded, report.Modules[2].Status)
+}
+
+func TestNewReportErrorRules(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+	runTerragrunt := moduleA.TerragruntOptions.RunTerragrunt
+	moduleA.TerragruntOptions.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		opts.OnErrorRuleMatch(options.ErrorRuleMatch{Rule: "throttling", Action: options.ErrorRuleActionRetry})
+		opts.OnErrorRuleMatch(options.ErrorRuleMatch{Rule: "missing_alarm", Action: options.ErrorRuleActionIgnore, Message: "harmless"})
+		return runTerragrunt(opts)
+	}
+	modules := []*TerraformModule{moduleA}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+
+	runningModules, err := toRunningModules(modules, NormalOrder)
+	require.NoError(t, err)
+	require.NoError(t, runModules(opts, runningModules, options.DefaultParallelism, nil))
+
+	report := newReport("apply", modules, runningModules)
+	require.Len(t, report.Modules, 1)
+	assert.Equal(t, ReportStatusSucceeded, report.Modules[0].Status)
+	assert.Equal(t, []options.ErrorRuleMatch{
+		{Rule: "throttling", Action: options.ErrorRuleActionRetry},
+		{Rule: "missing_alarm", Action: options.ErrorRuleActionIgnore, Message: "harmless"},
+	}, report.Modules[0].ErrorRules)
+
+	contents, err := report.junitXML()
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "Error matched the ignore rule missing_alarm: harmless")
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	t.Parallel()
+
+	report := runTestStackForReport(t)
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.WriteReport(reportPath, ""))
+
+	contents, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var actual Report
+	require.NoError(t, json.Unmarshal(contents, &actual))
+	assert.Equal(t, "apply", actual.Command)
+	require.Len(t, actual.Modules, 5)
+	assert.Equal(t, ReportStatusFailed, actual.Modules[1].Status)
+}
+
+func TestWriteReportJUnit(t *testing.T) {
+	t.Parallel()
+
+	report := runTestStackForReport(t)
+	reportPath := filepath.Join(t.TempDir(), "report.xml")
+	require.NoError(t, report.WriteReport(reportPath, ""))
+
+	contents, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var actual junitTestSuites
+	require.NoError(t, xml.Unmarshal(contents, &actual))
+	require.Len(t, actual.TestSuites, 1)
+
+	suite := actual.TestSuites[0]
+	assert.Equal(t, 5, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+	assert.Equal(t, 2, suite.Skipped)
+	require.Len(t, suite.TestCases, 5)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Contains(t, suite.TestCases[1].Failure.Body, assert.AnError.Error())
+	require.NotNil(t, suite.TestCases[4].Skipped)
+	assert.Equal(t, string(ReportStatusExcluded), suite.TestCases[4].Skipped.Message)
+}
+
+func TestWriteReportUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	report := &Report{Command: "plan"}
+	err := report.WriteReport(filepath.Join(t.TempDir(), "report.txt"), "yaml")
+	require.Error(t, err)
+	assert.IsType(t, UnsupportedReportFormat(""), errors.Unwrap(err))
+}
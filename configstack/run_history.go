@@ -0,0 +1,153 @@
+package configstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/util"
+)
+
+// runHistoryPath returns the path of the file, stored in the download dir, where the duration of the modules of the
+// stack at the given path in previous run-all commands is recorded, so that the modules on the longest chains can be
+// started first. The file is named after a hash of the stack path, so that the stacks sharing a download dir each keep
+// their own history.
+func runHistoryPath(downloadDir string, stackPath string) string {
+	return filepath.Join(downloadDir, fmt.Sprintf(".terragrunt-run-history-%s.json", util.EncodeBase64Sha1(stackPath)))
+}
+
+// runHistory holds, for every command, the duration in seconds of the last successful run of every module. Modules are
+// identified by their path relative to the stack.
+type runHistory struct {
+	path string
+
+	Commands map[string]map[string]float64 `json:"commands"`
+}
+
+// loadRunHistory reads the run history of the stack at the given path, stored in the given download dir. An empty
+// history is returned if there is no history yet.
+func loadRunHistory(downloadDir string, stackPath string) (*runHistory, error) {
+	history := &runHistory{
+		path:     runHistoryPath(downloadDir, stackPath),
+		Commands: map[string]map[string]float64{},
+	}
+	if !util.FileExists(history.path) {
+		return history, nil
+	}
+
+	contents, err := os.ReadFile(history.path)
+	if err != nil {
+		return history, errors.WithStackTrace(err)
+	}
+	if err := json.Unmarshal(contents, history); err != nil {
+		history.Commands = map[string]map[string]float64{}
+		return history, errors.WithStackTrace(RunHistoryParseError{Path: history.path, Err: err})
+	}
+	if history.Commands == nil {
+		history.Commands = map[string]map[string]float64{}
+	}
+	return history, nil
+}
+
+// durations returns the recorded duration in seconds of the given modules for the given command, by module path.
+// Modules without a recorded duration are left out.
+func (history *runHistory) durations(stackPath string, terraformCommand string, modules map[string]*runningModule) map[string]float64 {
+	durations := map[string]float64{}
+	for path := range modules {
+		if duration, ok := history.Commands[terraformCommand][historyKey(stackPath, path)]; ok {
+			durations[path] = duration
+		}
+	}
+	return durations
+}
+
+// record stores the duration of the modules that ran successfully and writes the history file.
+func (history *runHistory) record(stackPath string, terraformCommand string, modules map[string]*runningModule) error {
+	durations, ok := history.Commands[terraformCommand]
+	if !ok {
+		durations = map[string]float64{}
+		history.Commands[terraformCommand] = durations
+	}
+
+	for path, module := range modules {
+		if module.Err != nil || module.StartTime.IsZero() || module.EndTime.IsZero() {
+			continue
+		}
+		durations[historyKey(stackPath, path)] = module.EndTime.Sub(module.StartTime).Seconds()
+	}
+
+	contents, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(history.path), os.ModePerm); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	// Write into a temporary file first so that an interrupted write never leaves a truncated history behind.
+	tmpPath := history.path + ".tmp"
+	if err := os.WriteFile(tmpPath, contents, os.FileMode(0644)); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	return errors.WithStackTrace(os.Rename(tmpPath, history.path))
+}
+
+// historyKey returns the key of the module at the given path in the history.
+func historyKey(stackPath string, path string) string {
+	if relPath, err := filepath.Rel(stackPath, path); err == nil {
+		return filepath.ToSlash(relPath)
+	}
+	return filepath.ToSlash(path)
+}
+
+// prioritizeModules sets the priority of every module to the length of the longest chain of modules that starts with
+// it, i.e. its own expected duration plus the longest chain of the modules that wait for it. The expected duration of
+// a module is taken from durations, and defaults to the average of the known durations, or 1 if there are none. Under
+// a parallelism cap, the modules on the slowest chains then start first.
+func prioritizeModules(modules map[string]*runningModule, durations map[string]float64) {
+	defaultDuration := 1.0
+	if len(durations) > 0 {
+		total := 0.0
+		for _, duration := range durations {
+			total += duration
+		}
+		defaultDuration = total / float64(len(durations))
+	}
+
+	priorities := map[string]float64{}
+	var priorityOf func(module *runningModule) float64
+	priorityOf = func(module *runningModule) float64 {
+		path := module.Module.Path
+		if priority, ok := priorities[path]; ok {
+			return priority
+		}
+		// The graph has no cycles at this point, this only guards against infinite recursion.
+		priorities[path] = 0
+
+		longestChain := 0.0
+		for _, toNotify := range module.NotifyWhenDone {
+			// NotifyWhenDone may refer to the modules from before the excluded ones were removed.
+			if dependent, ok := modules[toNotify.Module.Path]; ok {
+				longestChain = max(longestChain, priorityOf(dependent))
+			}
+		}
+
+		duration, ok := durations[path]
+		switch {
+		case module.Module.AssumeAlreadyApplied:
+			duration = 0
+		case !ok:
+			duration = defaultDuration
+		}
+		priorities[path] = duration + longestChain
+		return priorities[path]
+	}
+
+	for _, module := range modules {
+		module.Priority = priorityOf(module)
+	}
+}
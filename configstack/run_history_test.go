@@ -0,0 +1,125 @@
+package configstack
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/gruntwork-io/terragrunt/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createPriorityTestModules creates the modules below, where each module depends on the one above it:
+//
+//	slow-vpc  fast-a  fast-b  fast-c
+//	slow-app
+//
+// Every module records the order it started in.
+func createPriorityTestModules(t *testing.T, stackDir string, started *[]string, mutex *sync.Mutex) []*TerraformModule {
+	newModule := func(name string, dependencies ...*TerraformModule) *TerraformModule {
+		executed := false
+		opts := optionsWithMockTerragruntCommand(t, name, nil, &executed)
+		opts.RunTerragrunt = func(_ *options.TerragruntOptions) error {
+			mutex.Lock()
+			*started = append(*started, name)
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}
+		return &TerraformModule{
+			Path:              filepath.Join(stackDir, name),
+			Dependencies:      dependencies,
+			Config:            config.TerragruntConfig{},
+			TerragruntOptions: opts,
+		}
+	}
+
+	slowVpc := newModule("slow-vpc")
+	return []*TerraformModule{
+		newModule("fast-a"), newModule("fast-b"), newModule("fast-c"), slowVpc, newModule("slow-app", slowVpc),
+	}
+}
+
+func TestPrioritizeModules(t *testing.T) {
+	t.Parallel()
+
+	var started []string
+	var mutex sync.Mutex
+	modules, err := toRunningModules(createPriorityTestModules(t, "/stack", &started, &mutex), NormalOrder)
+	require.NoError(t, err)
+
+	prioritizeModules(modules, map[string]float64{"/stack/slow-vpc": 60, "/stack/slow-app": 120, "/stack/fast-a": 5})
+
+	// The modules without a duration get the average duration of the others.
+	assert.Equal(t, 180.0, modules["/stack/slow-vpc"].Priority)
+	assert.Equal(t, 120.0, modules["/stack/slow-app"].Priority)
+	assert.Equal(t, 5.0, modules["/stack/fast-a"].Priority)
+	assert.Equal(t, 185.0/3, modules["/stack/fast-b"].Priority)
+}
+
+func TestRunModulesStartsLongestChainFirst(t *testing.T) {
+	t.Parallel()
+
+	var started []string
+	var mutex sync.Mutex
+	modules, err := toRunningModules(createPriorityTestModules(t, "/stack", &started, &mutex), NormalOrder)
+	require.NoError(t, err)
+	prioritizeModules(modules, map[string]float64{"/stack/slow-vpc": 60, "/stack/slow-app": 120, "/stack/fast-a": 5, "/stack/fast-b": 5, "/stack/fast-c": 5})
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	require.NoError(t, runModules(opts, modules, 1, nil))
+
+	// slow-app becomes ready when slow-vpc finishes, and has a longer chain than the fast modules still waiting.
+	assert.Equal(t, []string{"slow-vpc", "slow-app", "fast-a", "fast-b", "fast-c"}, started)
+}
+
+func TestRunHistoryRecord(t *testing.T) {
+	t.Parallel()
+
+	stackDir := t.TempDir()
+	vpc := newRunningModule(&TerraformModule{Path: filepath.Join(stackDir, "vpc")})
+	vpc.StartTime = time.Now()
+	vpc.EndTime = vpc.StartTime.Add(90 * time.Second)
+	app := newRunningModule(&TerraformModule{Path: filepath.Join(stackDir, "app")})
+	app.StartTime = time.Now()
+	app.EndTime = app.StartTime.Add(time.Second)
+	app.Err = assert.AnError
+	modules := map[string]*runningModule{vpc.Module.Path: vpc, app.Module.Path: app}
+
+	// The download dir is created when the history is first written.
+	downloadDir := filepath.Join(stackDir, util.TerragruntCacheDir)
+	history, err := loadRunHistory(downloadDir, stackDir)
+	require.NoError(t, err)
+	require.NoError(t, history.record(stackDir, "apply", modules))
+	assert.FileExists(t, runHistoryPath(downloadDir, stackDir))
+
+	// Only the duration of the modules that succeeded is recorded, by path relative to the stack.
+	reloaded, err := loadRunHistory(downloadDir, stackDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]float64{"apply": {"vpc": 90}}, reloaded.Commands)
+	assert.Equal(t, map[string]float64{vpc.Module.Path: 90}, reloaded.durations(stackDir, "apply", modules))
+	assert.Empty(t, reloaded.durations(stackDir, "plan", modules))
+
+	// Another stack sharing the download dir has its own history.
+	other, err := loadRunHistory(downloadDir, filepath.Join(stackDir, "other"))
+	require.NoError(t, err)
+	assert.Empty(t, other.Commands)
+}
+
+func TestLoadRunHistoryInvalid(t *testing.T) {
+	t.Parallel()
+
+	downloadDir := t.TempDir()
+	require.NoError(t, os.WriteFile(runHistoryPath(downloadDir, "stack"), []byte("not json"), 0644))
+
+	history, err := loadRunHistory(downloadDir, "stack")
+	assert.IsType(t, RunHistoryParseError{}, errors.Unwrap(err))
+	assert.Empty(t, history.Commands)
+}
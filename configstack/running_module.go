@@ -3,7 +3,10 @@ package configstack
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gruntwork-io/terragrunt/options"
 
@@ -24,6 +27,36 @@ const (
 	channelSize = 1000 // Use a huge buffer to ensure senders are never blocked
 )
 
+var moduleStatusNames = map[ModuleStatus]string{
+	Waiting:  "waiting",
+	Running:  "running",
+	Finished: "finished",
+}
+
+// String returns the human-readable name of the status.
+func (status ModuleStatus) String() string {
+	if name, ok := moduleStatusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(status))
+}
+
+// MarshalText encodes the status by name, so that it is readable in the run journal.
+func (status ModuleStatus) MarshalText() ([]byte, error) {
+	return []byte(status.String()), nil
+}
+
+// UnmarshalText decodes a status previously encoded with MarshalText.
+func (status *ModuleStatus) UnmarshalText(text []byte) error {
+	for value, name := range moduleStatusNames {
+		if name == string(text) {
+			*status = value
+			return nil
+		}
+	}
+	return errors.WithStackTrace(UnrecognizedModuleStatus(text))
+}
+
 // Represents a module we are trying to "run" (i.e. apply or destroy) as part of the apply-all or destroy-all command
 type runningModule struct {
 	Module         *TerraformModule
@@ -33,6 +66,16 @@ type runningModule struct {
 	Dependencies   map[string]*runningModule
 	NotifyWhenDone []*runningModule
 	FlagExcluded   bool
+	StartTime      time.Time
+	EndTime        time.Time
+	// Set by run-all drift when the refresh-only plan of the module found drift.
+	Drifted bool
+	// Buffered output of the module, if it is grouped with --terragrunt-group-output.
+	Output *groupedOutput
+	// Modules with a higher priority get a slot of the parallelism first. See prioritizeModules.
+	Priority float64
+	// Errors of the terraform commands of the module that matched a rule of its errors block.
+	ErrorRuleMatches []options.ErrorRuleMatch
 }
 
 // This controls in what order dependencies should be enforced between modules
@@ -66,7 +109,7 @@ func RunModules(opts *options.TerragruntOptions, modules []*TerraformModule, par
 	if err != nil {
 		return err
 	}
-	return runModules(opts, runningModules, parallelism)
+	return runModules(opts, runningModules, parallelism, nil)
 }
 
 // Run the given map of module path to runningModule. To "run" a module, execute the RunTerragrunt command in its
@@ -77,7 +120,7 @@ func RunModulesReverseOrder(opts *options.TerragruntOptions, modules []*Terrafor
 	if err != nil {
 		return err
 	}
-	return runModules(opts, runningModules, parallelism)
+	return runModules(opts, runningModules, parallelism, nil)
 }
 
 // Run the given map of module path to runningModule. To "run" a module, execute the RunTerragrunt command in its
@@ -87,7 +130,7 @@ func RunModulesIgnoreOrder(opts *options.TerragruntOptions, modules []*Terraform
 	if err != nil {
 		return err
 	}
-	return runModules(opts, runningModules, parallelism)
+	return runModules(opts, runningModules, parallelism, nil)
 }
 
 // Convert the list of modules to a map from module path to a runningModule struct. This struct contains information
@@ -168,15 +211,52 @@ func removeFlagExcluded(modules map[string]*runningModule) map[string]*runningMo
 // Run the given map of module path to runningModule. To "run" a module, execute the RunTerragrunt command in its
 // TerragruntOptions object. The modules will be executed in an order determined by their inter-dependencies, using
 // as much concurrency as possible.
-func runModules(opts *options.TerragruntOptions, modules map[string]*runningModule, parallelism int) error {
+//
+// If a journal is given, the status of every module is recorded in it before the run starts and as each module finishes.
+//
+// If opts.FailFast is set, the first module failure cancels the run: the modules that have not started yet are not run
+// and the commands of the running modules are interrupted.
+//
+// On top of the given parallelism, the modules that share the same value of a label listed in opts.LabelParallelism
+// are limited to the parallelism of that label. When more modules are ready than there are free slots, the modules with
+// the highest Priority start first.
+func runModules(opts *options.TerragruntOptions, modules map[string]*runningModule, parallelism int, journal *runJournal) error {
 	var waitGroup sync.WaitGroup
-	var semaphore = make(chan struct{}, parallelism) // Make a semaphore from a buffered channel
+
+	limits, err := newConcurrencyLimits(parallelism, opts.LabelParallelism)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if opts.FailFast {
+		for _, module := range modules {
+			module.Module.TerragruntOptions.CancelCtx = ctx
+		}
+	}
+
+	if journal != nil {
+		recordInJournal(opts, journal, runningModulesList(modules)...)
+	}
+
+	limits.global.track(modules)
+
+	// Without fail-fast, a failure only stops the modules that depend on the failed module.
+	cancelOnFailure := cancel
+	if !opts.FailFast {
+		cancelOnFailure = nil
+	}
 
 	for _, module := range modules {
 		waitGroup.Add(1)
 		go func(module *runningModule) {
 			defer waitGroup.Done()
-			module.runModuleWhenReady(opts, semaphore)
+			module.runModuleWhenReady(ctx, cancelOnFailure, opts, limits)
+			if journal != nil {
+				recordInJournal(opts, journal, module)
+			}
 		}(module)
 	}
 
@@ -185,21 +265,59 @@ func runModules(opts *options.TerragruntOptions, modules map[string]*runningModu
 	return collectErrors(modules)
 }
 
+// recordInJournal writes the status of the given modules to the journal. Failing to write the journal does not fail the
+// run, so the error is only logged.
+func recordInJournal(opts *options.TerragruntOptions, journal *runJournal, modules ...*runningModule) {
+	if err := journal.record(modules...); err != nil {
+		opts.Logger.Warnf("Failed to write the run journal %s: %v", journal.path, err)
+	}
+}
+
+// runningModulesList returns the modules of the given map as a list.
+func runningModulesList(modules map[string]*runningModule) []*runningModule {
+	list := make([]*runningModule, 0, len(modules))
+	for _, module := range modules {
+		list = append(list, module)
+	}
+	return list
+}
+
 // Collect the errors from the given modules and return a single error object to represent them, or nil if no errors
-// occurred
+// occurred. If any of the modules was cancelled, the error lists the cancelled modules separately from the errors of the
+// modules that failed.
 func collectErrors(modules map[string]*runningModule) error {
 	var result *multierror.Error
+	cancelledModules := []string{}
 	for _, module := range modules {
-		if module.Err != nil {
-			result = multierror.Append(result, module.Err)
+		if module.Err == nil {
+			continue
+		}
+		if isModuleCancelled(module.Err) {
+			cancelledModules = append(cancelledModules, module.Module.Path)
+			continue
 		}
+		result = multierror.Append(result, module.Err)
+	}
+
+	if len(cancelledModules) > 0 {
+		sort.Strings(cancelledModules)
+		return errors.WithStackTrace(RunCancelledError{Err: result.ErrorOrNil(), CancelledModules: cancelledModules})
 	}
 
 	return result.ErrorOrNil()
 }
 
-// Run a module once all of its dependencies have finished executing.
-func (module *runningModule) runModuleWhenReady(opts *options.TerragruntOptions, semaphore chan struct{}) {
+// Run a module once all of its dependencies have finished executing. If ctx is cancelled before the module starts,
+// or while it is running, the module finishes with a ModuleCancelled error. If cancelOnFailure is not nil, it is called
+// when the module fails, before any other module can take its place.
+func (module *runningModule) runModuleWhenReady(ctx context.Context, cancelOnFailure context.CancelFunc, opts *options.TerragruntOptions, limits *concurrencyLimits) {
+	// Modules that are already finished (e.g. they succeeded in the run that is being resumed) only need to notify
+	// the modules waiting on them.
+	if module.Status == Finished {
+		limits.global.finished(module)
+		module.moduleFinished(nil)
+		return
+	}
 
 	err := telemetry.Telemetry(opts, "wait_for_module_ready", map[string]interface{}{
 		"path":             module.Module.Path,
@@ -208,18 +326,36 @@ func (module *runningModule) runModuleWhenReady(opts *options.TerragruntOptions,
 		return module.waitForDependencies()
 	})
 
-	semaphore <- struct{}{} // Add one to the buffered channel. Will block if parallelism limit is met
-	defer func() {
-		<-semaphore // Remove one from the buffered channel
-	}()
+	// The dependents of a cancelled module are cancelled too, rather than failed.
+	if dependencyErr, ok := err.(DependencyFinishedWithError); ok && isModuleCancelled(dependencyErr.Err) {
+		err = ModuleCancelled{Module: module.Module}
+	}
+
+	release := limits.acquire(module)
+	defer release()
 	if err == nil {
 		err = telemetry.Telemetry(opts, "run_module", map[string]interface{}{
 			"path":             module.Module.Path,
 			"terraformCommand": module.Module.TerragruntOptions.TerraformCommand,
 		}, func(childCtx context.Context) error {
-			return module.runNow()
+			if ctx.Err() != nil {
+				return ModuleCancelled{Module: module.Module}
+			}
+			if err := module.runNow(); err != nil {
+				if ctx.Err() != nil {
+					return ModuleCancelled{Module: module.Module, Err: err}
+				}
+				return err
+			}
+			return nil
 		})
 	}
+
+	if err != nil && cancelOnFailure != nil && ctx.Err() == nil && !isModuleCancelled(err) {
+		opts.Logger.Errorf("Module %s failed, cancelling the remaining modules because of --terragrunt-fail-fast", module.Module.Path)
+		cancelOnFailure()
+	}
+	limits.global.finished(module)
 	module.moduleFinished(err)
 }
 
@@ -255,6 +391,13 @@ func (module *runningModule) runNow() error {
 		return nil
 	} else {
 		module.Module.TerragruntOptions.Logger.Debugf("Running module %s now", module.Module.Path)
+		module.StartTime = time.Now()
+		defer func() {
+			module.EndTime = time.Now()
+		}()
+		module.Module.TerragruntOptions.OnErrorRuleMatch = func(match options.ErrorRuleMatch) {
+			module.ErrorRuleMatches = append(module.ErrorRuleMatches, match)
+		}
 		return module.Module.TerragruntOptions.RunTerragrunt(module.Module.TerragruntOptions)
 	}
 }
@@ -270,6 +413,10 @@ func (module *runningModule) moduleFinished(moduleErr error) {
 	module.Status = Finished
 	module.Err = moduleErr
 
+	if module.Output != nil {
+		module.flushOutput(moduleErr)
+	}
+
 	for _, toNotify := range module.NotifyWhenDone {
 		toNotify.DependencyDone <- module
 	}
@@ -294,6 +441,48 @@ func (this DependencyFinishedWithError) ExitStatus() (int, error) {
 	return -1, this
 }
 
+// ModuleCancelled is the error of a module that was not run, or was interrupted, because the run was cancelled.
+type ModuleCancelled struct {
+	Module *TerraformModule
+	Err    error
+}
+
+func (err ModuleCancelled) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("Module %s was interrupted because the run was cancelled: %v", err.Module.Path, err.Err)
+	}
+	return fmt.Sprintf("Module %s was not run because the run was cancelled", err.Module.Path)
+}
+
+func isModuleCancelled(err error) bool {
+	_, ok := errors.Unwrap(err).(ModuleCancelled)
+	return ok
+}
+
+// RunCancelledError is returned when some of the modules were cancelled, e.g. because of --terragrunt-fail-fast. Err
+// holds the errors of the modules that failed.
+type RunCancelledError struct {
+	Err              error
+	CancelledModules []string
+}
+
+func (err RunCancelledError) Error() string {
+	return fmt.Sprintf("The run was cancelled. %d module(s) were cancelled: %s. Errors of the failed modules: %v", len(err.CancelledModules), strings.Join(err.CancelledModules, ", "), err.Err)
+}
+
+func (err RunCancelledError) ExitStatus() (int, error) {
+	if exitCode, exitCodeErr := shell.GetExitCode(err.Err); exitCodeErr == nil {
+		return exitCode, nil
+	}
+	return -1, err
+}
+
+type UnrecognizedModuleStatus string
+
+func (err UnrecognizedModuleStatus) Error() string {
+	return fmt.Sprintf("Unrecognized module status %q", string(err))
+}
+
 type DependencyNotFoundWhileCrossLinking struct {
 	Module     *runningModule
 	Dependency *TerraformModule
@@ -1,9 +1,12 @@
 package configstack
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/gruntwork-io/terragrunt/config"
 	"github.com/gruntwork-io/terragrunt/options"
 	"github.com/stretchr/testify/assert"
@@ -1782,3 +1785,121 @@ func TestRemoveFlagExcludedOneExcludeWithDependencies(t *testing.T) {
 
 	assertRunningModuleMapsEqual(t, expected, actual, true)
 }
+
+func TestRunModulesFailFastCancelsRunningAndDependentModules(t *testing.T) {
+	t.Parallel()
+
+	bStarted := make(chan struct{})
+
+	aRan := false
+	expectedErrA := fmt.Errorf("Expected error for module a")
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+	moduleA.TerragruntOptions.RunTerragrunt = func(_ *options.TerragruntOptions) error {
+		aRan = true
+		<-bStarted
+		return expectedErrA
+	}
+
+	bRan := false
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", nil, &bRan),
+	}
+	moduleB.TerragruntOptions.RunTerragrunt = func(opts *options.TerragruntOptions) error {
+		bRan = true
+		close(bStarted)
+		select {
+		case <-opts.CancelCtx.Done():
+			return fmt.Errorf("Module b was interrupted")
+		case <-time.After(10 * time.Second):
+			return nil
+		}
+	}
+
+	cRan := false
+	moduleC := &TerraformModule{
+		Path:              "c",
+		Dependencies:      []*TerraformModule{moduleB},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "c", nil, &cRan),
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	assert.NoError(t, err)
+	opts.FailFast = true
+
+	err = RunModules(opts, []*TerraformModule{moduleA, moduleB, moduleC}, options.DefaultParallelism)
+	cancelledErr, isCancelledErr := errors.Unwrap(err).(RunCancelledError)
+	if assert.True(t, isCancelledErr, "Expected a RunCancelledError, but got: %v", err) {
+		assert.Equal(t, []string{"b", "c"}, cancelledErr.CancelledModules)
+		assertMultiErrorContains(t, cancelledErr.Err, expectedErrA)
+	}
+
+	assert.True(t, aRan)
+	assert.True(t, bRan)
+	assert.False(t, cRan)
+}
+
+func TestRunModuleWhenReadyCancelledBeforeStart(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", nil, &aRan),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	limits, err := newConcurrencyLimits(1, nil)
+	assert.NoError(t, err)
+
+	running := newRunningModule(moduleA)
+	running.runModuleWhenReady(ctx, nil, mockOptions, limits)
+
+	assert.False(t, aRan)
+	assert.Equal(t, Finished, running.Status)
+	assert.Equal(t, ModuleCancelled{Module: moduleA}, running.Err)
+}
+
+func TestRunModulesWithoutFailFastKeepsRunningIndependentModules(t *testing.T) {
+	t.Parallel()
+
+	aRan := false
+	expectedErrA := fmt.Errorf("Expected error for module a")
+	moduleA := &TerraformModule{
+		Path:              "a",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "a", expectedErrA, &aRan),
+	}
+
+	bRan := false
+	moduleB := &TerraformModule{
+		Path:              "b",
+		Dependencies:      []*TerraformModule{},
+		Config:            config.TerragruntConfig{},
+		TerragruntOptions: optionsWithMockTerragruntCommand(t, "b", nil, &bRan),
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	assert.NoError(t, err)
+
+	// With a single slot, the module that runs second only starts after the first one finished.
+	err = RunModules(opts, []*TerraformModule{moduleA, moduleB}, 1)
+	assertMultiErrorContains(t, err, expectedErrA)
+
+	assert.True(t, aRan)
+	assert.True(t, bRan)
+	assert.Nil(t, moduleB.TerragruntOptions.CancelCtx)
+}
@@ -0,0 +1,172 @@
+package configstack
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// moduleScheduler hands out the slots of the global parallelism of a run to the modules that are ready to run. When
+// several modules wait for a slot, the module with the highest priority, i.e. the longest critical path, gets it first.
+//
+// Modules become ready concurrently, each in its own goroutine, so a slot could go to whichever module asks for it
+// first rather than to the one with the highest priority. To avoid that, the scheduler tracks which modules are ready
+// but have not asked for a slot yet, and only hands out slots once they all have.
+type moduleScheduler struct {
+	mutex sync.Mutex
+	free  int
+	queue moduleQueue
+
+	// remaining is the number of dependencies each tracked module still waits for.
+	remaining map[string]int
+	// arrived holds the tracked modules that already asked for a slot.
+	arrived map[string]bool
+	// inTransit is the number of tracked modules that are ready but have not asked for a slot yet.
+	inTransit int
+}
+
+func newModuleScheduler(parallelism int) *moduleScheduler {
+	return &moduleScheduler{
+		free:      parallelism,
+		remaining: map[string]int{},
+		arrived:   map[string]bool{},
+	}
+}
+
+// track starts tracking the readiness of the given modules. The modules that already finished, e.g. because the run
+// is resumed, never ask for a slot and are not tracked, and the modules don't wait for them.
+func (scheduler *moduleScheduler) track(modules map[string]*runningModule) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	for path, module := range modules {
+		if module.Status == Finished {
+			continue
+		}
+		// The dependencies are looked up by path, as the status is set on the modules of the run.
+		remaining := 0
+		for dependencyPath := range module.Dependencies {
+			if dependency, ok := modules[dependencyPath]; !ok || dependency.Status != Finished {
+				remaining++
+			}
+		}
+		scheduler.remaining[path] = remaining
+		if remaining == 0 {
+			scheduler.inTransit++
+		}
+	}
+}
+
+// arrive records that the module is about to ask for a slot, for the modules that must wait for other semaphores
+// before they do. A module may arrive before all its dependencies are done, e.g. if one of them failed.
+func (scheduler *moduleScheduler) arrive(module *runningModule) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	scheduler.arriveLocked(module)
+	scheduler.dispatch()
+}
+
+// arriveLocked records the arrival of the module, if it did not arrive yet. Must be called with the mutex held.
+func (scheduler *moduleScheduler) arriveLocked(module *runningModule) {
+	path := module.Module.Path
+	remaining, tracked := scheduler.remaining[path]
+	if !tracked || scheduler.arrived[path] {
+		return
+	}
+	scheduler.arrived[path] = true
+	if remaining == 0 {
+		scheduler.inTransit--
+	}
+}
+
+// finished records that the module is done, which makes the modules waiting only for it ready. It must be called
+// before the waiting modules are notified. The modules that finished before the run started were never waited for.
+func (scheduler *moduleScheduler) finished(module *runningModule) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	if _, tracked := scheduler.remaining[module.Module.Path]; !tracked {
+		return
+	}
+	for _, toNotify := range module.NotifyWhenDone {
+		path := toNotify.Module.Path
+		remaining, tracked := scheduler.remaining[path]
+		if !tracked || remaining == 0 || scheduler.arrived[path] {
+			continue
+		}
+		scheduler.remaining[path] = remaining - 1
+		if remaining == 1 {
+			scheduler.inTransit++
+		}
+	}
+}
+
+// acquire blocks until the module gets a slot.
+func (scheduler *moduleScheduler) acquire(module *runningModule) {
+	scheduler.mutex.Lock()
+	scheduler.arriveLocked(module)
+	waiter := &moduleWaiter{module: module, granted: make(chan struct{})}
+	heap.Push(&scheduler.queue, waiter)
+	scheduler.dispatch()
+	scheduler.mutex.Unlock()
+
+	<-waiter.granted
+}
+
+// release gives back the slot of a module that finished running.
+func (scheduler *moduleScheduler) release() {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	scheduler.free++
+	scheduler.dispatch()
+}
+
+// dispatch hands out the free slots to the waiting modules with the highest priority, once all the ready modules
+// asked for one. Must be called with the mutex held.
+func (scheduler *moduleScheduler) dispatch() {
+	if scheduler.inTransit > 0 {
+		return
+	}
+	for scheduler.free > 0 && scheduler.queue.Len() > 0 {
+		waiter := heap.Pop(&scheduler.queue).(*moduleWaiter)
+		scheduler.free--
+		close(waiter.granted)
+	}
+}
+
+// moduleWaiter is a module waiting for a slot.
+type moduleWaiter struct {
+	module  *runningModule
+	granted chan struct{}
+}
+
+// moduleQueue is a priority queue of the modules waiting for a slot, implementing heap.Interface. Modules with the
+// same priority are sorted by path, so that the order does not depend on the order they arrived in.
+type moduleQueue []*moduleWaiter
+
+func (queue moduleQueue) Len() int {
+	return len(queue)
+}
+
+func (queue moduleQueue) Less(i, j int) bool {
+	if queue[i].module.Priority != queue[j].module.Priority {
+		return queue[i].module.Priority > queue[j].module.Priority
+	}
+	return queue[i].module.Module.Path < queue[j].module.Module.Path
+}
+
+func (queue moduleQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *moduleQueue) Push(waiter any) {
+	*queue = append(*queue, waiter.(*moduleWaiter))
+}
+
+func (queue *moduleQueue) Pop() any {
+	old := *queue
+	waiter := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return waiter
+}
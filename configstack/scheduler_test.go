@@ -0,0 +1,176 @@
+package configstack
+
+import (
+	"container/heap"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/config"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerTestRun records the modules started by a run, in the order they started, and how many of them ran at the
+// same time, overall and by value of the account label.
+type schedulerTestRun struct {
+	mutex           sync.Mutex
+	started         []string
+	running         map[string]int
+	maxRunning      map[string]int
+	totalRunning    int
+	maxTotalRunning int
+}
+
+func newSchedulerTestRun() *schedulerTestRun {
+	return &schedulerTestRun{running: map[string]int{}, maxRunning: map[string]int{}}
+}
+
+// newModule returns a module that records its run in the test run, and fails with the given error.
+func (run *schedulerTestRun) newModule(t *testing.T, path string, labels map[string]string, runErr error, dependencies ...*TerraformModule) *TerraformModule {
+	executed := false
+	opts := optionsWithMockTerragruntCommand(t, path, nil, &executed)
+	opts.RunTerragrunt = func(_ *options.TerragruntOptions) error {
+		account := labels["account"]
+
+		run.mutex.Lock()
+		run.started = append(run.started, path)
+		run.running[account]++
+		run.totalRunning++
+		run.maxRunning[account] = max(run.maxRunning[account], run.running[account])
+		run.maxTotalRunning = max(run.maxTotalRunning, run.totalRunning)
+		run.mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		run.mutex.Lock()
+		run.running[account]--
+		run.totalRunning--
+		run.mutex.Unlock()
+		return runErr
+	}
+
+	return &TerraformModule{
+		Path:              path,
+		Dependencies:      dependencies,
+		Config:            config.TerragruntConfig{Labels: labels},
+		TerragruntOptions: opts,
+	}
+}
+
+// runSchedulerTestModules runs the given modules with the given priorities and parallelism, and returns the error of
+// the run along with the running modules.
+func runSchedulerTestModules(t *testing.T, opts *options.TerragruntOptions, modules []*TerraformModule, priorities map[string]float64, parallelism int) (map[string]*runningModule, error) {
+	runningModules, err := toRunningModules(modules, NormalOrder)
+	require.NoError(t, err)
+	for path, priority := range priorities {
+		runningModules[path].Priority = priority
+	}
+
+	return runningModules, runModules(opts, runningModules, parallelism, nil)
+}
+
+func TestModuleQueueOrdersByPriorityThenPath(t *testing.T) {
+	t.Parallel()
+
+	queue := moduleQueue{}
+	for path, priority := range map[string]float64{"b": 10, "a": 10, "c": 20, "d": 5} {
+		waiter := &moduleWaiter{module: &runningModule{Module: &TerraformModule{Path: path}, Priority: priority}}
+		heap.Push(&queue, waiter)
+	}
+
+	order := []string{}
+	for queue.Len() > 0 {
+		order = append(order, heap.Pop(&queue).(*moduleWaiter).module.Module.Path)
+	}
+	assert.Equal(t, []string{"c", "a", "b", "d"}, order)
+}
+
+func TestRunModulesLongestPathFirstUnderParallelism(t *testing.T) {
+	t.Parallel()
+
+	run := newSchedulerTestRun()
+	modules := []*TerraformModule{
+		run.newModule(t, "a", nil, nil), run.newModule(t, "b", nil, nil), run.newModule(t, "c", nil, nil), run.newModule(t, "d", nil, nil),
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	_, err = runSchedulerTestModules(t, opts, modules, map[string]float64{"a": 30, "b": 20, "c": 10, "d": 40}, 2)
+	require.NoError(t, err)
+
+	// The two modules with the longest paths take the two slots, and the others follow as the slots are released.
+	require.Len(t, run.started, 4)
+	assert.ElementsMatch(t, []string{"d", "a"}, run.started[:2])
+	assert.Equal(t, []string{"b", "c"}, run.started[2:])
+	assert.Equal(t, 2, run.maxTotalRunning)
+}
+
+func TestRunModulesDependencyFailureReleasesSlots(t *testing.T) {
+	t.Parallel()
+
+	run := newSchedulerTestRun()
+	vpc := run.newModule(t, "vpc", nil, assert.AnError)
+	app := run.newModule(t, "app", nil, nil, vpc)
+	db := run.newModule(t, "db", nil, nil, app)
+	other := run.newModule(t, "other", nil, nil)
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	runningModules, err := runSchedulerTestModules(t, opts, []*TerraformModule{vpc, app, db, other}, map[string]float64{"vpc": 30, "app": 20, "db": 10, "other": 1}, 1)
+	require.Error(t, err)
+
+	// The dependents of the failed module are not run, but they don't keep the slot from the other modules.
+	assert.Equal(t, []string{"vpc", "other"}, run.started)
+	assert.Equal(t, assert.AnError, errors.Unwrap(runningModules["vpc"].Err))
+	assert.IsType(t, DependencyFinishedWithError{}, runningModules["app"].Err)
+	assert.IsType(t, DependencyFinishedWithError{}, runningModules["db"].Err)
+	assert.NoError(t, runningModules["other"].Err)
+}
+
+func TestRunModulesResumedFinishedModules(t *testing.T) {
+	t.Parallel()
+
+	run := newSchedulerTestRun()
+	vpc := run.newModule(t, "vpc", nil, nil)
+	app := run.newModule(t, "app", nil, nil, vpc)
+	other := run.newModule(t, "other", nil, nil)
+
+	runningModules, err := toRunningModules([]*TerraformModule{vpc, app, other}, NormalOrder)
+	require.NoError(t, err)
+	runningModules["app"].Priority = 20
+	runningModules["other"].Priority = 10
+	// vpc succeeded in the run that is resumed, so app is ready as soon as the run starts.
+	runningModules["vpc"].Status = Finished
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	require.NoError(t, runModules(opts, runningModules, 1, nil))
+
+	assert.Equal(t, []string{"app", "other"}, run.started)
+}
+
+func TestRunModulesLabelLimitsDoNotHoldBackOtherModules(t *testing.T) {
+	t.Parallel()
+
+	run := newSchedulerTestRun()
+	prodA := run.newModule(t, "prod-a", map[string]string{"account": "prod"}, nil)
+	prodB := run.newModule(t, "prod-b", map[string]string{"account": "prod"}, nil)
+	prodC := run.newModule(t, "prod-c", map[string]string{"account": "prod"}, nil)
+	dev := run.newModule(t, "dev", map[string]string{"account": "dev"}, nil)
+
+	opts, err := options.NewTerragruntOptionsForTest("")
+	require.NoError(t, err)
+	opts.LabelParallelism = map[string]int{"account": 1}
+	_, err = runSchedulerTestModules(t, opts, []*TerraformModule{prodA, prodB, prodC, dev}, map[string]float64{"prod-a": 30, "prod-b": 30, "prod-c": 30, "dev": 1}, 2)
+	require.NoError(t, err)
+
+	// The prod modules waiting for the label slot don't take the global slot that the dev module, with the lowest
+	// priority, can use.
+	require.Len(t, run.started, 4)
+	assert.Contains(t, run.started[:2], "dev")
+	assert.Equal(t, 1, run.maxRunning["prod"])
+	assert.LessOrEqual(t, run.maxTotalRunning, 2)
+}
@@ -0,0 +1,96 @@
+package configstack
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/go-commons/errors"
+
+	"github.com/gruntwork-io/terragrunt/options"
+)
+
+// flagModulesOutsideShard flags as excluded all the modules that are not part of the shard passed via the
+// terragrunt-shard CLI flag, so that a run-all command can be split across several CI jobs. The modules of each group
+// of the run graph are distributed round-robin across the shards, in the order of their path relative to the working
+// dir, continuing from the shard that follows the last module of the previous group. This way every shard gets a
+// balanced slice of each group, and the assignment only depends on the stack, not on the machine it runs on. Like
+// terragrunt-filter, sharding only narrows down the set of modules: the modules that were already excluded are not
+// distributed.
+func flagModulesOutsideShard(modules []*TerraformModule, terragruntOptions *options.TerragruntOptions) ([]*TerraformModule, error) {
+	if terragruntOptions.Shard == "" {
+		return modules, nil
+	}
+
+	shardIndex, shardCount, err := parseShard(terragruntOptions.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := (&Stack{Modules: modules}).getModuleRunGraph(terragruntOptions.TerraformCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	inShard := map[string]bool{}
+	assigned := 0
+	for _, group := range groups {
+		relPaths := map[string]string{}
+		for _, module := range group {
+			relPaths[module.Path] = shardPath(module.Path, terragruntOptions.WorkingDir)
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return relPaths[group[i].Path] < relPaths[group[j].Path]
+		})
+
+		for _, module := range group {
+			if assigned%shardCount == shardIndex-1 {
+				inShard[module.Path] = true
+			}
+			assigned++
+		}
+	}
+
+	for _, module := range modules {
+		// The modules that are assumed to be already applied are not run, so they are left as they are.
+		if !inShard[module.Path] && !module.AssumeAlreadyApplied {
+			module.FlagExcluded = true
+		}
+	}
+
+	terragruntOptions.Logger.Debugf("%d of %d modules are part of shard %s", len(inShard), assigned, terragruntOptions.Shard)
+
+	return modules, nil
+}
+
+// parseShard parses a shard written as i/N, where N is the number of shards and i, between 1 and N, the shard to run.
+func parseShard(shard string) (int, int, error) {
+	index, count, found := strings.Cut(shard, "/")
+	if !found {
+		return 0, 0, errors.WithStackTrace(InvalidShard(shard))
+	}
+
+	shardIndex, err := strconv.Atoi(strings.TrimSpace(index))
+	if err != nil {
+		return 0, 0, errors.WithStackTrace(InvalidShard(shard))
+	}
+	shardCount, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return 0, 0, errors.WithStackTrace(InvalidShard(shard))
+	}
+	if shardCount < 1 || shardIndex < 1 || shardIndex > shardCount {
+		return 0, 0, errors.WithStackTrace(InvalidShard(shard))
+	}
+
+	return shardIndex, shardCount, nil
+}
+
+// shardPath returns the path of the module relative to the working dir, with forward slashes, so that the modules are
+// ordered the same way whatever the directory the stack was checked out in.
+func shardPath(path string, workingDir string) string {
+	if relPath, err := filepath.Rel(workingDir, path); err == nil {
+		return filepath.ToSlash(relPath)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,85 @@
+package configstack
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/terragrunt/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createShardTestModules creates the modules below, where app, db and cache depend on vpc, and dns is excluded:
+//
+//	vpc  iam  dns (excluded)
+//	app  db  cache
+func createShardTestModules(stackDir string) []*TerraformModule {
+	newModule := func(path string, dependencies ...*TerraformModule) *TerraformModule {
+		return &TerraformModule{Path: filepath.Join(stackDir, path), Dependencies: dependencies}
+	}
+
+	vpc := newModule("vpc")
+	iam := newModule("iam")
+	dns := newModule("dns")
+	dns.FlagExcluded = true
+
+	return []*TerraformModule{newModule("app", vpc), newModule("db", vpc), newModule("cache", vpc), vpc, iam, dns}
+}
+
+// shardModules returns the relative paths of the modules of the given shard of a stack checked out in stackDir.
+func shardModules(t *testing.T, stackDir string, shard string) []string {
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(stackDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+	opts.WorkingDir = stackDir
+	opts.TerraformCommand = "plan"
+	opts.Shard = shard
+
+	modules, err := flagModulesOutsideShard(createShardTestModules(stackDir), opts)
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, module := range modules {
+		if !module.FlagExcluded {
+			paths = append(paths, filepath.Base(module.Path))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestFlagModulesOutsideShard(t *testing.T) {
+	t.Parallel()
+
+	// The groups are [iam vpc] and [app cache db]: each shard gets a slice of each group.
+	assert.Equal(t, []string{"app", "db", "iam"}, shardModules(t, "/stack", "1/2"))
+	assert.Equal(t, []string{"cache", "vpc"}, shardModules(t, "/stack", "2/2"))
+
+	// The assignment does not depend on where the stack is checked out.
+	assert.Equal(t, []string{"app", "db", "iam"}, shardModules(t, "/builds/other/checkout", "1/2"))
+}
+
+func TestFlagModulesOutsideShardCoversStackOnce(t *testing.T) {
+	t.Parallel()
+
+	for _, shardCount := range []int{1, 2, 3, 4, 7} {
+		seen := map[string]int{}
+		for shardIndex := 1; shardIndex <= shardCount; shardIndex++ {
+			for _, path := range shardModules(t, "/stack", fmt.Sprintf("%d/%d", shardIndex, shardCount)) {
+				seen[path]++
+			}
+		}
+		assert.Equal(t, map[string]int{"app": 1, "cache": 1, "db": 1, "iam": 1, "vpc": 1}, seen, "shard count %d", shardCount)
+	}
+}
+
+func TestParseShardInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, shard := range []string{"2", "0/2", "3/2", "a/2", "1/0", "-1/2"} {
+		_, _, err := parseShard(shard)
+		assert.Equal(t, InvalidShard(shard), errors.Unwrap(err), shard)
+	}
+}
@@ -76,12 +76,21 @@ func (stack *Stack) JsonModuleDeployOrder(terraformCommand string) (string, erro
 	return string(j), nil
 }
 
-// Graph creates a graphviz representation of the modules
-func (stack *Stack) Graph(terragruntOptions *options.TerragruntOptions) {
-	err := WriteDot(terragruntOptions.Writer, terragruntOptions, stack.Modules)
-	if err != nil {
-		terragruntOptions.Logger.Warnf("Failed to graph dot: %v", err)
+// Graph creates a representation of the modules in the format set with GraphFormat: graphviz (the default), mermaid
+// or json
+func (stack *Stack) Graph(terragruntOptions *options.TerragruntOptions) error {
+	var err error
+	switch terragruntOptions.GraphFormat {
+	case "", GraphFormatDot:
+		err = WriteDot(terragruntOptions.Writer, terragruntOptions, stack.Modules)
+	case GraphFormatMermaid:
+		err = WriteMermaid(terragruntOptions.Writer, terragruntOptions, stack.Modules)
+	case GraphFormatJSON:
+		err = WriteGraphJSON(terragruntOptions.Writer, terragruntOptions, stack)
+	default:
+		return errors.WithStackTrace(UnsupportedGraphFormat(terragruntOptions.GraphFormat))
 	}
+	return errors.WithStackTrace(err)
 }
 
 func (stack *Stack) Run(terragruntOptions *options.TerragruntOptions) error {
@@ -122,14 +131,86 @@ func (stack *Stack) Run(terragruntOptions *options.TerragruntOptions) error {
 		defer stack.summarizePlanAllErrors(terragruntOptions, errorStreams)
 	}
 
+	// To summarize the plans of the stack, each module saves its plan so that it can be rendered with `show -json`.
+	var plans *planFiles
+	if planSummaryEnabled(terragruntOptions) {
+		var err error
+		if plans, err = stack.savePlans(terragruntOptions); err != nil {
+			return err
+		}
+	}
+
+	var dependencyOrder DependencyOrder
 	switch {
 	case terragruntOptions.IgnoreDependencyOrder:
-		return RunModulesIgnoreOrder(terragruntOptions, stack.Modules, terragruntOptions.Parallelism)
+		dependencyOrder = IgnoreOrder
 	case stackCmd == "destroy":
-		return RunModulesReverseOrder(terragruntOptions, stack.Modules, terragruntOptions.Parallelism)
+		dependencyOrder = ReverseOrder
 	default:
-		return RunModules(terragruntOptions, stack.Modules, terragruntOptions.Parallelism)
+		dependencyOrder = NormalOrder
+	}
+
+	runningModules, err := toRunningModules(stack.Modules, dependencyOrder)
+	if err != nil {
+		return err
 	}
+
+	if stackCmd == DriftCommand {
+		prepareDriftDetection(terragruntOptions, runningModules)
+	}
+
+	if terragruntOptions.GroupOutput {
+		groupModuleOutput(terragruntOptions, runningModules)
+	}
+
+	// The durations of the previous runs are used to start the modules on the longest chains first.
+	history, err := loadRunHistory(terragruntOptions.DownloadDir, stack.Path)
+	if err != nil {
+		terragruntOptions.Logger.Warnf("Failed to read the run history, modules will be started without priority: %v", err)
+	}
+	prioritizeModules(runningModules, history.durations(stack.Path, stackCmd, runningModules))
+
+	// The journal records the result of each module, so that a failed run can be picked up with --terragrunt-resume.
+	journal := newRunJournal(terragruntOptions.DownloadDir, stack.Path, stackCmd)
+	if terragruntOptions.Resume {
+		if err := journal.resume(terragruntOptions, runningModules); err != nil {
+			return err
+		}
+	}
+	for _, module := range stack.Modules {
+		if module.FlagExcluded {
+			journal.skip(module.Path)
+		}
+	}
+
+	runErr := runModules(terragruntOptions, runningModules, terragruntOptions.Parallelism, journal)
+
+	if err := history.record(stack.Path, stackCmd, runningModules); err != nil {
+		terragruntOptions.Logger.Warnf("Failed to write the run history %s: %v", history.path, err)
+	}
+
+	if err := stack.writeRunReport(terragruntOptions, runningModules); err != nil {
+		if runErr == nil {
+			return err
+		}
+		terragruntOptions.Logger.Errorf("Failed to write the run-all report: %v", err)
+	}
+
+	if plans != nil {
+		if err := stack.summarizePlans(terragruntOptions, plans, runningModules); err != nil {
+			if runErr == nil {
+				return err
+			}
+			terragruntOptions.Logger.Errorf("Failed to summarize the run-all plan: %v", err)
+		}
+	}
+
+	// Drift detection only fails when drift is found, the modules that failed are listed in the summary.
+	if stackCmd == DriftCommand {
+		return stack.summarizeDrift(terragruntOptions, runningModules)
+	}
+
+	return runErr
 }
 
 // We inspect the error streams to give an explicit message if the plan failed because there were references to
@@ -22,3 +22,53 @@ var DEFAULT_RETRYABLE_ERRORS = []string{
 	"(?s).*Client\\.Timeout exceeded while awaiting headers.*",
 	"(?s).*Could not download module.*The requested URL returned error: 429.*",
 }
+
+// Actions of the rules of the errors block, as recorded in ErrorRuleMatch.
+const (
+	ErrorRuleActionRetry  = "retry"
+	ErrorRuleActionIgnore = "ignore"
+)
+
+// RetryRule retries the terraform commands failing with an error that matches one of the regular expressions of On, up
+// to MaxAttempts attempts, sleeping SleepInterval between the attempts, doubled after each retry if ExponentialBackoff
+// is set.
+type RetryRule struct {
+	Name               string
+	On                 []string
+	MaxAttempts        int
+	SleepInterval      time.Duration
+	ExponentialBackoff bool
+}
+
+// MaxRetrySleepInterval is the longest sleep between two retries of a rule with an exponential backoff, unless the
+// sleep interval of the rule itself is longer.
+const MaxRetrySleepInterval = time.Hour
+
+// SleepBeforeRetry returns the duration to sleep before the given retry, starting at 1. With an exponential backoff,
+// the sleep is doubled until it reaches MaxRetrySleepInterval, so that it can't overflow with many attempts.
+func (rule *RetryRule) SleepBeforeRetry(retry int) time.Duration {
+	if !rule.ExponentialBackoff || retry <= 1 {
+		return rule.SleepInterval
+	}
+
+	sleep := rule.SleepInterval
+	for i := 1; i < retry && sleep > 0 && sleep < MaxRetrySleepInterval; i++ {
+		sleep *= 2
+	}
+	return max(rule.SleepInterval, min(sleep, MaxRetrySleepInterval))
+}
+
+// IgnoreRule ignores the errors of the terraform commands that match one of the regular expressions of On, which are
+// logged as warnings along with Message, explaining why they are harmless.
+type IgnoreRule struct {
+	Name    string
+	On      []string
+	Message string
+}
+
+// ErrorRuleMatch is an error of a terraform command that matched a rule of the errors block.
+type ErrorRuleMatch struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+}
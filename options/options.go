@@ -36,6 +36,15 @@ const (
 	// Default to naming it `terragrunt_rendered.json` in the terragrunt config directory.
 	DefaultJSONOutName = "terragrunt_rendered.json"
 
+	// Dependency outputs cached on disk are reused for an hour by default.
+	DefaultDependencyOutputCacheTTLSec = 3600
+
+	// The dependency graph is printed in DOT format unless graph-dependencies is run with --format.
+	DefaultGraphFormat = "dot"
+
+	// The issues found by lint are printed as text unless it is run with --format.
+	DefaultLintFormat = "text"
+
 	DefaultTFDataDir = ".terraform"
 
 	DefaultIAMAssumeRoleDuration = 3600
@@ -62,6 +71,10 @@ type TerragruntOptions struct {
 	// Context for collection of telemetry data
 	CtxTelemetryCtx context.Context
 
+	// Context that cancels the commands run with these options when it is done, e.g. when another module of a *-all
+	// command fails with FailFast set. Running commands are interrupted first and killed if they don't exit in time.
+	CancelCtx context.Context
+
 	// Location of the Terragrunt config file
 	TerragruntConfigPath string
 
@@ -188,6 +201,21 @@ type TerragruntOptions struct {
 	// RetryableErrors is an array of regular expressions with RE2 syntax (https://github.com/google/re2/wiki/Syntax) that qualify for retrying
 	RetryableErrors []string
 
+	// Retry rules of the errors block, each with its own policy. They take precedence over RetryableErrors.
+	RetryRules []RetryRule
+
+	// Ignore rules of the errors block. They take precedence over the retry rules and RetryableErrors.
+	IgnoreRules []IgnoreRule
+
+	// Called for every error of a terraform command that matches one of RetryRules or IgnoreRules, e.g. so that
+	// run-all can report the rules that matched.
+	OnErrorRuleMatch func(ErrorRuleMatch)
+
+	// Exit codes of the terraform command that report a result rather than a failure, e.g. 2 for a plan with
+	// -detailed-exitcode that has changes. The command still returns its error, so that the exit code can be read,
+	// but the error is neither retried nor logged as a failure.
+	NonFatalExitCodes []int
+
 	// Unix-style glob of directories to exclude when running *-all commands
 	ExcludeDirs []string
 
@@ -200,12 +228,54 @@ type TerragruntOptions struct {
 	// Parallelism limits the number of commands to run concurrently during *-all commands
 	Parallelism int
 
+	// LabelParallelism limits the number of commands to run concurrently during *-all commands for modules that share
+	// the same value of a label, e.g. {"account": 2} runs at most 2 modules per account at a time. These limits apply
+	// on top of Parallelism.
+	LabelParallelism map[string]int
+
+	// If set to true, *-all commands skip the modules that finished successfully in the previous run, as recorded in
+	// the run journal, and only run the failed or never started modules along with their dependents.
+	Resume bool
+
+	// Path to the file where *-all commands write a report with the result of every module.
+	ReportFile string
+
+	// Format of the report written to ReportFile: json or junit. If empty, it is detected from the file extension.
+	ReportFormat string
+
+	// Git ref to compare the working tree against. If set, *-all commands only run the modules affected by the changes
+	// since that ref, together with the modules that depend on them.
+	ChangedSince string
+
+	// If set to true, *-all commands stop as soon as a module fails: the modules that have not started yet are
+	// cancelled and the running ones are interrupted.
+	FailFast bool
+
+	// Filter expression that selects the modules *-all commands run, based on their labels, paths and dependencies.
+	Filter string
+
+	// If set to true, run-all plan saves the plan of every module and prints a summary of the planned changes.
+	PlanSummary bool
+
+	// Path to the file where run-all plan writes the combined plan JSON of the stack. Implies PlanSummary.
+	PlanSummaryFile string
+
 	// Enable check mode, by default it's disabled.
 	Check bool
 
 	// Show diff, by default it's disabled.
 	Diff bool
 
+	// Format the dependency graph is printed in by graph-dependencies: dot, mermaid or json.
+	GraphFormat string
+
+	// Format the issues found by lint are printed in: text, json or sarif.
+	LintFormat string
+
+	// Evaluate the locals with the functions that have side effects, e.g. run_cmd, to show their value on hover in the
+	// language server, which otherwise analyzes the configs without calling them.
+	LSPEvaluateFunctions bool
+
 	// The file which hclfmt should be specifically run on
 	HclFile string
 
@@ -240,6 +310,54 @@ type TerragruntOptions struct {
 	// Enables caching of includes during partial parsing operations.
 	UsePartialParseConfigCache bool
 
+	// Directory of the on-disk cache of partially parsed configs, shared across terragrunt processes. It is only used
+	// when UsePartialParseConfigCache is set.
+	PartialParseConfigCacheDir string
+
+	// If set to true, the partially parsed configs calling run_cmd are cached on disk as well, and the commands are run
+	// again to check that their output is unchanged before reusing them.
+	PartialParseConfigCacheRunCmd bool
+
+	// Directory of the on-disk cache of dependency outputs, shared across terragrunt processes. If empty, dependency
+	// outputs are only cached in memory.
+	DependencyOutputCacheDir string
+
+	// Number of seconds dependency outputs stay valid in DependencyOutputCacheDir.
+	DependencyOutputCacheTTLSec int
+
+	// If set to true, the dependency outputs cached in DependencyOutputCacheDir are ignored and replaced by fresh ones.
+	InvalidateOutputCache bool
+
+	// Directory of the content-addressed cache of the configs included or read from remote sources. If empty, the
+	// user cache directory is used.
+	RemoteConfigCacheDir string
+
+	// The shard of the stack to run, written as i/N: run-all commands only run the i-th of N balanced slices of the
+	// modules.
+	Shard string
+
+	// If set to true, run-all buffers the output of each module and writes it as a single block once the module finishes.
+	GroupOutput bool
+
+	// If set to true along with GroupOutput, the stderr of the modules is not buffered but written as it comes.
+	GroupOutputLiveStderr bool
+
+	// The value of --terragrunt-timeout, e.g. "30m". It is parsed into Timeout.
+	TimeoutStr string
+
+	// Maximum duration of the run of a unit, i.e. of all the commands run for it. When it is exceeded, the running
+	// command is interrupted, then killed. Zero means no timeout. The timeout attribute of the terragrunt config
+	// overrides it.
+	Timeout time.Duration
+
+	// Context whose deadline is the end of the Timeout of the unit being run, set when the run of the unit starts, so
+	// that its hooks, its terraform commands and their retries all share the same deadline. Nil means no timeout.
+	TimeoutCtx context.Context
+
+	// The values of the feature flags set with --terragrunt-feature, by name. They override the defaults of the feature
+	// blocks of the config.
+	FeatureFlags map[string]string
+
 	// Include fields metadata in render-json
 	RenderJsonWithMetadata bool
 
@@ -330,13 +448,21 @@ func NewTerragruntOptions() *TerragruntOptions {
 		ModulesThatInclude:             []string{},
 		StrictInclude:                  false,
 		Parallelism:                    DefaultParallelism,
+		LabelParallelism:               map[string]int{},
+		FeatureFlags:                   map[string]string{},
+		Resume:                         false,
+		FailFast:                       false,
+		PlanSummary:                    false,
 		Check:                          false,
 		Diff:                           false,
 		FetchDependencyOutputFromState: false,
 		UsePartialParseConfigCache:     false,
+		DependencyOutputCacheTTLSec:    DefaultDependencyOutputCacheTTLSec,
 		OutputPrefix:                   "",
 		IncludeModulePrefix:            false,
 		JSONOut:                        DefaultJSONOutName,
+		GraphFormat:                    DefaultGraphFormat,
+		LintFormat:                     DefaultLintFormat,
 		TerraformImplementation:        UnknownImpl,
 		JsonLogFormat:                  false,
 		TerraformLogsToJson:            false,
@@ -414,6 +540,7 @@ func (opts *TerragruntOptions) Clone(terragruntConfigPath string) *TerragruntOpt
 	// for more info.
 	return &TerragruntOptions{
 		CtxTelemetryCtx:                opts.CtxTelemetryCtx,
+		CancelCtx:                      opts.CancelCtx,
 		TerragruntConfigPath:           terragruntConfigPath,
 		OriginalTerragruntConfigPath:   opts.OriginalTerragruntConfigPath,
 		TerraformPath:                  opts.TerraformPath,
@@ -448,19 +575,48 @@ func (opts *TerragruntOptions) Clone(terragruntConfigPath string) *TerragruntOpt
 		RetryMaxAttempts:               opts.RetryMaxAttempts,
 		RetrySleepIntervalSec:          opts.RetrySleepIntervalSec,
 		RetryableErrors:                util.CloneStringList(opts.RetryableErrors),
+		RetryRules:                     opts.RetryRules,
+		IgnoreRules:                    opts.IgnoreRules,
+		OnErrorRuleMatch:               opts.OnErrorRuleMatch,
+		NonFatalExitCodes:              opts.NonFatalExitCodes,
 		ExcludeDirs:                    opts.ExcludeDirs,
 		IncludeDirs:                    opts.IncludeDirs,
 		ModulesThatInclude:             opts.ModulesThatInclude,
 		Parallelism:                    opts.Parallelism,
+		LabelParallelism:               opts.LabelParallelism,
+		FeatureFlags:                   opts.FeatureFlags,
+		Resume:                         opts.Resume,
+		ReportFile:                     opts.ReportFile,
+		ReportFormat:                   opts.ReportFormat,
+		ChangedSince:                   opts.ChangedSince,
+		FailFast:                       opts.FailFast,
+		Filter:                         opts.Filter,
+		PlanSummary:                    opts.PlanSummary,
+		PlanSummaryFile:                opts.PlanSummaryFile,
 		StrictInclude:                  opts.StrictInclude,
 		RunTerragrunt:                  opts.RunTerragrunt,
 		AwsProviderPatchOverrides:      opts.AwsProviderPatchOverrides,
 		HclFile:                        opts.HclFile,
+		GraphFormat:                    opts.GraphFormat,
+		LintFormat:                     opts.LintFormat,
+		LSPEvaluateFunctions:           opts.LSPEvaluateFunctions,
 		JSONOut:                        opts.JSONOut,
 		Check:                          opts.Check,
 		CheckDependentModules:          opts.CheckDependentModules,
 		FetchDependencyOutputFromState: opts.FetchDependencyOutputFromState,
 		UsePartialParseConfigCache:     opts.UsePartialParseConfigCache,
+		PartialParseConfigCacheDir:     opts.PartialParseConfigCacheDir,
+		PartialParseConfigCacheRunCmd:  opts.PartialParseConfigCacheRunCmd,
+		DependencyOutputCacheDir:       opts.DependencyOutputCacheDir,
+		DependencyOutputCacheTTLSec:    opts.DependencyOutputCacheTTLSec,
+		InvalidateOutputCache:          opts.InvalidateOutputCache,
+		RemoteConfigCacheDir:           opts.RemoteConfigCacheDir,
+		Shard:                          opts.Shard,
+		GroupOutput:                    opts.GroupOutput,
+		GroupOutputLiveStderr:          opts.GroupOutputLiveStderr,
+		TimeoutStr:                     opts.TimeoutStr,
+		Timeout:                        opts.Timeout,
+		TimeoutCtx:                     opts.TimeoutCtx,
 		OutputPrefix:                   opts.OutputPrefix,
 		IncludeModulePrefix:            opts.IncludeModulePrefix,
 		FailIfBucketCreationRequired:   opts.FailIfBucketCreationRequired,
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package shell
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startInProcessGroup makes the command the leader of a new process group, so that it can be signalled along with all
+// of the processes it starts, e.g. the terraform providers.
+func startInProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// interruptProcessGroup sends SIGINT to the process group led by the command.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(processGroupTarget(cmd), syscall.SIGINT)
+}
+
+// killProcessGroup sends SIGKILL to the process group led by the command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(processGroupTarget(cmd), syscall.SIGKILL)
+}
+
+// processGroupTarget returns the pid to signal the process group led by the command, or only the command itself if it
+// was not started in a process group or session of its own.
+func processGroupTarget(cmd *exec.Cmd) int {
+	if cmd.SysProcAttr != nil && (cmd.SysProcAttr.Setpgid || cmd.SysProcAttr.Setsid) {
+		return -cmd.Process.Pid
+	}
+	return cmd.Process.Pid
+}
@@ -0,0 +1,28 @@
+//go:build windows
+// +build windows
+
+package shell
+
+import (
+	"os/exec"
+
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// Process groups can't be signalled on Windows: the command is started as usual and only the command itself is killed.
+func startInProcessGroup(cmd *exec.Cmd) {}
+
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return errors.WithStackTrace(InterruptNotSupported{})
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// InterruptNotSupported is returned when the command can't be interrupted gracefully on this platform.
+type InterruptNotSupported struct{}
+
+func (err InterruptNotSupported) Error() string {
+	return "Interrupting a process group is not supported on Windows"
+}
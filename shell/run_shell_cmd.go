@@ -3,6 +3,7 @@ package shell
 import (
 	"bytes"
 	"context"
+	goerrors "errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 
 	"github.com/hashicorp/go-version"
 
+	"golang.org/x/term"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -36,6 +39,12 @@ import (
 // Since we cannot know how the signal is sent, we should give `terraform` time to gracefully exit if it receives the signal directly from the shell, to avoid sending the second interrupt signal to `terraform`.
 const signalForwardingDelay = time.Second * 30
 
+// How long a command interrupted because its CancelCtx is done is given to exit gracefully before it is killed.
+var cancelKillDelay = time.Second * 30
+
+// How long the process group of a command that exceeded its timeout is given to exit gracefully before it is killed.
+var timeoutKillDelay = time.Second * 30
+
 const (
 	gitPrefix = "git::"
 	refsTags  = "refs/tags/"
@@ -115,6 +124,11 @@ func RunShellCommandWithOutput(
 			terragruntOptions.Logger.Debugf("Command output will be suppressed.")
 		}
 
+		// The commands left once the unit exceeded its timeout are not started at all.
+		if terragruntOptions.TimeoutCtx != nil && terragruntOptions.TimeoutCtx.Err() != nil {
+			return errors.WithStackTrace(CommandTimeoutError{Command: command, Args: args, Timeout: terragruntOptions.Timeout, WorkingDir: commandDir})
+		}
+
 		var stdoutBuf bytes.Buffer
 		var stderrBuf bytes.Buffer
 
@@ -157,6 +171,13 @@ func RunShellCommandWithOutput(
 			cmd.Stdin = os.Stdin
 			cmd.Stdout = cmdStdout
 			cmd.Stderr = cmdStderr
+			// The command runs in its own process group, so that the processes it starts are terminated with it when
+			// it exceeds its timeout. A process group of its own is in the background of the terminal though, where
+			// it would be stopped when prompting for input and would not receive Ctrl+C, so when stdin is a terminal
+			// only the command itself is terminated. A pseudo TTY command is already the leader of its own session.
+			if terragruntOptions.TimeoutCtx != nil && !term.IsTerminal(int(os.Stdin.Fd())) {
+				startInProcessGroup(cmd)
+			}
 			if err := cmd.Start(); err != nil {
 				// bad path, binary not executable, &c
 				return errors.WithStackTrace(err)
@@ -173,7 +194,20 @@ func RunShellCommandWithOutput(
 			}
 		}(&signalChannel)
 
+		// Interrupt the command if the run it belongs to is cancelled.
+		cmdDone := make(chan struct{})
+		if terragruntOptions.CancelCtx != nil {
+			go interruptOnCancel(terragruntOptions.CancelCtx, cmd, terragruntOptions.Logger, cmdDone)
+		}
+
+		// Terminate the command if the unit it belongs to runs for longer than its timeout.
+		var timedOut atomic.Bool
+		if terragruntOptions.TimeoutCtx != nil {
+			go terminateOnTimeout(terragruntOptions.TimeoutCtx, terragruntOptions.Timeout, cmd, terragruntOptions.Logger, cmdDone, &timedOut)
+		}
+
 		err := cmd.Wait()
+		close(cmdDone)
 		cmdChannel <- err
 
 		cmdOutput := CmdOutput{
@@ -181,7 +215,16 @@ func RunShellCommandWithOutput(
 			Stderr: stderrBuf.String(),
 		}
 
-		if err != nil {
+		if timedOut.Load() {
+			err = CommandTimeoutError{
+				Command:    command,
+				Args:       args,
+				Timeout:    terragruntOptions.Timeout,
+				StdOut:     stdoutBuf.String(),
+				Stderr:     stderrBuf.String(),
+				WorkingDir: cmd.Dir,
+			}
+		} else if err != nil {
 			err = ProcessExecutionError{
 				Err:        err,
 				StdOut:     stdoutBuf.String(),
@@ -292,6 +335,69 @@ func NewSignalsForwarder(signals []os.Signal, c *exec.Cmd, logger *logrus.Entry,
 	return signalChannel
 }
 
+// interruptOnCancel sends an interrupt signal to the command when ctx is done, and kills it if it is still running
+// after cancelKillDelay. Returns once the command is done.
+func interruptOnCancel(ctx context.Context, cmd *exec.Cmd, logger *logrus.Entry, cmdDone <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+	case <-cmdDone:
+		return
+	}
+
+	logger.Debugf("Run cancelled. Interrupting %s (it will be killed if it does not exit within %v)", cmd.Path, cancelKillDelay)
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		// Interrupt is not supported on every platform, e.g. Windows.
+		logger.Debugf("Error interrupting %s, killing it: %v", cmd.Path, err)
+		killProcess(cmd, logger)
+		return
+	}
+
+	select {
+	case <-time.After(cancelKillDelay):
+		logger.Warnf("%s did not exit within %v after being interrupted, killing it.", cmd.Path, cancelKillDelay)
+		killProcess(cmd, logger)
+	case <-cmdDone:
+	}
+}
+
+// terminateOnTimeout sends SIGINT to the process group of the command if it is still running when timeoutCtx is done,
+// and SIGKILL if the process group is still running timeoutKillDelay later. timedOut is set before the command is
+// signalled. Returns once the command is done.
+func terminateOnTimeout(timeoutCtx context.Context, timeout time.Duration, cmd *exec.Cmd, logger *logrus.Entry, cmdDone <-chan struct{}, timedOut *atomic.Bool) {
+	select {
+	case <-timeoutCtx.Done():
+	case <-cmdDone:
+		return
+	}
+
+	timedOut.Store(true)
+	logger.Errorf("%s did not finish within the %v timeout of the unit. Interrupting it (it will be killed if it does not exit within %v)", cmd.Path, timeout, timeoutKillDelay)
+	if err := interruptProcessGroup(cmd); err != nil {
+		logger.Debugf("Error interrupting %s, killing it: %v", cmd.Path, err)
+		killProcessGroupOf(cmd, logger)
+		return
+	}
+
+	select {
+	case <-time.After(timeoutKillDelay):
+		logger.Warnf("%s did not exit within %v after being interrupted, killing it.", cmd.Path, timeoutKillDelay)
+		killProcessGroupOf(cmd, logger)
+	case <-cmdDone:
+	}
+}
+
+func killProcessGroupOf(cmd *exec.Cmd, logger *logrus.Entry) {
+	if err := killProcessGroup(cmd); err != nil && !errors.IsError(err, os.ErrProcessDone) && !errors.IsError(err, syscall.ESRCH) {
+		logger.Errorf("Error killing %s: %v", cmd.Path, err)
+	}
+}
+
+func killProcess(cmd *exec.Cmd, logger *logrus.Entry) {
+	if err := cmd.Process.Kill(); err != nil && !errors.IsError(err, os.ErrProcessDone) {
+		logger.Errorf("Error killing %s: %v", cmd.Path, err)
+	}
+}
+
 func (signalChannel *SignalsForwarder) Close() error {
 	signal.Stop(*signalChannel)
 	*signalChannel <- nil
@@ -323,6 +429,46 @@ func GitTopLevelDir(terragruntOptions *options.TerragruntOptions, path string) (
 	return strings.TrimSpace(cmd.Stdout), nil
 }
 
+// GitChangedFiles - fetch the absolute paths of the files changed since the given ref in the git repository of the
+// passed directory, including uncommitted and untracked files
+func GitChangedFiles(terragruntOptions *options.TerragruntOptions, path string, ref string) ([]string, error) {
+	topLevelDir, err := GitTopLevelDir(terragruntOptions, path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := options.NewTerragruntOptionsWithConfigPath(path)
+	if err != nil {
+		return nil, err
+	}
+	opts.Env = terragruntOptions.Env
+	opts.Writer = io.Discard
+	opts.ErrWriter = io.Discard
+
+	// Renames are listed as a deletion and an addition, so that the old path of a moved file is reported too, and the
+	// paths are separated by NUL, so that they are not quoted by git when they contain special characters.
+	diff, err := RunShellCommandWithOutput(opts, topLevelDir, true, false, "git", "diff", "--name-only", "--no-renames", "-z", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := RunShellCommandWithOutput(opts, topLevelDir, true, false, "git", "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	changedFiles := []string{}
+	for _, name := range strings.Split(diff.Stdout+"\x00"+untracked.Stdout, "\x00") {
+		if name == "" {
+			continue
+		}
+		changedFiles = append(changedFiles, util.JoinPath(topLevelDir, name))
+	}
+
+	terragruntOptions.Logger.Debugf("Files changed since %s: %v", ref, changedFiles)
+	return changedFiles, nil
+}
+
 // GitRepoTags - fetch git repository tags from passed url
 func GitRepoTags(opts *options.TerragruntOptions, gitRepo *url.URL) ([]string, error) {
 	repoPath := gitRepo.String()
@@ -411,3 +557,24 @@ func (err ProcessExecutionError) Error() string {
 func (err ProcessExecutionError) ExitStatus() (int, error) {
 	return GetExitCode(err.Err)
 }
+
+// CommandTimeoutError is returned when a command is terminated because the unit it belongs to ran for longer than its
+// timeout. The output of the command up to that point is kept, as with ProcessExecutionError.
+type CommandTimeoutError struct {
+	Command    string
+	Args       []string
+	Timeout    time.Duration
+	StdOut     string
+	Stderr     string
+	WorkingDir string
+}
+
+func (err CommandTimeoutError) Error() string {
+	return fmt.Sprintf("[%s] %s %s was terminated because the unit exceeded its timeout of %v", err.WorkingDir, err.Command, strings.Join(err.Args, " "), err.Timeout)
+}
+
+// IsTimeoutError returns true if the given error, or any error it wraps, is a CommandTimeoutError.
+func IsTimeoutError(err error) bool {
+	var timeoutErr CommandTimeoutError
+	return goerrors.As(err, &timeoutErr)
+}
@@ -4,6 +4,7 @@
 package shell
 
 import (
+	"context"
 	goerrors "errors"
 	"fmt"
 	"os"
@@ -143,3 +144,82 @@ func TestRunShellCommandWithOutputInterrupt(t *testing.T) {
 	expectedErr := fmt.Sprintf("[.] exit status %d", expectedWait)
 	assert.EqualError(t, <-errCh, expectedErr)
 }
+
+func TestRunShellCommandInterruptedOnCancelUnix(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsForTest("")
+	assert.Nil(t, err, "Unexpected error creating NewTerragruntOptionsForTest: %v", err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	terragruntOptions.CancelCtx = ctx
+	time.AfterFunc(500*time.Millisecond, cancel)
+
+	start := time.Now()
+	err = RunShellCommand(terragruntOptions, "sleep", "30")
+	assert.Error(t, err)
+	assert.WithinDuration(t, start, time.Now(), 5*time.Second, "Expected the command to be interrupted when the run is cancelled")
+}
+
+// withTestTimeout sets a timeout on the given options, as when the run of a unit starts.
+func withTestTimeout(t *testing.T, terragruntOptions *options.TerragruntOptions, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	terragruntOptions.Timeout = timeout
+	terragruntOptions.TimeoutCtx = ctx
+}
+
+func TestRunShellCommandTimeoutUnix(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsForTest("")
+	assert.Nil(t, err, "Unexpected error creating NewTerragruntOptionsForTest: %v", err)
+	withTestTimeout(t, terragruntOptions, 500*time.Millisecond)
+
+	// sh does not forward SIGINT to sleep, which keeps the output of the command open: the command only finishes
+	// quickly if the whole process group is interrupted.
+	start := time.Now()
+	_, err = RunShellCommandWithOutput(terragruntOptions, "", true, false, "sh", "-c", "echo started; sleep 30; echo done")
+	assert.Error(t, err)
+	assert.True(t, IsTimeoutError(err), "Expected a timeout error, got %v", err)
+	assert.WithinDuration(t, start, time.Now(), 5*time.Second, "Expected the command to be terminated when it exceeds its timeout")
+
+	// The output of the command up to the timeout is kept.
+	var timeoutErr CommandTimeoutError
+	assert.True(t, goerrors.As(err, &timeoutErr))
+	assert.Equal(t, "started\n", timeoutErr.StdOut)
+}
+
+func TestRunShellCommandTimeoutSharedByUnitUnix(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsForTest("")
+	assert.Nil(t, err, "Unexpected error creating NewTerragruntOptionsForTest: %v", err)
+	withTestTimeout(t, terragruntOptions, time.Second)
+
+	// Each command is shorter than the timeout, but not the commands of the unit all together.
+	assert.NoError(t, RunShellCommand(terragruntOptions, "sleep", "0.6"))
+	err = RunShellCommand(terragruntOptions, "sleep", "0.6")
+	assert.True(t, IsTimeoutError(err), "Expected a timeout error, got %v", err)
+
+	// The commands left after the timeout are not run.
+	start := time.Now()
+	err = RunShellCommand(terragruntOptions, "true")
+	assert.True(t, IsTimeoutError(err), "Expected a timeout error, got %v", err)
+	assert.WithinDuration(t, start, time.Now(), 100*time.Millisecond)
+}
+
+func TestRunShellCommandWithinTimeoutUnix(t *testing.T) {
+	t.Parallel()
+
+	terragruntOptions, err := options.NewTerragruntOptionsForTest("")
+	assert.Nil(t, err, "Unexpected error creating NewTerragruntOptionsForTest: %v", err)
+	withTestTimeout(t, terragruntOptions, 30*time.Second)
+
+	err = RunShellCommand(terragruntOptions, "sh", "-c", "exit 3")
+	assert.Error(t, err)
+	assert.False(t, IsTimeoutError(err))
+	exitCode, exitCodeErr := GetExitCode(err)
+	assert.Nil(t, exitCodeErr)
+	assert.Equal(t, 3, exitCode)
+}
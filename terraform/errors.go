@@ -1,6 +1,10 @@
 package terraform
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
 
 // MalformedRegistryURLErr is returned if the Terraform Registry URL passed to the Getter is malformed.
 type MalformedRegistryURLErr struct {
@@ -40,3 +44,14 @@ type RegistryAPIErr struct {
 func (err RegistryAPIErr) Error() string {
 	return fmt.Sprintf("Failed to fetch url %s: status code %d", err.url, err.statusCode)
 }
+
+// InvalidVariableType is returned if the type constraint of a variable of a terraform module can not be parsed.
+type InvalidVariableType struct {
+	Name string
+	Pos  tfconfig.SourcePos
+	Err  error
+}
+
+func (err InvalidVariableType) Error() string {
+	return fmt.Sprintf("Invalid type constraint for variable %s (%s:%d): %v", err.Name, err.Pos.Filename, err.Pos.Line, err.Err)
+}
@@ -1,8 +1,14 @@
 package terraform
 
 import (
+	"sort"
+
 	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Prefix to use for terraform variables set with environment variables.
@@ -27,3 +33,86 @@ func ModuleVariables(modulePath string) ([]string, []string, error) {
 	}
 	return required, optional, nil
 }
+
+// ModuleOutputs will return the names of all the outputs defined in the terraform module at the given path, sorted.
+func ModuleOutputs(modulePath string) ([]string, error) {
+	module, diags := tfconfig.LoadModule(modulePath)
+	if diags.HasErrors() {
+		return nil, errors.WithStackTrace(diags)
+	}
+
+	outputs := []string{}
+	for name := range module.Outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+	return outputs, nil
+}
+
+// ModuleVariable is a variable defined in a terraform module, with its type constraint.
+type ModuleVariable struct {
+	Name string
+	// Type is the type constraint of the variable, cty.DynamicPseudoType if the variable accepts any value or if its
+	// type constraint can not be parsed.
+	Type cty.Type
+	// TypeErr is the error parsing the type constraint of the variable, nil if it was parsed.
+	TypeErr  error
+	Required bool
+	Pos      tfconfig.SourcePos
+}
+
+// ModuleVariablesWithTypes will return all the variables defined in the downloaded terraform modules, like
+// ModuleVariables, along with their parsed type constraint. The variables are sorted by name. A variable whose type
+// constraint can not be parsed is still returned, with the parsing error in its TypeErr.
+func ModuleVariablesWithTypes(modulePath string) ([]*ModuleVariable, error) {
+	module, diags := tfconfig.LoadModule(modulePath)
+	if diags.HasErrors() {
+		return nil, errors.WithStackTrace(diags)
+	}
+
+	variables := []*ModuleVariable{}
+	for _, variable := range module.Variables {
+		moduleVariable := &ModuleVariable{
+			Name:     variable.Name,
+			Type:     cty.DynamicPseudoType,
+			Required: variable.Required,
+			Pos:      variable.Pos,
+		}
+		varType, err := parseVariableType(variable.Type)
+		if err != nil {
+			moduleVariable.TypeErr = errors.WithStackTrace(InvalidVariableType{Name: variable.Name, Pos: variable.Pos, Err: err})
+		} else {
+			moduleVariable.Type = varType
+		}
+		variables = append(variables, moduleVariable)
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, nil
+}
+
+// parseVariableType parses the type constraint of a variable, as returned by tfconfig, into a cty type. Variables
+// without a type constraint accept any value. The defaults of the optional attributes are allowed in the type
+// constraint, but are not needed to check the type of a value, so they are not returned.
+func parseVariableType(typeExpr string) (cty.Type, error) {
+	switch typeExpr {
+	case "", "any":
+		return cty.DynamicPseudoType, nil
+	// The legacy type keywords of terraform 0.11, which tfconfig returns unquoted.
+	case "list":
+		return cty.List(cty.DynamicPseudoType), nil
+	case "map":
+		return cty.Map(cty.DynamicPseudoType), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(typeExpr), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilType, diags
+	}
+	varType, _, diags := typeexpr.TypeConstraintWithDefaults(expr)
+	if diags.HasErrors() {
+		return cty.NilType, diags
+	}
+	return varType, nil
+}
@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestModuleVariablesWithTypes(t *testing.T) {
+	t.Parallel()
+
+	moduleDir := t.TempDir()
+	variables := `
+variable "name" {
+  type = string
+}
+
+variable "legacy" {
+  type    = "list"
+  default = []
+}
+
+variable "settings" {
+  type = object({
+    enabled = optional(bool, true)
+  })
+  default = {}
+}
+
+variable "anything" {}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "variables.tf"), []byte(variables), 0644))
+
+	moduleVariables, err := ModuleVariablesWithTypes(moduleDir)
+	require.NoError(t, err)
+	require.Len(t, moduleVariables, 4)
+
+	assert.Equal(t, "anything", moduleVariables[0].Name)
+	assert.Equal(t, cty.DynamicPseudoType, moduleVariables[0].Type)
+	assert.True(t, moduleVariables[0].Required)
+	assert.Equal(t, cty.List(cty.DynamicPseudoType), moduleVariables[1].Type)
+	assert.Equal(t, cty.String, moduleVariables[2].Type)
+	assert.Equal(t, cty.ObjectWithOptionalAttrs(map[string]cty.Type{"enabled": cty.Bool}, []string{"enabled"}), moduleVariables[3].Type)
+	assert.False(t, moduleVariables[3].Required)
+}
+
+func TestModuleVariablesWithTypesInvalidType(t *testing.T) {
+	t.Parallel()
+
+	moduleDir := t.TempDir()
+	variables := `
+variable "broken" {
+  type = list(strin)
+}
+
+variable "name" {
+  type = string
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "variables.tf"), []byte(variables), 0644))
+
+	// The variable with the invalid type is returned with its error, along with the other variables.
+	moduleVariables, err := ModuleVariablesWithTypes(moduleDir)
+	require.NoError(t, err)
+	require.Len(t, moduleVariables, 2)
+	assert.IsType(t, InvalidVariableType{}, errors.Unwrap(moduleVariables[0].TypeErr))
+	assert.Equal(t, cty.DynamicPseudoType, moduleVariables[0].Type)
+	assert.NoError(t, moduleVariables[1].TypeErr)
+	assert.Equal(t, cty.String, moduleVariables[1].Type)
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// WriteFiles writes the given files, by path relative to the given directory, creating the directories they are in.
+func WriteFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+}
+
+// WriteTempFiles writes the given files in a new temporary directory, and returns the directory. Its symlinks are
+// resolved, so that it matches the paths resolved by terragrunt, e.g. on macOS where the temporary directories are
+// behind a symlink.
+func WriteTempFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	WriteFiles(t, dir, files)
+	return dir
+}